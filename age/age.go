@@ -0,0 +1,133 @@
+// Package age decrypts age-encrypted values (https://age-encryption.org) bound
+// to clibind secret fields, so secrets can be committed encrypted and still bind
+// transparently:
+//
+//	type Config struct {
+//	    DBPassword clibind.Secret[string] `cli:"db-password" cliDefault:"age://secrets/db-password.age"`
+//	}
+//
+//	cmd := clibind.CommandWithBinding(nil, "serve", run, age.Option("~/.config/app/key.txt"))
+//
+// A secret field may hold an age://path reference to an encrypted file (binary
+// or armored), or the armored payload itself, starting with
+// "-----BEGIN AGE ENCRYPTED FILE-----". Decryption runs the age CLI, which
+// supports every identity type including plugins and SSH keys.
+package age
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+// Scheme is the reference scheme handled by this package.
+const Scheme = "age"
+
+// Decrypter decrypts age payloads. The zero value runs "age" with the identity
+// file named by SOPS_AGE_KEY_FILE, or else with sops' default key file
+// (age/keys.txt in the sops user config directory), so keys already set up for
+// sops work unchanged.
+type Decrypter struct {
+	// Identities are identity files passed to age with -i. A leading "~/" is
+	// expanded to the home directory.
+	Identities []string
+	// Command is the age binary, "age" if empty; rage is a drop-in replacement.
+	Command string
+}
+
+// Scheme implements clibind.Provider.
+func (d *Decrypter) Scheme() string { return Scheme }
+
+// Option decrypts age values with the given identity files, or with the defaults
+// of a zero Decrypter when none is given.
+func Option(identities ...string) clibind.Option {
+	return (&Decrypter{Identities: identities}).Option()
+}
+
+// Option decrypts age values with d.
+func (d *Decrypter) Option() clibind.Option {
+	return clibind.WithSecretResolver(Scheme, d.Resolve)
+}
+
+// Resolve decrypts the file an age://path reference names, or the armored
+// payload ref is.
+func (d *Decrypter) Resolve(ctx context.Context, ref string) (string, error) {
+	ciphertext := []byte(ref)
+	if path, ok := strings.CutPrefix(ref, Scheme+"://"); ok {
+		data, err := os.ReadFile(expandHome(path))
+		if err != nil {
+			return "", fmt.Errorf("age: %w", err)
+		}
+		ciphertext = data
+	}
+	out, err := d.Decrypt(ctx, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// Decrypt decrypts an age payload, binary or armored.
+func (d *Decrypter) Decrypt(ctx context.Context, ciphertext []byte) ([]byte, error) {
+	identities, err := d.identities()
+	if err != nil {
+		return nil, err
+	}
+	args := []string{"--decrypt"}
+	for _, id := range identities {
+		args = append(args, "-i", id)
+	}
+	command := d.Command
+	if command == "" {
+		command = "age"
+	}
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Stdin = bytes.NewReader(bytes.TrimSpace(ciphertext))
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("age: %s", msg)
+		}
+		return nil, fmt.Errorf("age: %w", err)
+	}
+	return out, nil
+}
+
+func (d *Decrypter) identities() ([]string, error) {
+	if len(d.Identities) > 0 {
+		ids := make([]string, len(d.Identities))
+		for i, id := range d.Identities {
+			ids[i] = expandHome(id)
+		}
+		return ids, nil
+	}
+	if file := os.Getenv("SOPS_AGE_KEY_FILE"); file != "" {
+		return []string{expandHome(file)}, nil
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return nil, fmt.Errorf("age: no identity file: %w", err)
+	}
+	file := filepath.Join(dir, "sops", "age", "keys.txt")
+	if _, err := os.Stat(file); err != nil {
+		return nil, fmt.Errorf("age: no identity file given and %s: %w", file, err)
+	}
+	return []string{file}, nil
+}
+
+func expandHome(path string) string {
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, rest)
+		}
+	}
+	return path
+}
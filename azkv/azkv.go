@@ -0,0 +1,168 @@
+// Package azkv resolves azkv:// references in clibind secret fields to Azure
+// Key Vault secrets:
+//
+//	type Config struct {
+//	    DBPassword clibind.Secret[string] `cli:"db-password" cliDefault:"azkv://acme/secrets/db-password"`
+//	}
+//
+//	cmd := clibind.CommandWithBinding(nil, "serve", run, azkv.Option())
+//
+// A reference is the secret URI of the vault with the azkv scheme,
+// azkv://VAULT.vault.azure.net/secrets/NAME, optionally followed by /VERSION;
+// the current version is used otherwise. A bare vault name, azkv://VAULT/...,
+// stands for a vault of the public cloud. Requests authenticate as described
+// for Client.TokenSource.
+package azkv
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+// Scheme is the reference scheme handled by this package.
+const Scheme = "azkv"
+
+const apiVersion = "7.4"
+
+// vaultSuffixes are the Key Vault DNS suffixes of the public and sovereign clouds.
+var vaultSuffixes = []string{".vault.azure.net", ".vault.azure.cn", ".vault.usgovcloudapi.net", ".vault.microsoftazure.de"}
+
+// Client reads Key Vault secrets. The zero value uses http.DefaultClient and the
+// default credential chain.
+type Client struct {
+	HTTPClient *http.Client
+	// TokenSource returns access tokens for the vault resource; nil tries, in
+	// order, a service principal from AZURE_TENANT_ID, AZURE_CLIENT_ID and
+	// AZURE_CLIENT_SECRET, workload identity (AZURE_FEDERATED_TOKEN_FILE), managed
+	// identity, and the Azure CLI (az account get-access-token).
+	TokenSource func(ctx context.Context, resource string) (string, error)
+
+	credOnce sync.Once
+	cred     *credential
+}
+
+// Scheme implements clibind.Provider.
+func (c *Client) Scheme() string { return Scheme }
+
+// Option resolves azkv:// references with a zero Client.
+func Option() clibind.Option {
+	return (&Client{}).Option()
+}
+
+// Option resolves azkv:// references with c.
+func (c *Client) Option() clibind.Option {
+	return clibind.WithSecretResolver(Scheme, c.Resolve)
+}
+
+// IsSecretURI reports whether ref is an azkv:// reference to a Key Vault secret.
+func IsSecretURI(ref string) bool {
+	_, _, ok := parseSecretURI(ref)
+	return ok
+}
+
+func parseSecretURI(ref string) (vault *url.URL, path string, ok bool) {
+	u, err := url.Parse(ref)
+	if err != nil || u.Scheme != Scheme || u.RawQuery != "" || u.Port() != "" {
+		return nil, "", false
+	}
+	if !strings.Contains(u.Host, ".") {
+		u.Host += vaultSuffixes[0]
+	}
+	known := false
+	for _, suffix := range vaultSuffixes {
+		known = known || strings.HasSuffix(u.Host, suffix)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	if !known || len(parts) < 2 || len(parts) > 3 || parts[0] != "secrets" {
+		return nil, "", false
+	}
+	return &url.URL{Scheme: "https", Host: u.Host}, "/" + strings.Join(parts, "/"), true
+}
+
+// Resolve returns the value of the secret ref refers to.
+func (c *Client) Resolve(ctx context.Context, ref string) (string, error) {
+	vault, path, ok := parseSecretURI(ref)
+	if !ok {
+		return "", fmt.Errorf("azkv: %q is not a %s://VAULT/secrets/NAME[/VERSION] reference", ref, Scheme)
+	}
+	resource := "https://vault.azure.net"
+	if i := strings.Index(vault.Host, ".vault."); i >= 0 {
+		resource = "https://" + vault.Host[i+1:]
+	}
+	token, err := c.token(ctx, resource)
+	if err != nil {
+		return "", err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, vault.String()+path+"?api-version="+apiVersion, nil)
+	if err != nil {
+		return "", fmt.Errorf("azkv: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var secret struct {
+		Value string `json:"value"`
+	}
+	if err := doJSON(c.httpClient(), req, &secret); err != nil {
+		return "", err
+	}
+	return secret.Value, nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) token(ctx context.Context, resource string) (string, error) {
+	if c.TokenSource != nil {
+		return c.TokenSource(ctx, resource)
+	}
+	c.credOnce.Do(func() { c.cred = &credential{client: c.httpClient(), tokens: map[string]cachedToken{}} })
+	return c.cred.token(ctx, resource)
+}
+
+// doJSON sends req and decodes a successful JSON response into out, reporting
+// Azure and Entra ID errors with their code and message.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("azkv: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("azkv: read %s: %w", req.URL.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error json.RawMessage `json:"error"`
+			Desc  string          `json:"error_description"` // Entra ID token endpoints
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		var detail struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		}
+		msg := apiErr.Desc
+		if json.Unmarshal(apiErr.Error, &detail) == nil && detail.Message != "" {
+			msg = detail.Code + ": " + detail.Message
+		}
+		if msg == "" {
+			msg = strings.TrimSpace(string(body))
+		}
+		return fmt.Errorf("azkv: %s: %s %s", req.URL.Path, resp.Status, msg)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("azkv: decode %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
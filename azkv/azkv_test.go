@@ -0,0 +1,76 @@
+package azkv_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/eosproject/urfave-cli-bind/azkv"
+)
+
+// redirect sends every request to the test server srv, whatever its host.
+type redirect struct {
+	srv  *httptest.Server
+	host []string // of the requests
+}
+
+func (r *redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	r.host = append(r.host, req.URL.Host)
+	u, _ := url.Parse(r.srv.URL)
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return r.srv.Client().Transport.RoundTrip(req)
+}
+
+func TestIsSecretURI(t *testing.T) {
+	for ref, want := range map[string]bool{
+		"azkv://acme/secrets/db":                         true,
+		"azkv://acme.vault.azure.net/secrets/db/0123abc": true,
+		"azkv://acme.vault.azure.cn/secrets/db":          true,
+		"azkv://acme.example.com/secrets/db":             false,
+		"azkv://acme/keys/db":                            false,
+		"azkv://acme/secrets/db?x=1":                     false,
+		"https://acme.vault.azure.net/secrets/db":        false,
+	} {
+		if got := azkv.IsSecretURI(ref); got != want {
+			t.Errorf("IsSecretURI(%q) = %v, want %v", ref, got, want)
+		}
+	}
+}
+
+func TestResolve(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" || r.URL.Path != "/secrets/db/v2" || r.URL.Query().Get("api-version") == "" {
+			http.Error(w, `{"error": {"code": "Forbidden", "message": "denied"}}`, http.StatusForbidden)
+			return
+		}
+		w.Write([]byte(`{"value": "s3cret"}`))
+	}))
+	defer srv.Close()
+	rt := &redirect{srv: srv}
+	var resources []string
+	c := &azkv.Client{
+		HTTPClient: &http.Client{Transport: rt},
+		TokenSource: func(_ context.Context, resource string) (string, error) {
+			resources = append(resources, resource)
+			return "tok", nil
+		},
+	}
+
+	got, err := c.Resolve(context.Background(), "azkv://acme/secrets/db/v2")
+	if err != nil || got != "s3cret" {
+		t.Fatalf("Resolve = %q, %v; want s3cret", got, err)
+	}
+	if rt.host[0] != "acme.vault.azure.net" || resources[0] != "https://vault.azure.net" {
+		t.Errorf("requested %s for %s, want acme.vault.azure.net for https://vault.azure.net", rt.host[0], resources[0])
+	}
+	if _, err := c.Resolve(context.Background(), "azkv://acme/secrets/other"); err == nil || !strings.Contains(err.Error(), "Forbidden: denied") {
+		t.Errorf("err = %v, want the Key Vault error", err)
+	}
+	if _, err := c.Resolve(context.Background(), "https://acme.vault.azure.net/secrets/db"); err == nil {
+		t.Error("resolved an https:// URI")
+	}
+}
@@ -0,0 +1,151 @@
+package azkv
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	imdsEndpoint  = "http://169.254.169.254/metadata/identity/oauth2/token"
+	authorityHost = "https://login.microsoftonline.com/"
+)
+
+type cachedToken struct {
+	value  string
+	expiry time.Time
+}
+
+// credential implements the default credential chain, caching tokens per resource.
+type credential struct {
+	client *http.Client
+
+	mu     sync.Mutex
+	tokens map[string]cachedToken
+}
+
+func (c *credential) token(ctx context.Context, resource string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if t, ok := c.tokens[resource]; ok && time.Until(t.expiry) > time.Minute {
+		return t.value, nil
+	}
+	t, err := c.fetch(ctx, resource)
+	if err != nil {
+		return "", fmt.Errorf("azkv: credentials: %w", err)
+	}
+	c.tokens[resource] = t
+	return t.value, nil
+}
+
+func (c *credential) fetch(ctx context.Context, resource string) (cachedToken, error) {
+	tenant, clientID := os.Getenv("AZURE_TENANT_ID"), os.Getenv("AZURE_CLIENT_ID")
+	scope := resource + "/.default"
+	if secret := os.Getenv("AZURE_CLIENT_SECRET"); tenant != "" && clientID != "" && secret != "" {
+		return c.post(ctx, authorityHost+tenant+"/oauth2/v2.0/token", url.Values{
+			"grant_type":    {"client_credentials"},
+			"client_id":     {clientID},
+			"client_secret": {secret},
+			"scope":         {scope},
+		})
+	}
+	if file := os.Getenv("AZURE_FEDERATED_TOKEN_FILE"); tenant != "" && clientID != "" && file != "" {
+		assertion, err := os.ReadFile(file)
+		if err != nil {
+			return cachedToken{}, fmt.Errorf("workload identity: %w", err)
+		}
+		return c.post(ctx, authorityHost+tenant+"/oauth2/v2.0/token", url.Values{
+			"grant_type":            {"client_credentials"},
+			"client_id":             {clientID},
+			"client_assertion_type": {"urn:ietf:params:oauth:client-assertion-type:jwt-bearer"},
+			"client_assertion":      {strings.TrimSpace(string(assertion))},
+			"scope":                 {scope},
+		})
+	}
+	t, miErr := c.managedIdentity(ctx, resource, clientID)
+	if miErr == nil {
+		return t, nil
+	}
+	t, cliErr := azureCLI(ctx, resource)
+	if cliErr == nil {
+		return t, nil
+	}
+	return cachedToken{}, errors.Join(
+		errors.New("no service principal or workload identity configured in the environment"),
+		fmt.Errorf("managed identity: %w", miErr),
+		fmt.Errorf("azure cli: %w", cliErr),
+	)
+}
+
+// managedIdentity asks App Service's identity endpoint, or the instance metadata
+// service otherwise.
+func (c *credential) managedIdentity(ctx context.Context, resource, clientID string) (cachedToken, error) {
+	endpoint, apiVersion := os.Getenv("IDENTITY_ENDPOINT"), "2019-08-01"
+	if endpoint == "" {
+		endpoint, apiVersion = imdsEndpoint, "2018-02-01"
+	}
+	q := url.Values{"api-version": {apiVersion}, "resource": {resource}}
+	if clientID != "" {
+		q.Set("client_id", clientID)
+	}
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second) // IMDS is unreachable off Azure
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+q.Encode(), nil)
+	if err != nil {
+		return cachedToken{}, err
+	}
+	if h := os.Getenv("IDENTITY_HEADER"); h != "" {
+		req.Header.Set("X-IDENTITY-HEADER", h)
+	} else {
+		req.Header.Set("Metadata", "true")
+	}
+	return c.do(req)
+}
+
+func (c *credential) post(ctx context.Context, endpoint string, form url.Values) (cachedToken, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return cachedToken{}, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return c.do(req)
+}
+
+func (c *credential) do(req *http.Request) (cachedToken, error) {
+	var resp struct {
+		AccessToken string          `json:"access_token"`
+		ExpiresIn   json.RawMessage `json:"expires_in"` // a string from managed identity endpoints
+	}
+	if err := doJSON(c.client, req, &resp); err != nil {
+		return cachedToken{}, err
+	}
+	secs, err := strconv.Atoi(strings.Trim(string(resp.ExpiresIn), `"`))
+	if err != nil {
+		return cachedToken{}, fmt.Errorf("token response: invalid expires_in %s", resp.ExpiresIn)
+	}
+	return cachedToken{value: resp.AccessToken, expiry: time.Now().Add(time.Duration(secs) * time.Second)}, nil
+}
+
+func azureCLI(ctx context.Context, resource string) (cachedToken, error) {
+	out, err := exec.CommandContext(ctx, "az", "account", "get-access-token", "--resource", resource, "-o", "json").Output()
+	if err != nil {
+		return cachedToken{}, err
+	}
+	var resp struct {
+		AccessToken string `json:"accessToken"`
+		ExpiresOn   int64  `json:"expires_on"`
+	}
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return cachedToken{}, err
+	}
+	return cachedToken{value: resp.AccessToken, expiry: time.Unix(resp.ExpiresOn, 0)}, nil
+}
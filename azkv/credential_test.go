@@ -0,0 +1,177 @@
+package azkv_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/eosproject/urfave-cli-bind/azkv"
+)
+
+// entra fakes the Entra ID token endpoint, the managed identity endpoints and a
+// vault returning the access token it was called with as the secret.
+type entra struct {
+	*httptest.Server
+	imds   bool // whether the instance metadata service answers
+	tokens int  // issued
+}
+
+func newEntra(t *testing.T) *entra {
+	e := &entra{imds: true}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /tenant/oauth2/v2.0/token", func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.FormValue("scope") != "https://vault.azure.net/.default" || r.FormValue("client_id") != "app":
+			http.Error(w, `{"error_description": "unexpected client or scope"}`, http.StatusBadRequest)
+		case r.FormValue("client_secret") == "secret":
+			e.issue(w, "service-principal", "3600")
+		case r.FormValue("client_assertion") == "federated" && strings.HasSuffix(r.FormValue("client_assertion_type"), "jwt-bearer"):
+			e.issue(w, "workload-identity", "3600")
+		default:
+			http.Error(w, `{"error_description": "invalid client credentials"}`, http.StatusUnauthorized)
+		}
+	})
+	mux.HandleFunc("GET /msi/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-IDENTITY-HEADER") != "h" || r.FormValue("resource") != "https://vault.azure.net" {
+			http.Error(w, `{"error": {"code": "Forbidden", "message": "bad identity header"}}`, http.StatusForbidden)
+			return
+		}
+		e.issue(w, "app-service", `"3600"`)
+	})
+	mux.HandleFunc("GET /metadata/identity/oauth2/token", func(w http.ResponseWriter, r *http.Request) {
+		if !e.imds || r.Header.Get("Metadata") != "true" {
+			http.Error(w, `{"error": {"code": "Unavailable", "message": "no identity"}}`, http.StatusBadRequest)
+			return
+		}
+		e.issue(w, "managed-identity:"+r.FormValue("client_id"), `"3600"`)
+	})
+	mux.HandleFunc("GET /secrets/db", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprintf(w, `{"value": %q}`, strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer "))
+	})
+	e.Server = httptest.NewTLSServer(mux)
+	t.Cleanup(e.Close)
+	return e
+}
+
+func (e *entra) issue(w http.ResponseWriter, token, expiresIn string) {
+	e.tokens++
+	fmt.Fprintf(w, `{"access_token": %q, "expires_in": %s}`, token, expiresIn)
+}
+
+// resolve resolves the db secret with a zero Client sending every request to e.
+func (e *entra) resolve(c *azkv.Client) (string, error) {
+	if c == nil {
+		c = &azkv.Client{}
+	}
+	c.HTTPClient = &http.Client{Transport: &redirect{srv: e.Server}}
+	return c.Resolve(context.Background(), "azkv://acme/secrets/db")
+}
+
+// azureEnv clears the environment of the credential chain, then sets env. PATH
+// is an empty directory: there is no az CLI unless a test writes one there.
+func azureEnv(t *testing.T, env map[string]string) {
+	for _, name := range []string{"AZURE_TENANT_ID", "AZURE_CLIENT_ID", "AZURE_CLIENT_SECRET", "AZURE_FEDERATED_TOKEN_FILE", "IDENTITY_ENDPOINT", "IDENTITY_HEADER"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("PATH", t.TempDir())
+	for name, value := range env {
+		t.Setenv(name, value)
+	}
+}
+
+func TestServicePrincipal(t *testing.T) {
+	e := newEntra(t)
+	azureEnv(t, map[string]string{"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_ID": "app", "AZURE_CLIENT_SECRET": "secret"})
+	c := &azkv.Client{}
+	for range 2 {
+		if got, err := e.resolve(c); err != nil || got != "service-principal" {
+			t.Fatalf("Resolve = %q, %v; want service-principal", got, err)
+		}
+	}
+	if e.tokens != 1 {
+		t.Errorf("%d tokens issued, want 1 reused until it expires", e.tokens)
+	}
+}
+
+func TestWorkloadIdentity(t *testing.T) {
+	e := newEntra(t)
+	file := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(file, []byte("federated\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	azureEnv(t, map[string]string{"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_ID": "app", "AZURE_FEDERATED_TOKEN_FILE": file})
+	if got, err := e.resolve(nil); err != nil || got != "workload-identity" {
+		t.Errorf("Resolve = %q, %v; want workload-identity", got, err)
+	}
+}
+
+func TestAppServiceIdentity(t *testing.T) {
+	e := newEntra(t)
+	azureEnv(t, map[string]string{"IDENTITY_ENDPOINT": "http://localhost:8081/msi/token", "IDENTITY_HEADER": "h"})
+	if got, err := e.resolve(nil); err != nil || got != "app-service" {
+		t.Errorf("Resolve = %q, %v; want app-service", got, err)
+	}
+}
+
+func TestManagedIdentity(t *testing.T) {
+	e := newEntra(t)
+	azureEnv(t, map[string]string{"AZURE_CLIENT_ID": "user-assigned"})
+	if got, err := e.resolve(nil); err != nil || got != "managed-identity:user-assigned" {
+		t.Errorf("Resolve = %q, %v; want managed-identity:user-assigned", got, err)
+	}
+}
+
+func TestAzureCLI(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake az is a shell script")
+	}
+	e := newEntra(t)
+	e.imds = false
+	azureEnv(t, nil)
+	script := `#!/bin/sh
+[ "$*" = "account get-access-token --resource https://vault.azure.net -o json" ] || exit 1
+echo '{"accessToken": "azure-cli", "expires_on": 4102444800}'
+`
+	if err := os.WriteFile(filepath.Join(os.Getenv("PATH"), "az"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := e.resolve(nil); err != nil || got != "azure-cli" {
+		t.Errorf("Resolve = %q, %v; want azure-cli", got, err)
+	}
+}
+
+func TestCredentialErrors(t *testing.T) {
+	e := newEntra(t)
+	e.imds = false
+	for _, c := range []struct {
+		name string
+		env  map[string]string
+		err  []string
+	}{
+		{"nothing configured", nil, []string{
+			"no service principal or workload identity configured",
+			"managed identity: azkv: /metadata/identity/oauth2/token: 400 Bad Request Unavailable: no identity",
+			"azure cli:",
+		}},
+		{"rejected secret", map[string]string{"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_ID": "app", "AZURE_CLIENT_SECRET": "wrong"},
+			[]string{"401 Unauthorized invalid client credentials"}},
+		{"missing federated token", map[string]string{"AZURE_TENANT_ID": "tenant", "AZURE_CLIENT_ID": "app", "AZURE_FEDERATED_TOKEN_FILE": "/nonexistent/token"},
+			[]string{"workload identity:"}},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			azureEnv(t, c.env)
+			_, err := e.resolve(nil)
+			for _, want := range c.err {
+				if err == nil || !strings.Contains(err.Error(), want) {
+					t.Errorf("err = %v, want %q", err, want)
+				}
+			}
+		})
+	}
+}
@@ -26,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"runtime/debug"
 	"strconv"
 	"strings"
 	"time"
@@ -35,12 +36,34 @@ import (
 )
 
 const (
-	tagCLI         = "cli"           // "name,alias,Short"
-	tagCLIDefault  = "cliDefault"    // default value as string
-	tagCLIUsage    = "cliUsage"      // usage/help string
-	tagCLITimeFmt  = "cliTimeLayout" // optional time layout (default RFC3339)
-	tagCLIPrefix   = "cliPrefix"
-	defaultTimeFmt = time.RFC3339
+	tagCLI           = "cli"           // "name,alias,Short"
+	tagCLIDefault    = "cliDefault"    // default value as string
+	tagCLIDefaultVar = "cliDefaultVar" // name of a RegisterDefaultVar variable overriding cliDefault when non-empty
+	tagCLIUsage      = "cliUsage"      // usage/help string
+	tagCLIEnv        = "cliEnv"        // comma-separated environment variables the flag reads, in order
+	tagCLIFile       = "cliFile"       // dotted key of the field in the WithConfigFile file, instead of the flag name
+	tagCLITimeFmt    = "cliTimeLayout" // optional time layouts separated by '|', tried in order (default RFC3339)
+	tagCLIPrefix     = "cliPrefix"
+	tagCLICategory   = "cliCategory"           // help category of the field, or of every flag of a nested struct
+	tagCLIChoices    = "cliChoices"            // comma-separated list of accepted values
+	tagCLISkipFlag   = "cliSkipFlag"           // "true" to bind the field without generating a flag for it
+	tagCLIBase       = "cliBase"               // integer base: 0 (auto-detect 0x/0o/0b prefixes) or 2..36
+	tagCLIFloats     = "cliAllowSpecialFloats" // "true" to accept NaN and ±Inf in float fields
+	tagCLIBytes      = "cliBytes"              // "true" to parse integer values as byte sizes (10MB, 1GiB) and show defaults so (10 MiB)
+	tagCLISecret     = "cliSecret"             // "true" to mask the field like a Secret[T] in help and dumps
+	tagCLISources    = "cliSources"            // comma-separated scheme://... references resolved by a Provider when the flag is unset
+	tagCLICheck      = "cliCheck"              // "file", "dir", "tcp" or "http": what DoctorCommand checks the value points to
+	tagCLIDeprecated = "cliDeprecated"         // "true", or what to use instead: warn when the flag is used
+	tagCLIRemoveIn   = "cliRemoveIn"           // release removing a deprecated flag, e.g. "v3.0"
+	tagCLIUnit       = "cliUnit"               // unit of bare integers given to a time.Duration field: ns, us, ms, s, m or h
+	tagCLIPrecision  = "cliPrecision"          // decimal places float values are rounded to once parsed
+	tagCLIKV         = "cliKV"                 // "true" ("query") to also accept a nested struct as one flag of key=value pairs (a URL query)
+	tagCLIComplete   = "cliComplete"           // name of a RegisterCompleter func completing the flag's values
+	tagCLISince      = "cliSince"              // first version (see WithVersion) with the flag
+	tagCLIUntil      = "cliUntil"              // version removing the flag
+	tagCLIParser     = "cliParser"             // name of a RegisterParser converter parsing the field's values
+	tagCLIScheme     = "cliScheme"             // comma-separated URL schemes a url.URL field accepts
+	defaultTimeFmt   = time.RFC3339
 )
 
 // Bind populates struct fields from CLI flag values defined in the given
@@ -48,12 +71,31 @@ const (
 // are tagged or named to correspond to the command’s flags.
 //
 // dest must be a non-nil pointer to a struct, otherwise Bind returns an error.
+// The hooks of RegisterPostBind run once the struct is populated.
 func Bind(ctx *cli.Command, dest any) error {
+	if err := bind(ctx, dest, newOptions(nil)); err != nil {
+		return err
+	}
+	return runPostBind(reflect.ValueOf(dest).Elem())
+}
+
+// bind is Bind without the post-bind hooks, which WithBinding runs once the
+// sources of unset flags, --set and secret references are applied. cli:"-"
+// fields also read the dotenv file of o, and the fields without a flag the
+// --config-json object.
+func bind(ctx *cli.Command, dest any, o *options) error {
 	rv := reflect.ValueOf(dest)
 	if rv.Kind() != reflect.Pointer || rv.IsNil() {
 		return errors.New("Bind: dest must be a non-nil pointer to a struct")
 	}
-	v, err := bindStruct(ctx, unreferenceType(rv.Type()), "")
+	var object map[string]any
+	if o.configJSON != nil {
+		if err := o.configJSON.loadErr(); err != nil {
+			return err
+		}
+		object = o.configJSON.data
+	}
+	v, err := bindStruct(ctx, unreferenceType(rv.Type()), "", object, o)
 	if err != nil {
 		return err
 	}
@@ -63,7 +105,10 @@ func Bind(ctx *cli.Command, dest any) error {
 	return nil
 }
 
-func bindStruct(ctx *cli.Command, t reflect.Type, prefix string) (vp *reflect.Value, err error) {
+// bindStruct binds the struct type t from the flags named with prefix. The
+// fields without a flag that the --config-json object has a member for in
+// object are decoded from it instead.
+func bindStruct(ctx *cli.Command, t reflect.Type, prefix string, object map[string]any, o *options) (vp *reflect.Value, err error) {
 	t = unreferenceType(t)
 
 	v := reflect.New(t).Elem()
@@ -82,53 +127,168 @@ func bindStruct(ctx *cli.Command, t reflect.Type, prefix string) (vp *reflect.Va
 		}
 		name = prefix + name
 
-		if isStructLike(sf.Type) {
-			pfx := prefix
-			if !sf.Anonymous {
-				pfx += sf.Tag.Get(tagCLIPrefix)
-			} else if sf.Tag.Get(tagCLI) != "" {
-				return nil, fmt.Errorf("embedded struct %s has cli tag, but unsupported", sf.Name)
+		member, inObject := jsonMember(object, sf)
+		if skip, _ := strconv.ParseBool(sf.Tag.Get(tagCLISkipFlag)); inObject && (skip || isEnvOnly(sf)) {
+			if err := decodeMember(member, fv); err != nil {
+				return nil, fmt.Errorf("--%s: field %s: %w", flagConfigJSON, sf.Name, err)
 			}
+			defined = true
+			continue
+		}
 
-			subv, err := bindStruct(ctx, sf.Type, pfx)
+		if isStructLike(sf.Type) {
+			if err := checkNested(sf); err != nil {
+				return nil, err
+			}
+			if isKV(sf) {
+				if err := expandKV(ctx, name, nestedPrefix(sf, prefix), sf.Type, isKVQuery(sf)); err != nil {
+					return nil, err
+				}
+			}
+			sub, _ := member.(map[string]any)
+			if sf.Anonymous && sf.Tag.Get("json") == "" {
+				sub = object // encoding/json flattens embedded structs
+			}
+			subv, err := bindStruct(ctx, sf.Type, nestedPrefix(sf, prefix), sub, o)
 			if err != nil {
 				return nil, fmt.Errorf("bind substruct %s: %w", sf.Name, err)
 			}
 			if subv != nil {
 				defined = true
-				fv.Set(*subv)
+				setReferenced(fv, *subv)
 			}
 			continue
 		}
 
-		if !ctx.IsSet(name) && omitEmpty {
+		// a nullable value or an Optional binds like a pointer to its value, nil
+		// leaving it invalid or unset
+		if nsf, ok := nullableField(sf); ok {
+			p := reflect.New(nsf.Type).Elem()
+			set, err := bindField(ctx, name, omitEmpty, nsf, p, o)
+			if err != nil {
+				return nil, err
+			}
+			if !p.IsNil() {
+				setNullable(fv, p.Elem())
+				if isOptional(sf.Type) && !ctx.IsSet(name) && !isEnvOnly(sf) {
+					fv.Field(1).SetBool(false) // a default, not a given flag
+				}
+			}
+			defined = defined || set
+			continue
+		}
+		set, err := bindField(ctx, name, omitEmpty, sf, fv, o)
+		if err != nil {
+			return nil, err
+		}
+		defined = defined || set
+	}
+	if !defined {
+		return nil, nil
+	}
+	return &v, nil
+}
+
+// bindField sets the leaf field fv, whose flag is name, and reports whether it
+// was set at all.
+func bindField(ctx *cli.Command, name string, omitEmpty bool, sf reflect.StructField, fv reflect.Value, o *options) (bool, error) {
+	if isEnvOnly(sf) {
+		set, err := bindEnvOnly(ctx, sf, fv, o)
+		if err != nil {
+			return false, fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		return set, nil
+	}
+	if fs := factoriesOf(sf.Type); fs != nil {
+		set, err := bindFactory(ctx, name, fs, fv, o)
+		if err != nil {
+			return false, fmt.Errorf("flag %s: %w", name, err)
+		}
+		return set, nil
+	}
+	if !ctx.IsSet(name) && omitEmpty {
+		return false, nil
+	}
+	// a nil pointer tells "not provided" apart from an explicit zero value
+	if sf.Type.Kind() == reflect.Pointer && !ctx.IsSet(name) && fieldDefault(sf) == "" {
+		return false, nil
+	}
+	if def := fieldDefault(sf); !ctx.IsSet(name) && hasFlagRefs(def) {
+		if err := setFieldFromString(resolveFlagRefs(ctx, def), sf, allocReferenced(fv)); err != nil {
+			return false, fmt.Errorf("set field %s default: %w", sf.Name, err)
+		}
+		return true, nil
+	}
+	if ctx.IsSet(name) {
+		if err := checkChoices(ctx.Value(name), splitCSV(sf.Tag.Get(tagCLIChoices))); err != nil {
+			return false, fmt.Errorf("flag %s: %w", name, err)
+		}
+	}
+	if err := setFieldValue(ctx, name, sf, allocReferenced(fv)); err != nil {
+		return false, fmt.Errorf("set field %s value: %w", sf.Name, err)
+	}
+	return true, nil
+}
+
+// bindEnvOnly sets the cli:"-" field fv from the first of its cliEnv variables
+// that is set, see options.lookupEnv, else from its default. Like a flag without a default, a field
+// with cliEnv variables is required unless it is a pointer.
+func bindEnvOnly(ctx *cli.Command, sf reflect.StructField, fv reflect.Value, o *options) (bool, error) {
+	vars := splitCSV(sf.Tag.Get(tagCLIEnv))
+	for _, name := range vars {
+		s, _, ok := o.lookupEnv(name)
+		if !ok {
 			continue
 		}
-		if err := setFieldValue(ctx, name, sf, unreferenceValue(fv)); err != nil {
-			return nil, fmt.Errorf("set field %s value: %w", sf.Name, err)
+		if err := checkChoices(s, splitCSV(sf.Tag.Get(tagCLIChoices))); err != nil {
+			return false, fmt.Errorf("environment variable %s: %w", name, err)
+		}
+		if err := setFieldFromString(s, sf, allocReferenced(fv)); err != nil {
+			return false, fmt.Errorf("environment variable %s: %w", name, err)
 		}
-		defined = true
+		return true, nil
 	}
-	if defined {
-		return &v, nil
+	def := fieldDefault(sf)
+	switch {
+	case def != "":
+		if err := setFieldFromString(resolveFlagRefs(ctx, def), sf, allocReferenced(fv)); err != nil {
+			return false, fmt.Errorf("default: %w", err)
+		}
+		return true, nil
+	case len(vars) > 0 && sf.Type.Kind() != reflect.Pointer:
+		return false, fmt.Errorf("environment variable %s not set", strings.Join(vars, " or "))
 	}
-	return nil, nil
+	return false, nil
 }
 
 // setFieldValue reads a CLI flag and sets the corresponding struct field.
 func setFieldValue(ctx *cli.Command, name string, sf reflect.StructField, field reflect.Value) error {
-	t := unreferenceType(sf.Type)
+	t := field.Type()
 
+	if _, ok := registeredType(t); ok || hasParser(sf, t) {
+		v, err := parseValue(ctx.String(name), t, sf)
+		if err != nil {
+			return err
+		}
+		field.Set(v)
+		return nil
+	}
 	switch {
+	case isFlagValue(t):
+		return setFlagValueField(ctx.Value(name), name, t, field)
+
+	case isSecret(t):
+		field.Addr().Interface().(secretSetter).setSecret(ctx.String(name))
+
 	case t == reflect.TypeOf(time.Second):
 		s := ctx.String(name)
 		if s == "" {
 			field.Set(reflect.ValueOf(time.Duration(0)))
 			return nil
 		}
-		d, err := time.ParseDuration(s)
+		d, err := parseDuration(s, sf)
 		if err != nil {
-			return fmt.Errorf("parse duration: %w", err)
+			return err
 		}
 		field.Set(reflect.ValueOf(d))
 
@@ -142,21 +302,21 @@ func setFieldValue(ctx *cli.Command, name string, sf reflect.StructField, field
 		castAndSetUint(field, ctx.Uint64(name))
 
 	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
-		field.SetFloat(ctx.Float64(name))
+		f, err := roundFloat(ctx.Float64(name), sf)
+		if err != nil {
+			return err
+		}
+		field.SetFloat(f)
 
 	case t == reflect.TypeOf(time.Time{}):
-		timeLayout := sf.Tag.Get(tagCLITimeFmt)
-		if timeLayout == "" {
-			timeLayout = defaultTimeFmt
-		}
 		s := ctx.String(name)
 		if s == "" {
 			field.Set(reflect.ValueOf(time.Time{}))
 			return nil
 		}
-		t, err := time.Parse(timeLayout, s)
+		t, err := parseTime(s, sf)
 		if err != nil {
-			return fmt.Errorf("time parse: %w", err)
+			return err
 		}
 		field.Set(reflect.ValueOf(t))
 
@@ -177,6 +337,9 @@ func setFieldValue(ctx *cli.Command, name string, sf reflect.StructField, field
 
 	case t.Kind() == reflect.Slice:
 		return setSliceField(ctx, name, sf, field)
+
+	case t.Kind() == reflect.Map:
+		return setMapField(ctx, name, sf, field)
 	}
 	return nil
 }
@@ -187,88 +350,257 @@ func setSliceField(ctx *cli.Command, name string, sf reflect.StructField, field
 	if len(raw) == 0 {
 		return nil
 	}
+	return setSliceFromStrings(raw, sf, field)
+}
 
-	ft := sf.Type
-	t := ft.Elem()
+// setSliceFromStrings parses every element of raw into the slice field.
+func setSliceFromStrings(raw []string, sf reflect.StructField, field reflect.Value) error {
+	t := field.Type().Elem()
 	out := reflect.MakeSlice(reflect.SliceOf(t), 0, len(raw))
+	for i, s := range raw {
+		val, err := parseValue(s, t, sf)
+		if err != nil {
+			return fmt.Errorf("element %d: %w", i, err)
+		}
+		out = reflect.Append(out, val)
+	}
+	field.Set(out)
+	return nil
+}
 
-	for _, s := range raw {
-		val := reflect.New(t).Elem()
+// setMapField handles map types bound from repeated key=value flags.
+func setMapField(ctx *cli.Command, name string, sf reflect.StructField, field reflect.Value) error {
+	raw := ctx.StringSlice(name)
+	if len(raw) == 0 {
+		return nil
+	}
+	return setMapFromStrings(raw, sf, field)
+}
+
+// setMapFromStrings parses "key=value" (or header style "Key: value") entries into the
+// map field. Keys and values may be of any scalar type parseScalar understands. Repeated
+// keys append to multi-valued maps (map[K][]V), where a value may also list several values
+// separated by ';', and replace the value of the others.
+func setMapFromStrings(raw []string, sf reflect.StructField, field reflect.Value) error {
+	t := field.Type()
+	elem := t.Elem()
+	multi := elem.Kind() == reflect.Slice && !isCustomType(elem)
+	if multi {
+		elem = elem.Elem()
+	}
+	switch elem.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Pointer, reflect.Interface:
+		if !isCustomType(elem) {
+			return fmt.Errorf("map type %s at %s is not supported", t, sf.Name)
+		}
+	}
+	if isStructLike(elem) {
+		return fmt.Errorf("map type %s at %s is not supported", t, sf.Name)
+	}
+	if !isMapKey(t.Key()) {
+		return fmt.Errorf("map type %s at %s: unsupported key type %s", t, sf.Name, t.Key())
+	}
 
-		switch {
-		case t == reflect.TypeOf(time.Second):
-			if s == "" {
-				val.Set(reflect.ValueOf(time.Duration(0)))
-				continue
+	entries, err := splitMapEntries(raw)
+	if err != nil {
+		return err
+	}
+	m := reflect.MakeMapWithSize(t, len(entries))
+	for _, e := range entries {
+		key, err := parseScalar(e[0], t.Key(), sf)
+		if err != nil {
+			return fmt.Errorf("map key %q: %w", e[0], err)
+		}
+		if !multi {
+			val, err := parseValue(e[1], elem, sf)
+			if err != nil {
+				return fmt.Errorf("map value %q of %q: %w", e[1], e[0], err)
 			}
-			d, err := time.ParseDuration(s)
+			m.SetMapIndex(key, val)
+			continue
+		}
+		vals := m.MapIndex(key)
+		if !vals.IsValid() {
+			vals = reflect.MakeSlice(t.Elem(), 0, 1)
+		}
+		for _, v := range strings.Split(e[1], ";") {
+			val, err := parseValue(strings.TrimSpace(v), elem, sf)
 			if err != nil {
-				return fmt.Errorf("parse duration: %w", err)
+				return fmt.Errorf("map value %q of %q: %w", v, e[0], err)
 			}
-			val.Set(reflect.ValueOf(d))
+			vals = reflect.Append(vals, val)
+		}
+		m.SetMapIndex(key, vals)
+	}
+	field.Set(m)
+	return nil
+}
 
-		case t.Kind() == reflect.Bool:
-			tr, _ := strconv.ParseBool(s)
-			val.SetBool(tr)
+// setFieldFromString parses s into field, splitting it as comma-separated values for
+// slices and comma-separated key=value entries for maps.
+func setFieldFromString(s string, sf reflect.StructField, field reflect.Value) error {
+	if field.Kind() == reflect.Slice && !isCustomType(field.Type()) {
+		return setSliceFromStrings(splitCSV(s), sf, field)
+	}
+	if field.Kind() == reflect.Map && !isCustomType(field.Type()) {
+		return setMapFromStrings(splitCSV(s), sf, field)
+	}
+	val, err := parseValue(s, field.Type(), sf)
+	if err != nil {
+		return err
+	}
+	field.Set(val)
+	return nil
+}
 
-		case isAnyInt(t.Kind()):
-			i, err := strconv.ParseInt(s, 10, 64)
-			if err != nil {
-				return fmt.Errorf("parse int: %w", err)
-			}
-			castAndSetInt(val, i)
+// parseTime parses s with the first matching cliTimeLayout of sf, so mixed-precision
+// inputs (dates and datetimes) can share one flag.
+func parseTime(s string, sf reflect.StructField) (time.Time, error) {
+	layouts := strings.Split(sf.Tag.Get(tagCLITimeFmt), "|")
+	if len(layouts) == 1 && layouts[0] == "" {
+		layouts[0] = defaultTimeFmt
+	}
+	var firstErr error
+	for _, layout := range layouts {
+		t, err := time.Parse(strings.TrimSpace(layout), s)
+		if err == nil {
+			return t, nil
+		}
+		if firstErr == nil {
+			firstErr = err
+		}
+	}
+	if len(layouts) > 1 {
+		return time.Time{}, fmt.Errorf("time parse: %q matches none of the layouts %q", s, layouts)
+	}
+	return time.Time{}, fmt.Errorf("time parse: %w", firstErr)
+}
 
-		case isAnyUint(t.Kind()):
-			i, err := strconv.ParseUint(s, 10, 64)
-			if err != nil {
-				return fmt.Errorf("parse uint: %w", err)
-			}
-			castAndSetUint(val, i)
+// isMapKey reports whether parseScalar parses map keys of type t: booleans,
+// numbers, strings, times, UUIDs and registered types, but not other structs,
+// arrays or complex numbers.
+func isMapKey(t reflect.Type) bool {
+	if _, ok := registeredType(t); ok || t == reflect.TypeOf(time.Time{}) || t == reflect.TypeOf(uuid.UUID{}) {
+		return true
+	}
+	switch k := t.Kind(); {
+	case k == reflect.Bool, k == reflect.String, k == reflect.Float32, k == reflect.Float64:
+		return true
+	default:
+		return isAnyInt(k) || isAnyUint(k)
+	}
+}
 
-		case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
-			i, err := strconv.ParseFloat(s, 64)
-			if err != nil {
-				return fmt.Errorf("parse float: %w", err)
-			}
-			val.SetFloat(i)
+// parseScalar parses s into a new value of the scalar type t.
+func parseScalar(s string, t reflect.Type, sf reflect.StructField) (reflect.Value, error) {
+	val := reflect.New(t).Elem()
 
-		case t == reflect.TypeOf(time.Time{}):
-			timeLayout := sf.Tag.Get(tagCLITimeFmt)
-			if timeLayout == "" {
-				timeLayout = defaultTimeFmt
-			}
-			if s == "" {
-				val.Set(reflect.ValueOf(time.Time{}))
-				continue
-			}
-			t, err := time.Parse(timeLayout, s)
-			if err != nil {
-				return fmt.Errorf("time parse: %w", err)
-			}
-			val.Set(reflect.ValueOf(t))
+	if c, ok := registeredType(t); ok {
+		if s == "" {
+			return val, nil
+		}
+		return c.parse(s, sf)
+	}
+	switch {
+	case isFlagValue(t):
+		return parseFlagValue(s, t)
 
-		case t == reflect.TypeOf(uuid.UUID{}):
-			if s == "" {
-				val.Set(reflect.ValueOf(uuid.Nil))
-				continue
-			}
-			id, err := uuid.FromString(s)
-			if err != nil {
-				return fmt.Errorf("parse uuid: %w", err)
-			}
-			val.Set(reflect.ValueOf(id))
+	case isSecret(t):
+		val.Addr().Interface().(secretSetter).setSecret(s)
+
+	case t == reflect.TypeOf(time.Second):
+		if s == "" {
+			return val, nil
+		}
+		d, err := parseDuration(s, sf)
+		if err != nil {
+			return val, err
+		}
+		val.Set(reflect.ValueOf(d))
+
+	case t.Kind() == reflect.Bool:
+		if s == "" {
+			return val, nil
+		}
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return val, fmt.Errorf("parse bool: %w", err)
+		}
+		val.SetBool(b)
 
-		case t.Kind() == reflect.String:
-			val.SetString(s)
+	case isAnyInt(t.Kind()):
+		base, err := intBase(sf, 10)
+		if err != nil {
+			return val, err
+		}
+		parse := parseInt
+		if isBytes(sf) {
+			parse = parseIntSize
+		}
+		i, err := parse(s, base)
+		if err != nil {
+			return val, fmt.Errorf("parse int: %w", err)
+		}
+		castAndSetInt(val, i)
 
-		case t.Kind() == reflect.Slice:
-			return fmt.Errorf("matrix type at %s is not supported", sf.Name)
+	case isAnyUint(t.Kind()):
+		base, err := intBase(sf, 10)
+		if err != nil {
+			return val, err
+		}
+		parse := parseUint
+		if isBytes(sf) {
+			parse = parseUintSize
 		}
+		i, err := parse(s, base)
+		if err != nil {
+			return val, fmt.Errorf("parse uint: %w", err)
+		}
+		castAndSetUint(val, i)
 
-		out = reflect.Append(out, val)
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		i, err := parseFloat(s, nil)
+		if err != nil {
+			return val, fmt.Errorf("parse float: %w", err)
+		}
+		if err := checkFinite(i, sf); err != nil {
+			return val, err
+		}
+		if i, err = roundFloat(i, sf); err != nil {
+			return val, err
+		}
+		val.SetFloat(i)
+
+	case t == reflect.TypeOf(time.Time{}):
+		if s == "" {
+			return val, nil
+		}
+		t, err := parseTime(s, sf)
+		if err != nil {
+			return val, err
+		}
+		val.Set(reflect.ValueOf(t))
+
+	case t == reflect.TypeOf(uuid.UUID{}):
+		if s == "" {
+			return val, nil
+		}
+		id, err := uuid.FromString(s)
+		if err != nil {
+			return val, fmt.Errorf("parse uuid: %w", err)
+		}
+		val.Set(reflect.ValueOf(id))
+
+	case t.Kind() == reflect.String:
+		val.SetString(s)
+
+	case t.Kind() == reflect.Slice:
+		return val, fmt.Errorf("matrix type at %s is not supported", sf.Name)
+
+	default:
+		return val, fmt.Errorf("unsupported kind %s of %s at %s", t.Kind(), t, sf.Name)
 	}
-	field.Set(out)
-	return nil
+	return val, nil
 }
 
 // WithBinding wraps a typed handler function so that it automatically binds CLI
@@ -278,24 +610,116 @@ func setSliceField(ctx *cli.Command, name string, sf reflect.StructField, field
 // will be bound. The provided function fn receives a populated instance of T.
 //
 // This allows you to write clean, strongly typed handlers without manually
-// parsing or binding CLI flags.
+// parsing or binding CLI flags. The bound T is also stored in the handler's
+// context, see FromContext. Middleware given with Use wraps fn, WithRecover
+// turns its panics into errors, WithTimeoutField bounds its context and
+// WithExitCode maps its errors to exit codes.
 func WithBinding[T any](
 	fn func(ctx context.Context, t T) error,
+	opts ...Option,
 ) func(ctx context.Context, c *cli.Command) (err error) {
-	return func(ctx context.Context, c *cli.Command) (err error) {
-		var t T
-		if err = Bind(c, &t); err != nil {
-			return fmt.Errorf("bind flags: %w", err)
+	o := newOptions(opts)
+	h := chain(fn, o)
+	action := func(ctx context.Context, c *cli.Command) (err error) {
+		defer resetSources(c)
+		t, prov, err := bindCommand[T](ctx, c, o)
+		if err != nil {
+			return err
+		}
+		recordBound(ctx, t)
+		if o.printConfig && c.Bool(flagPrintConfig) {
+			return WriteConfig(c.Root().Writer, &t, c.Bool(flagShowSecrets))
+		}
+		if o.explainConfig && c.Bool(flagExplainConfig) {
+			return writeExplanation(c.Root().Writer, c, &t, o, prov)
 		}
-		return fn(ctx, t)
+		ctx, cancel, err := withTimeout(ctx, &t, o)
+		if err != nil {
+			return err
+		}
+		defer cancel()
+		if o.recoverPanics {
+			defer func() {
+				if r := recover(); r != nil {
+					err = &PanicError{Command: c.FullName(), Value: r, Stack: debug.Stack()}
+				}
+			}()
+		}
+		ctx = context.WithValue(ctx, provenanceKey{}, prov)
+		observeBinding(ctx, c, t)
+		return h(IntoContext(ctx, t), t)
+	}
+	if len(o.exitCodes) == 0 {
+		return action
+	}
+	return func(ctx context.Context, c *cli.Command) error {
+		return mapExitCode(action(ctx, c), o.exitCodes)
 	}
 }
 
+// boundRun is the configuration bindCommand bound for cmd in a Before hook,
+// which stores it in the context for the Action of the same run to reuse.
+type boundRun struct {
+	cmd  *cli.Command
+	cfg  any
+	prov *Provenance
+}
+
+type boundRunKey struct{}
+
+// bindCommand binds the flags of c into a T and completes it with resolveBound,
+// reporting flag usage, deprecations and instrumentation along the way, unless
+// a Before hook of c already did in ctx: then its T is returned.
+func bindCommand[T any](ctx context.Context, c *cli.Command, o *options) (t T, prov *Provenance, err error) {
+	if run, ok := ctx.Value(boundRunKey{}).(*boundRun); ok && run.cmd == c {
+		if t, ok := run.cfg.(T); ok {
+			return t, run.prov, nil
+		}
+	}
+	reportFlagUsage(ctx, c, o)
+	if o.dotenv != nil {
+		if err = o.dotenv.loadErr(); err != nil {
+			return t, nil, fmt.Errorf("%w: %w", ErrBind, err)
+		}
+	}
+	if o.strict != 0 {
+		if err = checkStrict(c, reflect.TypeFor[T](), o.strict, o.ownFlags()); err != nil {
+			return t, nil, fmt.Errorf("%w: %w", ErrBind, err)
+		}
+	}
+	start := time.Now()
+	bc := BindComplete{Command: c.FullName(), Type: reflect.TypeFor[T]()}
+	report := func(err error) {
+		if fn := o.instrumentation.OnBindComplete; fn != nil {
+			bc.Fields, bc.Err = countFields(bc.Type), err
+			fn(ctx, bc)
+		}
+	}
+	err = bind(c, &t, o)
+	bc.BindDuration = time.Since(start)
+	if err != nil {
+		report(err)
+		return t, nil, fmt.Errorf("%w: %w", ErrBind, err)
+	}
+	warnDeprecated(ctx, c, &t, o)
+	start = time.Now()
+	prov = &Provenance{Failures: sourceFailures(c)}
+	err = resolveBound(ctx, c, &t, o, prov)
+	bc.ResolveDuration = time.Since(start)
+	report(err)
+	return t, prov, err
+}
+
 // CommandWithBinding creates a new CLI command that automatically binds
 // command-line flags into a typed configuration struct before executing
 // the provided handler function.
 //
 // It combines command construction and type-safe binding in one step.
+// Options are passed on to FlagsFromStruct and WithBinding; WithBefore and
+// WithAfter set the command's Before and After, WithPrintConfig adds the
+// --print-config and --show-secrets flags, WithExplainConfig the
+// --explain-config flag, WithConfigFile the --config flag, and WithSetFlag the
+// --set flag.
 //
 // If base is nil, a new *cli.Command is created. The resulting command’s
 // Action is set using WithBinding(fn), and its Name is set to the provided
@@ -315,13 +739,45 @@ func CommandWithBinding[T any](
 	base *cli.Command,
 	name string,
 	fn func(ctx context.Context, t T) error,
+	opts ...Option,
 ) *cli.Command {
 	if base == nil {
 		base = &cli.Command{}
 	}
-	var t T
-	base.Flags = FlagsFromStruct(t)
-	base.Action = WithBinding(fn)
+	if base.Version != "" {
+		opts = append([]Option{WithVersion(base.Version)}, opts...)
+	}
+	base.Flags = Flags[T](opts...)
+	o := newOptions(opts)
+	if o.printConfig {
+		base.Flags = append(base.Flags, printConfigFlags()...)
+	}
+	if o.explainConfig {
+		base.Flags = append(base.Flags, explainConfigFlag())
+	}
+	if o.config != nil {
+		base.Flags = append(o.config.cliFlags(reflect.TypeFor[T]()), base.Flags...) // first, so their values are known when other flags consult the file
+	}
+	if o.flagsJSON != nil {
+		base.Flags = append([]cli.Flag{o.flagsJSON.cliFlag(reflect.TypeFor[T]())}, base.Flags...)
+	}
+	if o.configJSON != nil {
+		base.Flags = append([]cli.Flag{o.configJSON.cliFlag(reflect.TypeFor[T]())}, base.Flags...)
+	}
+	if o.setFlag {
+		base.Flags = append(base.Flags, setFlag())
+	}
+	if o.categoryOrder != nil {
+		SetCategoryOrder(base, o.categoryOrder...)
+	}
+	if b := before[T](o); b != nil {
+		base.Before = b
+	}
+	if base.ShellComplete == nil {
+		base.ShellComplete = ShellComplete
+	}
+	base.Action = WithBinding(fn, opts...)
+	installAfter[T](base, o)
 	base.Name = name
 	return base
 }
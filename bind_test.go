@@ -0,0 +1,68 @@
+package clibind_test
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/gofrs/uuid"
+	"github.com/urfave/cli/v3"
+)
+
+type mapConfig struct {
+	Weights map[int]float64      `cli:"weight,omitempty"`
+	Tenants map[uuid.UUID]string `cli:"tenant,omitempty"`
+	Ports   map[string][]int     `cli:"ports,omitempty"`
+	Flags   map[string]bool      `cli:"flag" cliDefault:"a=true"`
+}
+
+func TestMapKeysAndValues(t *testing.T) {
+	id := uuid.Must(uuid.FromString("6ba7b810-9dad-11d1-80b4-00c04fd430c8"))
+	root := &cli.Command{
+		Name:     "app",
+		Commands: []*cli.Command{clibind.CommandWithBinding(nil, "route", func(context.Context, mapConfig) error { return nil })},
+	}
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{
+		"route", "--weight", "1=0.5", "--weight", "2=1.5",
+		"--tenant", id.String() + "=acme", "--ports", "web=80;443", "--ports", "web=8080",
+	}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := mapConfig{
+		Weights: map[int]float64{1: 0.5, 2: 1.5},
+		Tenants: map[uuid.UUID]string{id: "acme"},
+		Ports:   map[string][]int{"web": {80, 443, 8080}},
+		Flags:   map[string]bool{"a": true},
+	}
+	if got := clibindtest.Bound[mapConfig](t, res, "app route"); !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+}
+
+func TestUnsupportedMapKeys(t *testing.T) {
+	for _, v := range []any{
+		struct {
+			M map[complex128]string `cli:"m,omitempty"`
+		}{},
+		struct {
+			M map[[2]int]string `cli:"m,omitempty"`
+		}{},
+		struct {
+			M map[struct{ A int }]string `cli:"m,omitempty"`
+		}{},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), "unsupported map key type") {
+					t.Errorf("%T: recovered %v, want an unsupported key type", v, r)
+				}
+			}()
+			clibind.FlagsFromStruct(v)
+		}()
+	}
+}
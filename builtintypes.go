@@ -0,0 +1,314 @@
+package clibind
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// builtinTypes holds the codecs of the standard library types that bind like
+// string flags. Their kinds (net.IP is a []byte, net.IPNet and the netip types
+// structs, url.URL too, os.FileMode a uint32, slog.Level an int) would
+// otherwise make them slices, nested structs or decimal numbers.
+var builtinTypes = map[reflect.Type]typeCodec{
+	reflect.TypeFor[net.IP](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			ip := net.ParseIP(s)
+			if ip == nil {
+				return reflect.Value{}, fmt.Errorf("parse ip: %q is not an IP address", s)
+			}
+			return reflect.ValueOf(ip), nil
+		},
+		format: func(v reflect.Value) string {
+			if v.Len() == 0 {
+				return ""
+			}
+			return v.Interface().(net.IP).String()
+		},
+	},
+	reflect.TypeFor[net.IPNet](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			_, n, err := net.ParseCIDR(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse cidr: %q is not a CIDR network such as 10.0.0.0/8", s)
+			}
+			return reflect.ValueOf(*n), nil
+		},
+		format: func(v reflect.Value) string {
+			n := v.Interface().(net.IPNet)
+			if n.IP == nil {
+				return ""
+			}
+			return n.String()
+		},
+	},
+	reflect.TypeFor[netip.Addr](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			a, err := netip.ParseAddr(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse ip: %w", err)
+			}
+			return reflect.ValueOf(a), nil
+		},
+		format: func(v reflect.Value) string {
+			if a := v.Interface().(netip.Addr); a.IsValid() {
+				return a.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeFor[netip.AddrPort](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			ap, err := netip.ParseAddrPort(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse ip:port %q: %w", s, err)
+			}
+			return reflect.ValueOf(ap), nil
+		},
+		format: func(v reflect.Value) string {
+			if ap := v.Interface().(netip.AddrPort); ap.IsValid() {
+				return ap.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeFor[netip.Prefix](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			p, err := netip.ParsePrefix(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse prefix: %w", err)
+			}
+			return reflect.ValueOf(p), nil
+		},
+		format: func(v reflect.Value) string {
+			if p := v.Interface().(netip.Prefix); p.IsValid() {
+				return p.String()
+			}
+			return ""
+		},
+	},
+	reflect.TypeFor[os.FileMode](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			m, err := parseFileMode(s)
+			return reflect.ValueOf(m), err
+		},
+		format: func(v reflect.Value) string { return formatFileMode(v.Interface().(os.FileMode)) },
+	},
+	reflect.TypeFor[slog.Level](): {
+		// the names of slog, in any case and with offsets such as debug+2, which
+		// custom levels between them are known by
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			var l slog.Level
+			if err := l.UnmarshalText([]byte(s)); err != nil {
+				return reflect.Value{}, fmt.Errorf("parse log level: %q is not one of debug, info, warn, error (optionally +N or -N)", s)
+			}
+			return reflect.ValueOf(l), nil
+		},
+		format: func(v reflect.Value) string { return strings.ToLower(v.Interface().(slog.Level).String()) },
+	},
+	reflect.TypeFor[url.URL](): {
+		parse: func(s string, sf reflect.StructField) (reflect.Value, error) {
+			u, err := url.Parse(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse url: %w", err)
+			}
+			if err := checkScheme(u, sf); err != nil {
+				return reflect.Value{}, err
+			}
+			return reflect.ValueOf(*u), nil
+		},
+		format: func(v reflect.Value) string {
+			u := v.Interface().(url.URL)
+			return u.String()
+		},
+	},
+}
+
+// fileModeBits maps the octal setuid, setgid and sticky bits of chmod to their
+// os.FileMode counterparts.
+var fileModeBits = [...]struct {
+	octal uint64
+	mode  os.FileMode
+}{{0o4000, os.ModeSetuid}, {0o2000, os.ModeSetgid}, {0o1000, os.ModeSticky}}
+
+// parseFileMode parses the octal permissions of chmod, such as 0644, 755 or
+// 0o2775, into an os.FileMode.
+func parseFileMode(s string) (os.FileMode, error) {
+	n, err := strconv.ParseUint(strings.TrimPrefix(strings.TrimPrefix(s, "0o"), "0O"), 8, 32)
+	if err != nil || n > 0o7777 {
+		return 0, fmt.Errorf("parse file mode: %q is not an octal mode from 0000 to 7777", s)
+	}
+	m := os.FileMode(n) & os.ModePerm
+	for _, b := range fileModeBits {
+		if n&b.octal != 0 {
+			m |= b.mode
+		}
+	}
+	return m, nil
+}
+
+// formatFileMode returns m in the octal form parseFileMode reads, e.g. 0644.
+func formatFileMode(m os.FileMode) string {
+	n := uint64(m.Perm())
+	for _, b := range fileModeBits {
+		if m&b.mode != 0 {
+			n |= b.octal
+		}
+	}
+	return fmt.Sprintf("%04o", n)
+}
+
+// checkScheme reports a URL whose scheme is not one of the cliScheme of sf,
+// schemes being case-insensitive.
+func checkScheme(u *url.URL, sf reflect.StructField) error {
+	schemes := splitCSV(sf.Tag.Get(tagCLIScheme))
+	switch {
+	case len(schemes) == 0 || slices.ContainsFunc(schemes, func(s string) bool { return strings.EqualFold(s, u.Scheme) }):
+		return nil
+	case u.Scheme == "":
+		return fmt.Errorf("url %q has no scheme, want %s://...", u.Redacted(), strings.Join(schemes, ":// or "))
+	}
+	return fmt.Errorf("url %q: scheme %q is not one of %s", u.Redacted(), u.Scheme, strings.Join(schemes, ", "))
+}
+
+// pointerTypes holds the codecs of the types used through pointers, the math/big
+// numbers, *regexp.Regexp, *time.Location and *mail.Address; init adds them to
+// builtinTypes along with their pointee types, which fields of pointer type
+// unreference to.
+var pointerTypes = map[reflect.Type]typeCodec{
+	reflect.TypeFor[*big.Int](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			i, ok := new(big.Int).SetString(s, 0)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("parse big.Int: %q is not an integer", s)
+			}
+			return reflect.ValueOf(i), nil
+		},
+		format: func(v reflect.Value) string { return v.Interface().(*big.Int).String() },
+	},
+	reflect.TypeFor[*big.Rat](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			r, ok := new(big.Rat).SetString(s)
+			if !ok {
+				return reflect.Value{}, fmt.Errorf("parse big.Rat: %q is not a fraction or decimal number", s)
+			}
+			return reflect.ValueOf(r), nil
+		},
+		format: func(v reflect.Value) string { return v.Interface().(*big.Rat).RatString() },
+	},
+	reflect.TypeFor[*big.Float](): {
+		// the precision grows with the digits given, so long amounts are not
+		// rounded to the 64 bits SetString would default to
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			prec := max(64, uint(math.Ceil(float64(len(s))*math.Log2(10))))
+			f, _, err := big.ParseFloat(s, 10, prec, big.ToNearestEven)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse big.Float: %q is not a number", s)
+			}
+			return reflect.ValueOf(f), nil
+		},
+		format: func(v reflect.Value) string { return v.Interface().(*big.Float).Text('g', -1) },
+	},
+	reflect.TypeFor[*regexp.Regexp](): {
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			re, err := regexp.Compile(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse regexp: %w", err)
+			}
+			return reflect.ValueOf(re), nil
+		},
+		format: func(v reflect.Value) string { return v.Interface().(*regexp.Regexp).String() },
+	},
+	reflect.TypeFor[*time.Location](): {
+		// IANA names as time.LoadLocation reads them, from the system zoneinfo
+		// or the tzdata embedded by importing time/tzdata
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			loc, err := time.LoadLocation(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse time zone: %w", err)
+			}
+			return reflect.ValueOf(loc), nil
+		},
+		format: func(v reflect.Value) string { return v.Interface().(*time.Location).String() },
+	},
+	reflect.TypeFor[*mail.Address](): {
+		// a single RFC 5322 address, with or without a display name: Alice
+		// <alice@example.com> or alice@example.com
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			a, err := mail.ParseAddress(s)
+			if err != nil {
+				return reflect.Value{}, fmt.Errorf("parse email address %q: %w", s, err)
+			}
+			return reflect.ValueOf(a), nil
+		},
+		format: func(v reflect.Value) string {
+			a := v.Interface().(*mail.Address)
+			if a.Name == "" {
+				return a.Address
+			}
+			return a.String()
+		},
+	},
+}
+
+func init() {
+	for t, c := range pointerTypes {
+		builtinTypes[t] = pointerCodec(c)
+		builtinTypes[t.Elem()] = pointeeCodec(c)
+	}
+}
+
+// pointerCodec prints nil pointers, those of a slice or a map, as empty.
+func pointerCodec(c typeCodec) typeCodec {
+	return typeCodec{
+		parse: c.parse,
+		format: func(v reflect.Value) string {
+			if v.IsNil() {
+				return ""
+			}
+			return c.format(v)
+		},
+	}
+}
+
+// pointeeCodec adapts the codec c of a pointer type to its pointee type, whose
+// zero value prints as empty, as unset.
+func pointeeCodec(c typeCodec) typeCodec {
+	return typeCodec{
+		parse: func(s string, sf reflect.StructField) (reflect.Value, error) {
+			v, err := c.parse(s, sf)
+			if err != nil {
+				return v, err
+			}
+			return v.Elem(), nil
+		},
+		format: func(v reflect.Value) string {
+			if v.IsZero() {
+				return ""
+			}
+			if !v.CanAddr() {
+				p := reflect.New(v.Type())
+				p.Elem().Set(v)
+				v = p.Elem()
+			}
+			return c.format(v.Addr())
+		},
+	}
+}
+
+func isBuiltinType(t reflect.Type) bool {
+	_, ok := builtinTypes[t]
+	return ok
+}
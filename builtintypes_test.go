@@ -0,0 +1,441 @@
+package clibind_test
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type zoneConfig struct {
+	TZ      *time.Location   `cli:"tz"`
+	Home    *time.Location   `cli:"home" cliDefault:"Local"`
+	Zones   []*time.Location `cli:"zone"`
+	Missing *time.Location   `cli:"missing"`
+}
+
+func TestLocationIsLoaded(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, zoneConfig) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--tz", "UTC", "--zone", "UTC,Local"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	got := clibindtest.Bound[zoneConfig](t, res, "app")
+	if got.TZ != time.UTC || got.Home != time.Local {
+		t.Errorf("TZ, Home = %p, %p; want time.UTC %p and time.Local %p as loaded", got.TZ, got.Home, time.UTC, time.Local)
+	}
+	if len(got.Zones) != 2 || got.Zones[0] != time.UTC || got.Zones[1] != time.Local {
+		t.Errorf("Zones = %v, want [UTC Local]", got.Zones)
+	}
+	if got.Missing != nil {
+		t.Errorf("Missing = %v, want nil", got.Missing)
+	}
+}
+
+type urlConfig struct {
+	Store *url.URL `cli:"store" cliScheme:"HTTPS,Vault"`
+}
+
+func TestURLSchemes(t *testing.T) {
+	for _, c := range []struct {
+		arg, err string
+	}{
+		{"vault://secrets/db", ""},
+		{"HTTPS://example.com", ""},
+		{"ftp://example.com", `scheme "ftp" is not one of HTTPS, Vault`},
+		{"example.com", "has no scheme, want HTTPS:// or Vault://..."},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, urlConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--store", c.arg}})
+		switch {
+		case c.err == "" && res.Err != nil:
+			t.Errorf("--store %s: %v", c.arg, res.Err)
+		case c.err != "" && (res.Err == nil || !strings.Contains(res.Err.Error(), c.err)):
+			t.Errorf("--store %s: err = %v, want %q", c.arg, res.Err, c.err)
+		}
+	}
+}
+
+// bindArgs binds args to a new command binding T.
+func bindArgs[T any](t *testing.T, args ...string) (T, error) {
+	t.Helper()
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, T) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: args})
+	if res.Err != nil {
+		var zero T
+		return zero, res.Err
+	}
+	return clibindtest.Bound[T](t, res, "app"), nil
+}
+
+// checkUnbind checks cfg unbinds to want.
+func checkUnbind(t *testing.T, cfg any, want ...string) {
+	t.Helper()
+	if got, err := clibind.Unbind(cfg); err != nil || !slices.Equal(got, want) {
+		t.Errorf("Unbind = %q, %v; want %q", got, err, want)
+	}
+}
+
+type ipConfig struct {
+	Bind  net.IP     `cli:"bind" cliDefault:"127.0.0.1"`
+	Peers []net.IP   `cli:"peer,omitempty"`
+	Net   net.IPNet  `cli:"net,omitempty"`
+	Allow *net.IPNet `cli:"allow,omitempty"`
+}
+
+func TestIP(t *testing.T) {
+	got, err := bindArgs[ipConfig](t, "--peer", "10.0.0.1,::1", "--net", "10.1.2.3/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Bind.Equal(net.IPv4(127, 0, 0, 1)) || len(got.Peers) != 2 || !got.Peers[1].Equal(net.IPv6loopback) {
+		t.Errorf("Bind, Peers = %v, %v; want 127.0.0.1, [10.0.0.1 ::1]", got.Bind, got.Peers)
+	}
+	if got.Net.String() != "10.0.0.0/8" || got.Allow != nil {
+		t.Errorf("Net, Allow = %v, %v; want 10.0.0.0/8, nil", got.Net.String(), got.Allow)
+	}
+	checkUnbind(t, got, "--bind", "127.0.0.1", "--peer", "10.0.0.1", "--peer", "::1", "--net", "10.0.0.0/8")
+
+	for _, c := range []struct{ arg, err string }{
+		{"--bind=10.0.0", `parse ip: "10.0.0" is not an IP address`},
+		{"--allow=10.0.0.0", `parse cidr: "10.0.0.0" is not a CIDR network such as 10.0.0.0/8`},
+	} {
+		if _, err := bindArgs[ipConfig](t, c.arg); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %q", c.arg, err, c.err)
+		}
+	}
+}
+
+type netipConfig struct {
+	Addr   netip.Addr     `cli:"addr" cliDefault:"::1"`
+	Listen netip.AddrPort `cli:"listen,omitempty"`
+	Routes []netip.Prefix `cli:"route,omitempty"`
+	Peer   *netip.Addr    `cli:"peer,omitempty"`
+}
+
+func TestNetip(t *testing.T) {
+	got, err := bindArgs[netipConfig](t, "--listen", "[::1]:8080", "--route", "10.0.0.0/8", "--route", "fd00::/8")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := netipConfig{
+		Addr:   netip.IPv6Loopback(),
+		Listen: netip.MustParseAddrPort("[::1]:8080"),
+		Routes: []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8"), netip.MustParsePrefix("fd00::/8")},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+	checkUnbind(t, got, "--addr", "::1", "--listen", "[::1]:8080", "--route", "10.0.0.0/8", "--route", "fd00::/8")
+
+	for _, c := range []struct{ arg, err string }{
+		{"--addr=localhost", "parse ip: "},
+		{"--listen=127.0.0.1", `parse ip:port "127.0.0.1": `},
+		{"--route=10.0.0.0", "parse prefix: "},
+	} {
+		if _, err := bindArgs[netipConfig](t, c.arg); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %q", c.arg, err, c.err)
+		}
+	}
+}
+
+type bigConfig struct {
+	Supply *big.Int   `cli:"supply"`
+	Mask   big.Int    `cli:"mask,omitempty"`
+	Ratio  *big.Rat   `cli:"ratio,omitempty"`
+	Amount *big.Float `cli:"amount,omitempty"`
+	Limit  *big.Int   `cli:"limit,omitempty"`
+}
+
+func TestBigNumbers(t *testing.T) {
+	const amount = "12345678901234567890.123456789"
+	got, err := bindArgs[bigConfig](t, "--supply", "100000000000000000000000", "--mask", "0xff", "--ratio", "0.75", "--amount", amount)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Supply.String() != "100000000000000000000000" || got.Mask.Int64() != 255 || got.Ratio.RatString() != "3/4" || got.Limit != nil {
+		t.Errorf("bound supply %v, mask %v, ratio %v, limit %v; want 1e23, 255, 3/4, nil", got.Supply, &got.Mask, got.Ratio, got.Limit)
+	}
+	if s := got.Amount.Text('f', 9); s != amount {
+		t.Errorf("Amount = %s, want %s unrounded", s, amount)
+	}
+	checkUnbind(t, bigConfig{Supply: big.NewInt(7), Ratio: big.NewRat(1, 3)}, "--supply", "7", "--ratio", "1/3")
+
+	for _, c := range []struct{ arg, err string }{
+		{"--supply=1.5", `parse big.Int: "1.5" is not an integer`},
+		{"--ratio=x", `parse big.Rat: "x" is not a fraction or decimal number`},
+		{"--amount=1e", `parse big.Float: "1e" is not a number`},
+	} {
+		if _, err := bindArgs[bigConfig](t, c.arg); err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %q", c.arg, err, c.err)
+		}
+	}
+}
+
+type regexpConfig struct {
+	Match   *regexp.Regexp   `cli:"match" cliDefault:"^v[0-9]+$"`
+	Exclude []*regexp.Regexp `cli:"exclude,omitempty"`
+}
+
+func TestRegexp(t *testing.T) {
+	got, err := bindArgs[regexpConfig](t, "--exclude", `\.tmp$`, "--exclude", "^#")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.Match.MatchString("v12") || got.Match.MatchString("v1.2") {
+		t.Errorf("Match = %v, want the default compiled", got.Match)
+	}
+	if len(got.Exclude) != 2 || !got.Exclude[0].MatchString("a.tmp") || !got.Exclude[1].MatchString("#x") {
+		t.Errorf("Exclude = %v, want [\\.tmp$ ^#]", got.Exclude)
+	}
+	checkUnbind(t, regexpConfig{Match: regexp.MustCompile("a+")}, "--match", "a+")
+
+	if _, err := bindArgs[regexpConfig](t, "--match", "(a"); err == nil || !strings.Contains(err.Error(), "parse regexp: error parsing regexp: missing closing )") {
+		t.Errorf("err = %v, want the compile error", err)
+	}
+}
+
+type fileModeConfig struct {
+	Mode   os.FileMode  `cli:"mode" cliDefault:"0644"`
+	Dir    os.FileMode  `cli:"dir-mode,omitempty"`
+	Socket *os.FileMode `cli:"socket-mode,omitempty"`
+}
+
+func TestFileMode(t *testing.T) {
+	got, err := bindArgs[fileModeConfig](t, "--dir-mode", "0o2775", "--socket-mode", "600")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Mode != 0o644 || got.Dir != os.ModeSetgid|0o775 || got.Socket == nil || *got.Socket != 0o600 {
+		t.Errorf("bound %v, %v, %v; want -rw-r--r--, g-rwxrwxr-x, -rw-------", got.Mode, got.Dir, got.Socket)
+	}
+	checkUnbind(t, got, "--mode", "0644", "--dir-mode", "2775", "--socket-mode", "0600")
+
+	for _, arg := range []string{"--mode=0888", "--mode=17777", "--mode=rw"} {
+		if _, err := bindArgs[fileModeConfig](t, arg); err == nil || !strings.Contains(err.Error(), "is not an octal mode from 0000 to 7777") {
+			t.Errorf("%s: err = %v, want an invalid mode", arg, err)
+		}
+	}
+}
+
+type logLevelConfig struct {
+	Level slog.Level   `cli:"log-level" cliDefault:"info"`
+	Trace *slog.Level  `cli:"trace-level,omitempty"`
+	Extra []slog.Level `cli:"extra,omitempty"`
+}
+
+func TestLogLevel(t *testing.T) {
+	got, err := bindArgs[logLevelConfig](t, "--trace-level", "DEBUG-4", "--extra", "Warn,error+2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Level != slog.LevelInfo || got.Trace == nil || *got.Trace != slog.LevelDebug-4 || !slices.Equal(got.Extra, []slog.Level{slog.LevelWarn, slog.LevelError + 2}) {
+		t.Errorf("bound %v, %v, %v; want INFO, DEBUG-4, [WARN ERROR+2]", got.Level, got.Trace, got.Extra)
+	}
+	checkUnbind(t, got, "--log-level", "info", "--trace-level", "debug-4", "--extra", "warn", "--extra", "error+2")
+
+	if _, err := bindArgs[logLevelConfig](t, "--log-level", "verbose"); err == nil ||
+		!strings.Contains(err.Error(), `parse log level: "verbose" is not one of debug, info, warn, error (optionally +N or -N)`) {
+		t.Errorf("err = %v, want the level names", err)
+	}
+}
+
+type mailConfig struct {
+	From *mail.Address  `cli:"from"`
+	To   []mail.Address `cli:"to,omitempty"`
+	Cc   *mail.Address  `cli:"cc,omitempty"`
+}
+
+func TestMailAddress(t *testing.T) {
+	got, err := bindArgs[mailConfig](t, "--from", "Alice <alice@example.com>", "--to", "bob@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got.From != (mail.Address{Name: "Alice", Address: "alice@example.com"}) || len(got.To) != 1 || got.To[0].Address != "bob@example.com" || got.Cc != nil {
+		t.Errorf("bound %+v, want Alice <alice@example.com> to bob@example.com", got)
+	}
+	checkUnbind(t, got, "--from", `"Alice" <alice@example.com>`, "--to", "bob@example.com")
+
+	if _, err := bindArgs[mailConfig](t, "--from", "alice"); err == nil || !strings.Contains(err.Error(), `parse email address "alice": mail: missing '@' or angle-addr`) {
+		t.Errorf("err = %v, want the address error", err)
+	}
+}
+
+type nullConfig struct {
+	Name    sql.NullString    `cli:"name"`
+	Retries sql.NullInt64     `cli:"retries"`
+	Active  sql.NullBool      `cli:"active"`
+	Region  sql.Null[string]  `cli:"region" cliDefault:"eu"`
+	Since   sql.NullTime      `cli:"since"`
+	Weight  sql.Null[float64] `cli:"weight"`
+}
+
+func TestSQLNull(t *testing.T) {
+	got, err := bindArgs[nullConfig](t, "--name", "", "--retries", "0", "--no-active")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := nullConfig{
+		Name:    sql.NullString{Valid: true},
+		Retries: sql.NullInt64{Valid: true},
+		Active:  sql.NullBool{Valid: true},
+		Region:  sql.Null[string]{V: "eu", Valid: true},
+	}
+	if got != want {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+	checkUnbind(t, nullConfig{Retries: sql.NullInt64{Int64: 3, Valid: true}}, "--retries", "3")
+
+	if _, err := bindArgs[nullConfig](t, "--weight", "heavy"); err == nil {
+		t.Error("--weight heavy was accepted")
+	}
+}
+
+type optionalConfig struct {
+	Retries clibind.Optional[int]    `cli:"retries" cliDefault:"3"`
+	Verbose clibind.Optional[bool]   `cli:"verbose"`
+	Name    clibind.Optional[string] `cli:"name" cliEnv:"NAME"`
+}
+
+func TestOptional(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want optionalConfig
+	}{
+		{"omitted", nil, nil, optionalConfig{Retries: clibind.Optional[int]{Value: 3}}},
+		{"zero values", []string{"--retries", "0", "--no-verbose", "--name", ""}, nil, optionalConfig{
+			Retries: clibind.NewOptional(0),
+			Verbose: clibind.NewOptional(false),
+			Name:    clibind.NewOptional(""),
+		}},
+		{"environment", []string{"--verbose"}, map[string]string{"NAME": "env"}, optionalConfig{
+			Retries: clibind.Optional[int]{Value: 3},
+			Verbose: clibind.NewOptional(true),
+			Name:    clibind.NewOptional("env"),
+		}},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, optionalConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: c.args, Env: c.env})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[optionalConfig](t, res, "app"); got != c.want {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+	checkUnbind(t, optionalConfig{Retries: clibind.Optional[int]{Value: 3}, Verbose: clibind.NewOptional(false)}, "--no-verbose")
+}
+
+// color is a type unknown to the binder until TestRegisterType registers it.
+type color struct{ r, g, b uint8 }
+
+func parseColor(s string) (color, error) {
+	var c color
+	if _, err := fmt.Sscanf(s, "#%02x%02x%02x", &c.r, &c.g, &c.b); err != nil {
+		return c, fmt.Errorf("%q is not a #rrggbb color", s)
+	}
+	return c, nil
+}
+
+func (c color) String() string { return fmt.Sprintf("#%02x%02x%02x", c.r, c.g, c.b) }
+
+type themeConfig struct {
+	Accent  color            `cli:"accent" cliDefault:"#000000"`
+	Palette []color          `cli:"palette,omitempty"`
+	Named   map[string]color `cli:"named,omitempty"`
+}
+
+func TestRegisterType(t *testing.T) {
+	if err := clibind.RegisterType(parseColor, color.String); err != nil {
+		t.Fatal(err)
+	}
+	got, err := bindArgs[themeConfig](t, "--accent", "#ff8000", "--palette", "#010203,#040506", "--named", "bg=#ffffff")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := themeConfig{
+		Accent:  color{0xff, 0x80, 0},
+		Palette: []color{{1, 2, 3}, {4, 5, 6}},
+		Named:   map[string]color{"bg": {0xff, 0xff, 0xff}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+	checkUnbind(t, themeConfig{Accent: color{0xff, 0x80, 0}}, "--accent", "#ff8000")
+
+	if _, err := bindArgs[themeConfig](t, "--accent", "orange"); err == nil || !strings.Contains(err.Error(), `"orange" is not a #rrggbb color`) {
+		t.Errorf("err = %v, want the parse error", err)
+	}
+}
+
+type parserConfig struct {
+	Accent  string            `cli:"accent,omitempty" cliParser:"lowerhex"`
+	Name    string            `cli:"name,omitempty"`
+	Palette []string          `cli:"palette,omitempty" cliParser:"lowerhex"`
+	Limits  map[string]string `cli:"limit,omitempty" cliParser:"lowerhex"`
+}
+
+type badParserConfig struct {
+	Count int `cli:"count" cliParser:"lowerhex"`
+}
+
+type unknownParserConfig struct {
+	Name string `cli:"name" cliParser:"nope"`
+}
+
+func TestRegisterParser(t *testing.T) {
+	if err := clibind.RegisterParser("lowerhex", func(s string) (string, error) {
+		if strings.Trim(strings.ToLower(s), "0123456789abcdef") != "" {
+			return "", fmt.Errorf("%q is not hexadecimal", s)
+		}
+		return strings.ToLower(s), nil
+	}); err != nil {
+		t.Fatal(err)
+	}
+	got, err := bindArgs[parserConfig](t, "--accent", "FF8000", "--name", "XY", "--palette", "AB,Cd", "--limit", "K=EF")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := parserConfig{Accent: "ff8000", Name: "XY", Palette: []string{"ab", "cd"}, Limits: map[string]string{"K": "ef"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+	if _, err := bindArgs[parserConfig](t, "--palette", "ab,xy"); err == nil || !strings.Contains(err.Error(), `"xy" is not hexadecimal`) {
+		t.Errorf("err = %v, want the parser's error", err)
+	}
+
+	for _, c := range []struct {
+		name   string
+		flags  func()
+		panics string
+	}{
+		{"other type", func() { clibind.FlagsFromStruct(&badParserConfig{}) }, `cliParser "lowerhex" parses string values, not int`},
+		{"unknown", func() { clibind.FlagsFromStruct(&unknownParserConfig{}) }, `cliParser "nope" is not registered`},
+	} {
+		func() {
+			defer func() {
+				if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), c.panics) {
+					t.Errorf("%s: panic %v, want %q", c.name, r, c.panics)
+				}
+			}()
+			c.flags()
+		}()
+	}
+}
@@ -0,0 +1,103 @@
+package clibind
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CachedProvider wraps a Provider so each reference is resolved at most once per
+// TTL, which keeps short-lived CLIs from hitting Vault or SSM on every run:
+//
+//	clibind.RegisterProvider(&clibind.CachedProvider{
+//	    Provider: &gcpsm.Client{},
+//	    TTL:      10 * time.Minute,
+//	    File:     filepath.Join(cacheDir, "secrets.json"),
+//	    StaleFor: time.Hour,
+//	})
+//
+// Without File, values are only cached in memory for the life of the process.
+type CachedProvider struct {
+	Provider Provider
+	TTL      time.Duration
+	// File persists resolved values across invocations. It holds them in plain
+	// text, so it is written with mode 0600 and should live in a private directory.
+	File string
+	// StaleFor keeps serving a value this long past its TTL while Provider fails,
+	// so commands still work briefly offline.
+	StaleFor time.Duration
+
+	mu      sync.Mutex
+	loaded  bool
+	entries map[string]cacheEntry
+}
+
+type cacheEntry struct {
+	Value   string    `json:"value"`
+	Fetched time.Time `json:"fetched"`
+}
+
+// Scheme implements Provider.
+func (c *CachedProvider) Scheme() string { return c.Provider.Scheme() }
+
+// Resolve implements Provider.
+func (c *CachedProvider) Resolve(ctx context.Context, ref string) (string, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.load()
+	e, ok := c.entries[ref]
+	age := time.Since(e.Fetched)
+	if ok && age < c.TTL {
+		return e.Value, nil
+	}
+	v, err := c.Provider.Resolve(ctx, ref)
+	if err != nil {
+		if ok && age < c.TTL+c.StaleFor {
+			return e.Value, nil
+		}
+		return "", err
+	}
+	c.entries[ref] = cacheEntry{Value: v, Fetched: time.Now()}
+	c.store()
+	return v, nil
+}
+
+// load reads File once; a missing or unreadable file starts an empty cache.
+func (c *CachedProvider) load() {
+	if c.loaded {
+		return
+	}
+	c.loaded = true
+	c.entries = map[string]cacheEntry{}
+	if c.File == "" {
+		return
+	}
+	if data, err := os.ReadFile(c.File); err == nil {
+		_ = json.Unmarshal(data, &c.entries)
+	}
+}
+
+// store writes the entries back to File, merged with what other invocations
+// wrote since it was loaded. Failing to persist only costs a lookup next time.
+func (c *CachedProvider) store() {
+	if c.File == "" {
+		return
+	}
+	onDisk := map[string]cacheEntry{}
+	if data, err := os.ReadFile(c.File); err == nil {
+		_ = json.Unmarshal(data, &onDisk)
+	}
+	for ref, e := range c.entries {
+		if cur, ok := onDisk[ref]; !ok || cur.Fetched.Before(e.Fetched) {
+			onDisk[ref] = e
+		}
+	}
+	data, err := json.Marshal(onDisk)
+	if err != nil || os.MkdirAll(filepath.Dir(c.File), 0o700) != nil {
+		return
+	}
+	_ = writeFileAtomic(c.File, data)
+}
@@ -0,0 +1,181 @@
+// Package clibindtest runs command trees built with clibind end to end, against
+// synthetic arguments, environment and files, and reports the configuration each
+// handler received, for black-box tests of a CLI:
+//
+//	func TestServe(t *testing.T) {
+//	    res := clibindtest.Run(t, newApp(), clibindtest.Input{
+//	        Args:  []string{"serve", "--config", "config.yaml"},
+//	        Env:   map[string]string{"APP_PORT": "9000"},
+//	        Files: map[string]string{"config.yaml": "host: db.internal\n"},
+//	    })
+//	    if res.Err != nil {
+//	        t.Fatal(res.Err)
+//	    }
+//	    cfg := clibindtest.Bound[ServeConfig](t, res, "app serve")
+//	    ...
+//	}
+//
+// Everything a real run goes through takes part: Before and After hooks, value
+// sources, config files, secret resolution and shell completion.
+package clibindtest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/urfave/cli/v3"
+)
+
+// completionFlag is the flag shells append to request completions.
+const completionFlag = "--generate-shell-completion"
+
+// Input is what a command tree runs against.
+type Input struct {
+	// Args are the command-line arguments, without the program name.
+	Args []string
+	// Env is the whole environment of the run: the variables of the test process
+	// are hidden, so pass PATH if the command runs other programs.
+	Env map[string]string
+	// Files are created in the working directory of the run, a fresh temporary
+	// directory, by slash-separated relative path. Args can name them as is.
+	Files map[string]string
+	// Stdin is the standard input of the run, both os.Stdin and the Reader of
+	// the root command.
+	Stdin string
+	// Complete requests shell completions for Args, as a shell would when the user
+	// presses tab after them; they are printed to Stdout, see Result.Completions.
+	Complete bool
+}
+
+// Call is the configuration a handler bound with clibind.WithBinding received.
+type Call struct {
+	// Command is the full name of the command, e.g. "app serve".
+	Command string
+	Config  any
+}
+
+// Result is the outcome of Run.
+type Result struct {
+	// Dir is the working directory the files of the input were created in.
+	Dir            string
+	Stdout, Stderr string
+	// Err is what the root command returned.
+	Err error
+	// ExitCode is the code the command exited with through cli.OsExiter, or 0.
+	ExitCode int
+	// Calls are the handlers run, in order.
+	Calls []Call
+}
+
+// Completions returns the lines of Stdout, the candidates printed for a run with
+// Input.Complete set.
+func (r *Result) Completions() []string {
+	var lines []string
+	sc := bufio.NewScanner(strings.NewReader(r.Stdout))
+	for sc.Scan() {
+		if l := strings.TrimSpace(sc.Text()); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
+
+// Run runs root against in and returns what happened. The output of the run is
+// captured, and the process state it needs (working directory, environment,
+// os.Args, os.Stdin, cli.OsExiter) is swapped for the duration of the test, so tests using
+// Run cannot be parallel. A command tree can only run once: build a new one for
+// each call.
+func Run(t testing.TB, root *cli.Command, in Input) *Result {
+	t.Helper()
+	res := &Result{Dir: t.TempDir()}
+	for name, content := range in.Files {
+		path := filepath.Join(res.Dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	t.Chdir(res.Dir)
+	for _, kv := range os.Environ() {
+		name, _, _ := strings.Cut(kv, "=")
+		if name == "" {
+			continue // Windows per-drive working directories, such as "=C:"
+		}
+		t.Setenv(name, "") // restores the variable when the test ends
+		os.Unsetenv(name)
+	}
+	for name, value := range in.Env {
+		t.Setenv(name, value)
+	}
+
+	args := append([]string{root.Name}, in.Args...)
+	if in.Complete {
+		root.EnableShellCompletion = true
+		args = append(args, completionFlag)
+	}
+	// completion looks at the raw arguments
+	stdin, err := os.CreateTemp(t.TempDir(), "stdin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stdin.Close()
+	if _, err := stdin.WriteString(in.Stdin); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := stdin.Seek(0, io.SeekStart); err != nil {
+		t.Fatal(err)
+	}
+	oldArgs, oldStdin, oldExiter, oldErrWriter := os.Args, os.Stdin, cli.OsExiter, cli.ErrWriter
+	defer func() { os.Args, os.Stdin, cli.OsExiter, cli.ErrWriter = oldArgs, oldStdin, oldExiter, oldErrWriter }()
+	os.Args, os.Stdin = args, stdin
+	cli.OsExiter = func(code int) { res.ExitCode = code }
+
+	var stdout, stderr bytes.Buffer
+	root.Writer, root.ErrWriter, root.Reader = &stdout, &stderr, strings.NewReader(in.Stdin)
+	cli.ErrWriter = &stderr
+	ctx := clibind.WithBindingObserver(context.Background(), func(cmd *cli.Command, cfg any) {
+		res.Calls = append(res.Calls, Call{Command: cmd.FullName(), Config: cfg})
+	})
+	res.Err = root.Run(ctx, args)
+	res.Stdout, res.Stderr = stdout.String(), stderr.String()
+	return res
+}
+
+// Bound returns the configuration of type T the handler of command, named by its
+// full name, received in the last of its calls. It fails the test if the handler
+// did not run or took another type.
+func Bound[T any](t testing.TB, r *Result, command string) T {
+	t.Helper()
+	for i := len(r.Calls) - 1; i >= 0; i-- {
+		if c := r.Calls[i]; c.Command == command {
+			cfg, ok := c.Config.(T)
+			if !ok {
+				t.Fatalf("clibindtest: %s received %T, not %T", command, c.Config, cfg)
+			}
+			return cfg
+		}
+	}
+	var zero T
+	t.Fatalf("clibindtest: %s did not run (ran %s)", command, ran(r.Calls))
+	return zero
+}
+
+func ran(calls []Call) string {
+	if len(calls) == 0 {
+		return "nothing"
+	}
+	names := make([]string, len(calls))
+	for i, c := range calls {
+		names[i] = c.Command
+	}
+	return strings.Join(names, ", ")
+}
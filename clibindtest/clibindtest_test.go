@@ -0,0 +1,283 @@
+package clibindtest_test
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type serveDB struct {
+	Name string `cli:"name"`
+	Pool int    `cli:"pool" cliDefault:"4"`
+}
+
+type serveConfig struct {
+	Host     string                 `cli:"host"`
+	Port     int                    `cli:"port" cliDefault:"8080"`
+	Region   string                 `cli:"region" cliDefault:"us-east-1"`
+	Zone     string                 `cli:"zone,omitempty" cliComplete:"e2eZones"`
+	Timeout  time.Duration          `cli:"timeout" cliUnit:"s" cliDefault:"10"`
+	Ratio    float64                `cli:"ratio" cliPrecision:"2" cliDefault:"0.5"`
+	Password clibind.Secret[string] `cli:"password,omitempty"`
+	Token    string                 `cli:"-" cliEnv:"APP_TOKEN" cliDefault:"anonymous"`
+	Listen   string                 `cli:"listen,omitempty"`
+	Addr     string                 `cli:"addr,omitempty" cliDeprecated:"use --listen" cliRemoveIn:"v3.0"`
+	Owner    string                 `cli:"owner,omitempty" cliSources:"e2e://owner"`
+	Team     string                 `cli:"team"`
+	Labels   map[string]string      `cli:"label,omitempty"`
+	DB       serveDB                `cli:"db" cliKV:"true"`
+}
+
+// e2eProvider resolves e2e:// references from a map.
+type e2eProvider map[string]string
+
+func (p e2eProvider) Scheme() string { return "e2e" }
+
+func (p e2eProvider) Resolve(_ context.Context, ref string) (string, error) {
+	v, ok := p[ref]
+	if !ok {
+		return "", errors.New("no such reference")
+	}
+	return v, nil
+}
+
+// teamSource is a ValueSource holding the team flag.
+type teamSource struct{}
+
+func (teamSource) Lookup(_ context.Context, flag string) (string, bool, error) {
+	return "infra", flag == "team", nil
+}
+
+func (teamSource) String() string { return "team store" }
+
+func init() {
+	if err := clibind.RegisterCompleter("e2eZones", func(context.Context) []string { return []string{"eu-1a", "eu-1b"} }); err != nil {
+		panic(err)
+	}
+}
+
+// app records what the hooks of a run saw.
+type app struct {
+	before   []serveConfig
+	used     []string
+	deprecs  []clibind.Deprecation
+	bindings []clibind.BindComplete
+}
+
+func (a *app) options() []clibind.Option {
+	return []clibind.Option{
+		clibind.WithEnvPrefix("APP_"),
+		clibind.WithDotenv(".env"),
+		clibind.WithConfigFile("config"),
+		clibind.WithFlagsJSON(),
+		clibind.WithConfigJSON(),
+		clibind.WithSetFlag(),
+		clibind.WithExplainConfig(),
+		clibind.WithStrictBind(clibind.StrictFields | clibind.StrictFlags),
+		clibind.WithProvider(e2eProvider{"e2e://owner": "ops"}),
+		clibind.WithSecretResolver("vault", func(_ context.Context, ref string) (string, error) {
+			return strings.ToUpper(strings.TrimPrefix(ref, "vault://")), nil
+		}),
+		clibind.WithValueSource(teamSource{}),
+		clibind.WithBefore(func(ctx context.Context, cfg serveConfig) (context.Context, error) {
+			a.before = append(a.before, cfg)
+			return ctx, nil
+		}),
+		clibind.WithFlagUsage(func(_ context.Context, _ string, flags []string) {
+			a.used = append(a.used, flags...)
+		}),
+		clibind.WithDeprecationWarnings(func(_ context.Context, d clibind.Deprecation) {
+			a.deprecs = append(a.deprecs, d)
+		}),
+		clibind.WithInstrumentation(clibind.Instrumentation{
+			OnBindComplete: func(_ context.Context, b clibind.BindComplete) { a.bindings = append(a.bindings, b) },
+		}),
+	}
+}
+
+func (a *app) command() *cli.Command {
+	return &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			clibind.CommandWithBinding(nil, "serve", func(context.Context, serveConfig) error { return nil }, a.options()...),
+			clibind.DoctorCommand[serveConfig](a.options()...),
+			clibind.ExplainCommand[serveConfig](a.options()...),
+			clibind.DiffCommand[serveConfig](),
+		},
+	}
+}
+
+func TestRunServe(t *testing.T) {
+	a := &app{}
+	res := clibindtest.Run(t, a.command(), clibindtest.Input{
+		Args: []string{
+			"serve", "--config", "config.yaml", "--db", "name=app",
+			"--password", "vault://pw", "--timeout", "30", "--ratio", "0.125",
+			"--addr", ":80", "--set", "db.pool=8",
+		},
+		Env: map[string]string{"APP_PORT": "9000", "APP_TOKEN": "t0k"},
+		Files: map[string]string{
+			"config.yaml": "host: db.internal\nlabel:\n  tier: prod\n",
+			".env":        "APP_REGION=eu-west-1\n",
+		},
+	})
+	if res.Err != nil {
+		t.Fatalf("%v\n%s", res.Err, res.Stderr)
+	}
+	cfg := clibindtest.Bound[serveConfig](t, res, "app serve")
+	for _, c := range []struct {
+		name      string
+		got, want any
+	}{
+		{"Host (config file)", cfg.Host, "db.internal"},
+		{"Labels (config file)", cfg.Labels["tier"], "prod"},
+		{"Port (environment)", cfg.Port, 9000},
+		{"Region (dotenv)", cfg.Region, "eu-west-1"},
+		{"Token (env only)", cfg.Token, "t0k"},
+		{"Timeout (cliUnit)", cfg.Timeout, 30 * time.Second},
+		{"Ratio (cliPrecision)", cfg.Ratio, 0.13},
+		{"Password (secret resolver)", cfg.Password.Value(), "PW"},
+		{"Owner (cliSources)", cfg.Owner, "ops"},
+		{"Team (value source)", cfg.Team, "infra"},
+		{"DB.Name (cliKV)", cfg.DB.Name, "app"},
+		{"DB.Pool (--set)", cfg.DB.Pool, 8},
+		{"Addr (deprecated)", cfg.Addr, ":80"},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+
+	if len(a.before) != 1 || a.before[0].Host != "db.internal" {
+		t.Errorf("Before saw %+v, want one run with the bound config", a.before)
+	}
+	if len(a.deprecs) != 1 || a.deprecs[0].Flag != "addr" || a.deprecs[0].RemoveIn != "v3.0" {
+		t.Errorf("deprecation warnings = %+v, want one for --addr", a.deprecs)
+	}
+	for _, name := range []string{"config", "db", "password", "timeout", "ratio", "addr"} {
+		if !slices.Contains(a.used, name) {
+			t.Errorf("flag usage %v misses --%s", a.used, name)
+		}
+	}
+	for _, name := range []string{"port", "region", "host", "label"} {
+		if slices.Contains(a.used, name) {
+			t.Errorf("flag usage %v has --%s, set by a source", a.used, name)
+		}
+	}
+	if len(a.bindings) != 1 || a.bindings[0].Command != "app serve" || a.bindings[0].Err != nil {
+		t.Errorf("bindings = %+v, want one successful binding of app serve", a.bindings)
+	}
+}
+
+func TestRunFlagsJSON(t *testing.T) {
+	res := clibindtest.Run(t, (&app{}).command(), clibindtest.Input{
+		Args:  []string{"serve", "--flags-json", "-", "--config-json", `{"DB": {"Pool": 2}}`},
+		Stdin: `{"host": "json.internal", "db": {"name": "x", "pool": 1}}`,
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	cfg := clibindtest.Bound[serveConfig](t, res, "app serve")
+	if cfg.Host != "json.internal" || cfg.DB.Name != "x" || cfg.DB.Pool != 2 {
+		t.Errorf("bound host %q, db %+v; want json.internal, {x 2}", cfg.Host, cfg.DB)
+	}
+}
+
+func TestRunExplainConfig(t *testing.T) {
+	res := clibindtest.Run(t, (&app{}).command(), clibindtest.Input{
+		Args: []string{"serve", "--explain-config", "--host", "h", "--db", "name=x", "--password", "vault://pw"},
+		Env:  map[string]string{"APP_PORT": "9000"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if len(res.Calls) != 0 {
+		t.Errorf("the handler ran along with --explain-config")
+	}
+	for _, want := range []string{`environment variable "APP_PORT"`, "--host", "team store", "[redacted]"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("explanation misses %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestRunExplain(t *testing.T) {
+	res := clibindtest.Run(t, (&app{}).command(), clibindtest.Input{
+		Args: []string{"explain", "--flag", "region", "--host", "h", "--db", "name=x"},
+		Files: map[string]string{
+			".env": "APP_REGION=eu-west-1\n",
+		},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	for _, want := range []string{"field:", "serveConfig.Region", "value:", "eu-west-1"} {
+		if !strings.Contains(res.Stdout, want) {
+			t.Errorf("explanation misses %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+func TestRunDoctor(t *testing.T) {
+	res := clibindtest.Run(t, (&app{}).command(), clibindtest.Input{
+		Args: []string{"doctor", "--config", "config.yaml", "--db", "name=x"},
+		Files: map[string]string{
+			"config.yaml": "port: 9000\n",
+		},
+	})
+	if !errors.Is(res.Err, clibind.ErrCheckFailed) {
+		t.Errorf("err = %v, want ErrCheckFailed for the missing --host", res.Err)
+	}
+	if !strings.Contains(res.Stdout, "FAIL") || !strings.Contains(res.Stdout, "host") {
+		t.Errorf("report misses the failing --host:\n%s", res.Stdout)
+	}
+}
+
+func TestRunConfigDiff(t *testing.T) {
+	res := clibindtest.Run(t, (&app{}).command(), clibindtest.Input{
+		Args: []string{"config-diff", "old.yaml", "new.yaml"},
+		Files: map[string]string{
+			"old.yaml": "host: a\ntimeout: 1m\n",
+			"new.yaml": "host: b\ntimeout: 60s\n",
+		},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if !strings.Contains(res.Stdout, "host") || !strings.Contains(res.Stdout, "(formatting only)") {
+		t.Errorf("diff misses the host change or the timeout re-spelling:\n%s", res.Stdout)
+	}
+}
+
+func TestRunCompletion(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"subcommands", nil, []string{"serve", "doctor", "explain", "config-diff"}},
+		{"flag values", []string{"serve", "--zone"}, []string{"eu-1a", "eu-1b"}},
+	} {
+		res := clibindtest.Run(t, (&app{}).command(), clibindtest.Input{Args: c.args, Complete: true})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		got := res.Completions()
+		for _, w := range c.want {
+			if !slices.ContainsFunc(got, func(s string) bool { return s == w || strings.HasPrefix(s, w+":") }) {
+				t.Errorf("%s: completions %v miss %s", c.name, got, w)
+			}
+		}
+	}
+}
+
+func TestCheckRoundTrip(t *testing.T) {
+	clibindtest.CheckRoundTrip[serveDB](t, 200)
+}
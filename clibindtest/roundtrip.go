@@ -0,0 +1,71 @@
+package clibindtest
+
+import (
+	"context"
+	"io"
+	"math/rand/v2"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/urfave/cli/v3"
+)
+
+// CheckRoundTrip binds n sets of random arguments for the flags of T (see
+// clibind.RandomArgs), unbinds each result with clibind.Unbind and binds that
+// again, failing the test unless both bindings are equal. It catches values a
+// field's parser and formatter disagree on. opts are those of the real command;
+// failures report the seed to reproduce them with CheckRoundTripSeed.
+func CheckRoundTrip[T any](t testing.TB, n int, opts ...clibind.Option) {
+	t.Helper()
+	CheckRoundTripSeed[T](t, uint64(time.Now().UnixNano()), n, opts...)
+}
+
+// CheckRoundTripSeed is CheckRoundTrip with a fixed seed.
+func CheckRoundTripSeed[T any](t testing.TB, seed uint64, n int, opts ...clibind.Option) {
+	t.Helper()
+	r := rand.New(rand.NewPCG(seed, seed))
+	for range n {
+		args := clibind.RandomArgs[T](r)
+		first, err := bindArgs[T](args, opts)
+		if err != nil {
+			t.Fatalf("clibindtest: seed %d: %q does not bind: %v", seed, args, err)
+		}
+		unbound, err := clibind.Unbind(first)
+		if err != nil {
+			t.Fatalf("clibindtest: seed %d: %v", seed, err)
+		}
+		second, err := bindArgs[T](unbound, opts)
+		if err != nil {
+			t.Fatalf("clibindtest: seed %d: %q, unbound from %q, does not bind: %v", seed, unbound, args, err)
+		}
+		if !reflect.DeepEqual(first, second) {
+			t.Fatalf("clibindtest: seed %d: %q binds\n%s\nbut its unbinding %q binds\n%s",
+				seed, args, dump(first), unbound, dump(second))
+		}
+	}
+}
+
+// bindArgs binds args to the flags of T like a command with opts would.
+func bindArgs[T any](args []string, opts []clibind.Option) (T, error) {
+	var cfg T
+	cmd := &cli.Command{
+		Name:      "roundtrip",
+		Flags:     clibind.Flags[T](opts...),
+		Writer:    io.Discard,
+		ErrWriter: io.Discard,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			return clibind.Bind(c, &cfg)
+		},
+	}
+	err := cmd.Run(context.Background(), append([]string{cmd.Name}, args...))
+	return cfg, err
+}
+
+func dump(cfg any) string {
+	var b strings.Builder
+	clibind.WriteConfig(&b, cfg, true)
+	return b.String()
+}
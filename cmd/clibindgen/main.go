@@ -0,0 +1,187 @@
+// Command clibindgen generates clibind.RegisterFieldDocs calls from the doc comments
+// of struct fields, so flag usage texts don't have to be duplicated into cliUsage tags.
+//
+// Usage:
+//
+//	//go:generate go run github.com/eosproject/urfave-cli-bind/cmd/clibindgen -type Config,ServerConfig
+//
+// Run without -type, every struct having at least one cli-tagged field is processed.
+// Fields with an explicit cliUsage tag are skipped, the tag always wins.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const clibindImport = "github.com/eosproject/urfave-cli-bind"
+
+func main() {
+	var (
+		typeNames = flag.String("type", "", "comma-separated list of struct type names; empty means every cli-tagged struct")
+		output    = flag.String("output", "clibind_docs.go", "output file name, relative to the package directory")
+	)
+	flag.Parse()
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+
+	if err := run(dir, *output, splitList(*typeNames)); err != nil {
+		log.Fatalf("clibindgen: %v", err)
+	}
+}
+
+type structDocs struct {
+	name   string
+	fields [][2]string // field name, doc
+}
+
+func run(dir, output string, typeNames []string) error {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.go"))
+	if err != nil {
+		return err
+	}
+
+	fset := token.NewFileSet()
+	var (
+		pkgName string
+		docs    []structDocs
+	)
+	for _, path := range paths {
+		base := filepath.Base(path)
+		if strings.HasSuffix(base, "_test.go") || base == filepath.Base(output) {
+			continue
+		}
+		f, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return err
+		}
+		pkgName = f.Name.Name
+		docs = append(docs, collectDocs(f, typeNames)...)
+	}
+	if pkgName == "" {
+		return fmt.Errorf("no Go files in %s", dir)
+	}
+	sort.Slice(docs, func(i, j int) bool { return docs[i].name < docs[j].name })
+
+	src, err := render(pkgName, docs)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(filepath.Join(dir, output), src, 0o644)
+}
+
+func collectDocs(f *ast.File, typeNames []string) []structDocs {
+	var out []structDocs
+	for _, decl := range f.Decls {
+		gd, ok := decl.(*ast.GenDecl)
+		if !ok || gd.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gd.Specs {
+			ts := spec.(*ast.TypeSpec)
+			st, ok := ts.Type.(*ast.StructType)
+			if !ok || ts.TypeParams != nil {
+				continue // generic types can only be registered once instantiated
+			}
+			if len(typeNames) > 0 && !slices.Contains(typeNames, ts.Name.Name) {
+				continue
+			}
+			if len(typeNames) == 0 && !hasCLITags(st) {
+				continue
+			}
+
+			sd := structDocs{name: ts.Name.Name}
+			for _, field := range st.Fields.List {
+				if field.Tag != nil && fieldTag(field).Get("cliUsage") != "" {
+					continue
+				}
+				doc := commentText(field.Doc)
+				if doc == "" {
+					doc = commentText(field.Comment)
+				}
+				if doc == "" {
+					continue
+				}
+				for _, name := range field.Names {
+					if name.IsExported() {
+						sd.fields = append(sd.fields, [2]string{name.Name, doc})
+					}
+				}
+			}
+			if len(sd.fields) > 0 {
+				out = append(out, sd)
+			}
+		}
+	}
+	return out
+}
+
+func hasCLITags(st *ast.StructType) bool {
+	for _, field := range st.Fields.List {
+		if field.Tag == nil {
+			continue
+		}
+		if _, ok := fieldTag(field).Lookup("cli"); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func fieldTag(field *ast.Field) reflect.StructTag {
+	tag, _ := strconv.Unquote(field.Tag.Value)
+	return reflect.StructTag(tag)
+}
+
+// commentText flattens a comment group into a single usage line.
+func commentText(cg *ast.CommentGroup) string {
+	if cg == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(cg.Text()), " ")
+}
+
+func render(pkgName string, docs []structDocs) ([]byte, error) {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by clibindgen; DO NOT EDIT.\n\n")
+	fmt.Fprintf(&b, "package %s\n\n", pkgName)
+	if len(docs) > 0 {
+		fmt.Fprintf(&b, "import clibind %q\n\n", clibindImport)
+		fmt.Fprintf(&b, "func init() {\n")
+		for _, sd := range docs {
+			fmt.Fprintf(&b, "\tclibind.RegisterFieldDocs[%s](map[string]string{\n", sd.name)
+			for _, fd := range sd.fields {
+				fmt.Fprintf(&b, "\t\t%q: %q,\n", fd[0], fd[1])
+			}
+			fmt.Fprintf(&b, "\t})\n")
+		}
+		fmt.Fprintf(&b, "}\n")
+	}
+	return format.Source(b.Bytes())
+}
+
+func splitList(s string) []string {
+	if s == "" {
+		return nil
+	}
+	parts := strings.Split(s, ",")
+	for i := range parts {
+		parts[i] = strings.TrimSpace(parts[i])
+	}
+	return parts
+}
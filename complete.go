@@ -0,0 +1,177 @@
+package clibind
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v3"
+)
+
+// completionFlag is the flag urfave appends to the arguments of a completion request.
+const completionFlag = "--generate-shell-completion"
+
+var (
+	completersMu sync.RWMutex
+	completers   = map[string]func(ctx context.Context) []string{}
+)
+
+// RegisterCompleter makes fn available to cliComplete tags as name. fn returns
+// the candidate values of a flag, e.g. the regions of a cloud account or the
+// profiles of a local config, when the shell completes it:
+//
+//	clibind.RegisterCompleter("listRegions", func(ctx context.Context) []string { ... })
+//
+//	type Config struct {
+//	    Region string `cli:"region" cliComplete:"listRegions"`
+//	}
+//
+// Registering a name again replaces the completer. It fails after Freeze.
+func RegisterCompleter(name string, fn func(ctx context.Context) []string) error {
+	completersMu.Lock()
+	defer completersMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterCompleter(%q)", name)); err != nil {
+		return err
+	}
+	completers[name] = fn
+	return nil
+}
+
+func completer(name string) (func(ctx context.Context) []string, bool) {
+	completersMu.RLock()
+	defer completersMu.RUnlock()
+	fn, ok := completers[name]
+	return fn, ok
+}
+
+// ShellComplete is a cli.ShellCompleteFunc completing the values of flags tagged
+// cliComplete with their completer, and everything else like
+// cli.DefaultCompleteWithFlags. CommandWithBinding installs it; set it as the
+// ShellComplete of hand-built commands, whose root needs EnableShellCompletion.
+func ShellComplete(ctx context.Context, cmd *cli.Command) {
+	// a flag missing its value parses into nothing: look at the raw arguments
+	args := commandArgs(cmd)
+	if len(args) > 0 && args[len(args)-1] == completionFlag {
+		args = args[:len(args)-1]
+	}
+	if len(args) > 1 {
+		if fn, ok := flagCompleter(cmd, args[len(args)-1]); ok {
+			for _, v := range fn(ctx) {
+				fmt.Fprintln(cmd.Root().Writer, v)
+			}
+			return
+		}
+	}
+	cli.DefaultCompleteWithFlags(ctx, cmd)
+}
+
+// flagCompleter returns the completer of the flag arg names, if it has one.
+func flagCompleter(cmd *cli.Command, arg string) (func(ctx context.Context) []string, bool) {
+	name, ok := strings.CutPrefix(arg, "-")
+	if !ok || name == "" {
+		return nil, false
+	}
+	name = strings.TrimPrefix(name, "-")
+	for _, c := range cmd.Lineage() {
+		for _, fl := range c.Flags {
+			if !slices.Contains(fl.Names(), name) {
+				continue
+			}
+			cf, ok := fl.(*completeFlag)
+			if !ok {
+				return nil, false
+			}
+			return completer(cf.completer)
+		}
+	}
+	return nil, false
+}
+
+// flagStruct returns the struct behind fl, that of the flag it wraps for a
+// completeFlag, so fields such as Sources or Hidden can be read and set whatever
+// the type of fl. It is not a struct for other implementations of cli.Flag.
+func flagStruct(fl cli.Flag) reflect.Value {
+	if cf, ok := fl.(*completeFlag); ok {
+		fl = cf.Flag
+	}
+	return reflect.Indirect(reflect.ValueOf(fl))
+}
+
+// completeFlag is a generated flag whose values the shell completes with the
+// completer named by the cliComplete tag of its field. It forwards the optional
+// interfaces of urfave flags to the flag it wraps; see flagStruct for the code
+// reading the fields of flags.
+type completeFlag struct {
+	cli.Flag
+	completer string
+}
+
+func (f *completeFlag) IsRequired() bool {
+	rf, ok := f.Flag.(cli.RequiredFlag)
+	return ok && rf.IsRequired()
+}
+
+func (f *completeFlag) IsVisible() bool {
+	vf, ok := f.Flag.(cli.VisibleFlag)
+	return !ok || vf.IsVisible()
+}
+
+func (f *completeFlag) IsLocal() bool {
+	lf, ok := f.Flag.(cli.LocalFlag)
+	return ok && lf.IsLocal()
+}
+
+func (f *completeFlag) IsBoolFlag() bool {
+	bf, ok := f.Flag.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+func (f *completeFlag) IsMultiValueFlag() bool {
+	mf, ok := f.Flag.(cli.DocGenerationMultiValueFlag)
+	return ok && mf.IsMultiValueFlag()
+}
+
+func (f *completeFlag) Count() int {
+	if cf, ok := f.Flag.(cli.Countable); ok {
+		return cf.Count()
+	}
+	return 0
+}
+
+func (f *completeFlag) GetCategory() string {
+	if cf, ok := f.Flag.(cli.CategorizableFlag); ok {
+		return cf.GetCategory()
+	}
+	return ""
+}
+
+func (f *completeFlag) SetCategory(category string) {
+	if cf, ok := f.Flag.(cli.CategorizableFlag); ok {
+		cf.SetCategory(category)
+	}
+}
+
+func (f *completeFlag) RunAction(ctx context.Context, cmd *cli.Command) error {
+	if af, ok := f.Flag.(cli.ActionableFlag); ok {
+		return af.RunAction(ctx, cmd)
+	}
+	return nil
+}
+
+// docFlag returns the wrapped flag as a cli.DocGenerationFlag, which all the
+// generated flags are.
+func (f *completeFlag) docFlag() cli.DocGenerationFlag {
+	df, _ := f.Flag.(cli.DocGenerationFlag)
+	return df
+}
+
+func (f *completeFlag) TakesValue() bool       { return f.docFlag().TakesValue() }
+func (f *completeFlag) GetUsage() string       { return f.docFlag().GetUsage() }
+func (f *completeFlag) GetValue() string       { return f.docFlag().GetValue() }
+func (f *completeFlag) GetDefaultText() string { return f.docFlag().GetDefaultText() }
+func (f *completeFlag) GetEnvVars() []string   { return f.docFlag().GetEnvVars() }
+func (f *completeFlag) IsDefaultVisible() bool { return f.docFlag().IsDefaultVisible() }
+func (f *completeFlag) TypeName() string       { return f.docFlag().TypeName() }
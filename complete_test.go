@@ -0,0 +1,51 @@
+package clibind_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type completeConfig struct {
+	Region string `cli:"region" cliEnv:"REGION" cliComplete:"completeRegions" cliUsage:"cloud region"`
+}
+
+func TestCompletedFlag(t *testing.T) {
+	if err := clibind.RegisterCompleter("completeRegions", func(context.Context) []string { return []string{"eu-west-1", "us-east-1"} }); err != nil {
+		t.Fatal(err)
+	}
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "deploy", func(context.Context, completeConfig) error { return nil }),
+			},
+		}
+	}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"deploy", "--region"}, Complete: true})
+	if got := res.Completions(); !slices.Equal(got, []string{"eu-west-1", "us-east-1"}) {
+		t.Errorf("completions = %v, want the regions", got)
+	}
+
+	// the flag is otherwise the one generated without cliComplete
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"deploy"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), `"region" not set`) {
+		t.Errorf("err = %v, want the required flag missing", res.Err)
+	}
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"deploy"}, Env: map[string]string{"REGION": "eu-west-1"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[completeConfig](t, res, "app deploy").Region; got != "eu-west-1" {
+		t.Errorf("Region = %q, want it from $REGION", got)
+	}
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"deploy", "--help"}})
+	if !strings.Contains(res.Stdout, "--region string  cloud region [$REGION]") {
+		t.Errorf("help misses --region:\n%s", res.Stdout)
+	}
+}
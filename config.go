@@ -0,0 +1,322 @@
+package clibind
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// WithConfigFile makes CommandWithBinding add a --flag option naming a JSON,
+// YAML (.yaml, .yml) or TOML (.toml) configuration file, and makes every
+// generated flag fall back to the file when it is not given on the command line
+// or in the environment: the precedence is flag > env > file > default. The
+// file may also be an https:// URL, see fetchRemote.
+//
+// Keys are flag names. Nested objects spell prefixes, so {"db": {"host": "x"}}
+// and {"db-host": "x"} both set --db-host. A cliFile tag gives a field a key of
+// its own instead, a dotted path of nested objects such as "database.host".
+// Arrays fill slice flags, and objects at a map field's key fill its entries. Files encrypted with SOPS are
+// decrypted first, see sopsDecrypt.
+//
+// The option carries the loaded file, so use a separate WithConfigFile for
+// every command.
+func WithConfigFile(flag string) Option {
+	cf := &configFile{flag: strings.TrimLeft(flag, "-")}
+	return func(o *options) {
+		o.config = cf
+	}
+}
+
+// WithConfigChecksum adds a --flag option to a command using WithConfigFile:
+// given a hex SHA-256 digest, the configuration file is only used if its
+// content matches, e.g.
+//
+//	app --config https://example.com/app.json --config-sha256 9f86d0...
+//
+// It must come after WithConfigFile.
+func WithConfigChecksum(flag string) Option {
+	return func(o *options) {
+		if o.config == nil {
+			panic("clibind: WithConfigChecksum needs WithConfigFile first")
+		}
+		o.config.checksumFlag = strings.TrimLeft(flag, "-")
+	}
+}
+
+// WithConfigPublicKey makes a command using WithConfigFile only trust
+// configuration files signed with minisign (https://jedisct1.github.io/minisign/)
+// by publicKey, the base64 key line of the .pub file or the whole file. The
+// signature is read from FILE.minisig, next to the file or URL. It must come
+// after WithConfigFile, and panics if publicKey is malformed.
+func WithConfigPublicKey(publicKey string) Option {
+	key, err := parseMinisignKey(publicKey)
+	if err != nil {
+		panic("clibind: WithConfigPublicKey: " + err.Error())
+	}
+	return func(o *options) {
+		if o.config == nil {
+			panic("clibind: WithConfigPublicKey needs WithConfigFile first")
+		}
+		o.config.publicKey = key
+	}
+}
+
+// configFile is the state shared by the --config flag and the value sources of
+// the generated flags. It is loaded on the first lookup, after urfave has parsed
+// the command line.
+type configFile struct {
+	flag string
+	path string // --config, set by urfave through Destination
+
+	checksumFlag string
+	sha256       string // --config-sha256
+	publicKey    *minisignKey
+	schema       reflect.Type // bound struct, see migrateConfig
+
+	once sync.Once
+	data map[string]any
+	keys map[string]string // cliFile keys by flag name
+	err  error
+}
+
+// cliFlags returns the --config flag, followed by the checksum flag if any, for
+// a command binding the struct type schema.
+func (cf *configFile) cliFlags(schema reflect.Type) []cli.Flag {
+	cf.schema = schema
+	flags := []cli.Flag{cf.cliFlag()}
+	if cf.checksumFlag != "" {
+		flags = append(flags, &cli.StringFlag{
+			Name:        cf.checksumFlag,
+			Usage:       "fail unless the --" + cf.flag + " file has this SHA-256 `DIGEST` (hex)",
+			Destination: &cf.sha256,
+		})
+	}
+	return flags
+}
+
+func (cf *configFile) cliFlag() cli.Flag {
+	return &cli.StringFlag{
+		Name:        cf.flag,
+		Usage:       "read defaults for all flags from `FILE` (a path or an https:// URL)",
+		Destination: &cf.path,
+		// flag actions run before required flags are checked, so a broken file is
+		// reported as such rather than as missing flags
+		Action: func(context.Context, *cli.Command, string) error {
+			return cf.loadErr()
+		},
+	}
+}
+
+// source returns the value source of the flag named name.
+func (cf *configFile) source(name string) cli.ValueSource {
+	return &configSource{file: cf, key: name}
+}
+
+func (cf *configFile) load() {
+	if cf.path == "" {
+		return
+	}
+	var data []byte
+	data, cf.err = cf.read(cf.path, checkConfigContentType)
+	if cf.err == nil {
+		cf.err = cf.verify(data)
+	}
+	if cf.err != nil {
+		cf.err = fmt.Errorf("config %s: %w", cf.path, cf.err)
+		return
+	}
+	format := configFormat(cf.path)
+	cf.data, cf.err = decodeConfig(format, data)
+	if cf.err == nil && isSOPS(cf.data) {
+		if data, cf.err = sopsDecrypt(format, data); cf.err == nil {
+			cf.data, cf.err = decodeConfig("json", data)
+		}
+	}
+	if cf.err == nil {
+		cf.err = migrateConfig(cf.schema, cf.data)
+	}
+	if cf.schema != nil {
+		walkLeafFields(cf.schema, "", func(name string, sf reflect.StructField) {
+			if key := sf.Tag.Get(tagCLIFile); key != "" {
+				if cf.keys == nil {
+					cf.keys = map[string]string{}
+				}
+				cf.keys[name] = key
+			}
+		})
+	}
+	if cf.err != nil {
+		cf.err = fmt.Errorf("config %s: %w", cf.path, cf.err)
+	}
+}
+
+// read reads the local file or https:// URL path.
+func (cf *configFile) read(path string, checkType func(string) error) ([]byte, error) {
+	if strings.HasPrefix(path, "https://") {
+		return fetchRemote(path, checkType)
+	}
+	return os.ReadFile(path)
+}
+
+// verify checks data against the --config-sha256 digest and the minisign
+// signature next to the file, when required. It runs on cached downloads too, so
+// a tampered cache is caught as well.
+func (cf *configFile) verify(data []byte) error {
+	if cf.sha256 != "" {
+		want := strings.ToLower(strings.TrimPrefix(cf.sha256, "sha256:"))
+		sum := sha256.Sum256(data)
+		if got := hex.EncodeToString(sum[:]); got != want {
+			return fmt.Errorf("sha256 is %s, want %s", got, want)
+		}
+	}
+	if cf.publicKey != nil {
+		sig, err := cf.read(cf.path+".minisig", nil)
+		if err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+		if err := cf.publicKey.verify(data, sig); err != nil {
+			return fmt.Errorf("signature: %w", err)
+		}
+	}
+	return nil
+}
+
+// configFormat returns the format of the file at path (or URL) from its extension.
+func configFormat(path string) string {
+	if u, err := url.Parse(path); err == nil && u.Scheme != "" {
+		path = u.Path
+	}
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	}
+	return "json"
+}
+
+func decodeConfig(format string, data []byte) (map[string]any, error) {
+	var m map[string]any
+	switch format {
+	case "yaml":
+		err := yaml.Unmarshal(data, &m)
+		return m, err
+	case "toml":
+		return decodeTOML(data)
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keep large integers exact
+	err := dec.Decode(&m)
+	return m, err
+}
+
+// loadErr loads the file if needed and returns the error doing so.
+func (cf *configFile) loadErr() error {
+	if cf.path == "" {
+		return nil
+	}
+	cf.once.Do(cf.load)
+	return cf.err
+}
+
+// lookup finds the value of the flag named name, descending into nested objects
+// whose key is a '-'-separated prefix of name, or along its cliFile key.
+func (cf *configFile) lookup(name string) (string, bool) {
+	if cf.loadErr() != nil || cf.data == nil {
+		return "", false
+	}
+	var v any
+	var ok bool
+	if key, custom := cf.keys[name]; custom {
+		v, ok = lookupPath(cf.data, key)
+	} else {
+		v, ok = lookupKey(cf.data, name)
+	}
+	if !ok || v == nil {
+		return "", false
+	}
+	return configString(v), true
+}
+
+func lookupKey(m map[string]any, name string) (any, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		rest, ok := strings.CutPrefix(name, k+"-")
+		if sub, isMap := v.(map[string]any); ok && isMap {
+			if v, ok := lookupKey(sub, rest); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// configString renders a decoded JSON or YAML value in the syntax the flag would take.
+func configString(v any) string {
+	switch v := v.(type) {
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	case bool:
+		return strconv.FormatBool(v)
+	case []any:
+		parts := make([]string, len(v))
+		for i, e := range v {
+			parts[i] = configString(e)
+		}
+		return strings.Join(parts, ",")
+	case map[string]any:
+		parts := make([]string, 0, len(v))
+		for k, e := range v {
+			if vs, ok := e.([]any); ok {
+				vals := make([]string, len(vs))
+				for i, x := range vs {
+					vals[i] = configString(x)
+				}
+				parts = append(parts, k+"="+strings.Join(vals, ";"))
+				continue
+			}
+			parts = append(parts, k+"="+configString(e))
+		}
+		slices.Sort(parts)
+		return strings.Join(parts, ",")
+	}
+	return fmt.Sprint(v)
+}
+
+// configSource is the cli.ValueSource of one flag in the configuration file.
+type configSource struct {
+	file *configFile
+	key  string
+}
+
+func (s *configSource) Lookup() (string, bool) { return s.file.lookup(s.key) }
+
+func (s *configSource) String() string {
+	key := s.key
+	if k, ok := s.file.keys[key]; ok {
+		key = k
+	}
+	return fmt.Sprintf("key %q in config file %q", key, s.file.path)
+}
+
+func (s *configSource) GoString() string {
+	return fmt.Sprintf("&configSource{key:%q}", s.key)
+}
@@ -0,0 +1,129 @@
+// Package consul looks clibind flag values up in the Consul KV store, under a
+// key prefix per service:
+//
+//	cmd := clibind.CommandWithBinding(nil, "serve", run,
+//	    clibind.WithValueSource(&consul.KV{Prefix: "config/myapp/"}),
+//	)
+//
+// The key of a flag is the prefix followed by the full flag name, so --db-host
+// reads config/myapp/db-host. The keys under the prefix are fetched in a single
+// request, on the first lookup; if it fails, every lookup reports that error.
+package consul
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+const defaultAddress = "http://127.0.0.1:8500"
+
+// KV is a clibind.ValueSource reading the keys under Prefix. The zero value
+// uses http.DefaultClient, and the address and token of the Consul CLI.
+type KV struct {
+	Prefix string
+	// Address of the Consul agent; empty means CONSUL_HTTP_ADDR, or else
+	// http://127.0.0.1:8500.
+	Address string
+	// Token is the ACL token; empty means CONSUL_HTTP_TOKEN.
+	Token      string
+	Datacenter string
+	HTTPClient *http.Client
+
+	mu      sync.Mutex
+	fetched bool
+	values  map[string]string
+	err     error // of the fetch
+}
+
+// Lookup implements clibind.ValueSource.
+func (kv *KV) Lookup(ctx context.Context, flag string) (string, bool, error) {
+	kv.mu.Lock()
+	defer kv.mu.Unlock()
+	if !kv.fetched {
+		kv.values, kv.err = kv.fetch(ctx)
+		kv.fetched = true
+	}
+	if kv.err != nil {
+		return "", false, kv.err
+	}
+	v, ok := kv.values[kv.Prefix+flag]
+	return v, ok, nil
+}
+
+// String implements clibind.ValueSource.
+func (kv *KV) String() string {
+	return "consul kv " + kv.Prefix
+}
+
+func (kv *KV) fetch(ctx context.Context) (map[string]string, error) {
+	addr := kv.Address
+	if addr == "" {
+		addr = os.Getenv("CONSUL_HTTP_ADDR")
+	}
+	if addr == "" {
+		addr = defaultAddress
+	}
+	if !strings.Contains(addr, "://") {
+		addr = "http://" + addr // CONSUL_HTTP_ADDR is often host:port
+	}
+	q := url.Values{"recurse": {""}}
+	if kv.Datacenter != "" {
+		q.Set("dc", kv.Datacenter)
+	}
+	u := strings.TrimSuffix(addr, "/") + "/v1/kv/" + kv.Prefix + "?" + q.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	token := kv.Token
+	if token == "" {
+		token = os.Getenv("CONSUL_HTTP_TOKEN")
+	}
+	if token != "" {
+		req.Header.Set("X-Consul-Token", token)
+	}
+	client := kv.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul: %w", err)
+	}
+	defer resp.Body.Close()
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound: // no key under the prefix
+		return map[string]string{}, nil
+	default:
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return nil, fmt.Errorf("consul: GET %s: %s: %s", kv.Prefix, resp.Status, strings.TrimSpace(string(body)))
+	}
+	var entries []struct {
+		Key   string
+		Value *string // base64, null for keys without a value
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("consul: decode %s: %w", kv.Prefix, err)
+	}
+	values := make(map[string]string, len(entries))
+	for _, e := range entries {
+		if e.Value == nil {
+			continue
+		}
+		v, err := base64.StdEncoding.DecodeString(*e.Value)
+		if err != nil {
+			return nil, fmt.Errorf("consul: key %s: %w", e.Key, err)
+		}
+		values[e.Key] = string(v)
+	}
+	return values, nil
+}
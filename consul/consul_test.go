@@ -0,0 +1,63 @@
+package consul_test
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/eosproject/urfave-cli-bind/consul"
+)
+
+func TestLookup(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/v1/kv/config/app/" || r.Header.Get("X-Consul-Token") != "t0k" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		fmt.Fprintf(w, `[{"Key": "config/app/db-host", "Value": %q}, {"Key": "config/app/empty", "Value": null}]`,
+			base64.StdEncoding.EncodeToString([]byte("db.internal")))
+	}))
+	defer srv.Close()
+
+	kv := &consul.KV{Prefix: "config/app/", Address: srv.URL, Token: "t0k"}
+	for _, c := range []struct {
+		flag, want string
+		ok         bool
+	}{
+		{"db-host", "db.internal", true},
+		{"empty", "", false},
+		{"port", "", false},
+	} {
+		v, ok, err := kv.Lookup(context.Background(), c.flag)
+		if err != nil || v != c.want || ok != c.ok {
+			t.Errorf("Lookup(%s) = %q, %t, %v; want %q, %t", c.flag, v, ok, err, c.want, c.ok)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want 1 for all lookups", requests)
+	}
+}
+
+func TestLookupError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		http.Error(w, "permission denied", http.StatusForbidden)
+	}))
+	defer srv.Close()
+
+	kv := &consul.KV{Prefix: "config/app/", Address: srv.URL}
+	for _, flag := range []string{"db-host", "port"} {
+		if _, _, err := kv.Lookup(context.Background(), flag); err == nil {
+			t.Errorf("Lookup(%s) succeeded against a failing agent", flag)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("%d requests, want 1: the failure holds for the run", requests)
+	}
+}
@@ -0,0 +1,39 @@
+package clibind
+
+import (
+	"context"
+
+	"github.com/urfave/cli/v3"
+)
+
+// configKey is the context key of a bound configuration of type T.
+type configKey[T any] struct{}
+
+// IntoContext returns a copy of ctx carrying cfg. WithBinding does this for the
+// bound configuration before calling the handler.
+func IntoContext[T any](ctx context.Context, cfg T) context.Context {
+	return context.WithValue(ctx, configKey[T]{}, cfg)
+}
+
+// FromContext returns the configuration of type T stored by IntoContext, so deeper
+// layers (middleware, libraries) can read it without threading it through every
+// signature. ok is false if ctx carries no T.
+func FromContext[T any](ctx context.Context) (cfg T, ok bool) {
+	cfg, ok = ctx.Value(configKey[T]{}).(T)
+	return cfg, ok
+}
+
+type observerKey struct{}
+
+// WithBindingObserver returns a copy of ctx on which WithBinding calls fn with
+// the command and the configuration it bound, right before running the handler.
+// It lets tests see what handlers were given, see package clibindtest.
+func WithBindingObserver(ctx context.Context, fn func(cmd *cli.Command, cfg any)) context.Context {
+	return context.WithValue(ctx, observerKey{}, fn)
+}
+
+func observeBinding(ctx context.Context, cmd *cli.Command, cfg any) {
+	if fn, ok := ctx.Value(observerKey{}).(func(*cli.Command, any)); ok {
+		fn(cmd, cfg)
+	}
+}
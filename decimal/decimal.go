@@ -0,0 +1,59 @@
+// Package decimal teaches clibind the decimal.Decimal type of
+// github.com/shopspring/decimal, for amounts that a float64 would round:
+//
+//	import clibinddecimal "github.com/eosproject/urfave-cli-bind/decimal"
+//
+//	type Config struct {
+//	    Amount decimal.Decimal   `cli:"amount"`
+//	    Fee    *decimal.Decimal  `cli:"fee,omitempty"`
+//	    Splits []decimal.Decimal `cli:"split,omitempty"`
+//	}
+//
+//	func main() {
+//	    if err := clibinddecimal.Register(); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    clibind.Freeze()
+//	    ...
+//	}
+//
+// Values are parsed with decimal.NewFromString, so 12.50, -0.001 and 1.2e3 are
+// accepted, and print without trailing zeros lost or added: --amount 12.50
+// binds 12.50 and is written back as 12.5, the same number.
+package decimal
+
+import (
+	"fmt"
+
+	"github.com/shopspring/decimal"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+// Register registers decimal.Decimal, and pointers to it, with
+// clibind.RegisterType. It fails after clibind.Freeze.
+func Register() error {
+	if err := clibind.RegisterType(parse, decimal.Decimal.String); err != nil {
+		return err
+	}
+	return clibind.RegisterType(func(s string) (*decimal.Decimal, error) {
+		d, err := parse(s)
+		if err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}, func(d *decimal.Decimal) string {
+		if d == nil {
+			return ""
+		}
+		return d.String()
+	})
+}
+
+func parse(s string) (decimal.Decimal, error) {
+	d, err := decimal.NewFromString(s)
+	if err != nil {
+		return decimal.Decimal{}, fmt.Errorf("parse decimal %q: %w", s, err)
+	}
+	return d, nil
+}
@@ -0,0 +1,72 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	defaultVarsMu sync.RWMutex
+	defaultVars   = map[string]*string{}
+)
+
+// RegisterDefaultVar makes the string variable v available to cliDefaultVar
+// tags as name, so a default can be set per build or distribution channel at
+// link time with -ldflags "-X":
+//
+//	var defaultRegion string // go build -ldflags "-X main.defaultRegion=eu-west-1"
+//
+//	func init() { clibind.RegisterDefaultVar("main.defaultRegion", &defaultRegion) }
+//
+//	type Config struct {
+//	    Region string `cli:"region" cliDefault:"us-east-1" cliDefaultVar:"main.defaultRegion"`
+//	}
+//
+// A non-empty v replaces the cliDefault of the field everywhere the default
+// shows: in the generated flag and its help, in exports and in the explanations
+// of values. v is read whenever flags are generated. Registering a name again
+// replaces the variable. It fails after Freeze.
+func RegisterDefaultVar(name string, v *string) error {
+	if v == nil {
+		panic("clibind: RegisterDefaultVar: nil variable")
+	}
+	defaultVarsMu.Lock()
+	defer defaultVarsMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterDefaultVar(%q)", name)); err != nil {
+		return err
+	}
+	defaultVars[name] = v
+	return nil
+}
+
+func defaultVar(name string) (*string, bool) {
+	defaultVarsMu.RLock()
+	defer defaultVarsMu.RUnlock()
+	v, ok := defaultVars[name]
+	return v, ok
+}
+
+// fieldDefault returns the default of the field sf: the value of its
+// cliDefaultVar variable if set, else its cliDefault tag.
+func fieldDefault(sf reflect.StructField) string {
+	if name := sf.Tag.Get(tagCLIDefaultVar); name != "" {
+		if v, ok := defaultVar(name); ok && *v != "" {
+			return *v
+		}
+	}
+	return sf.Tag.Get(tagCLIDefault)
+}
+
+// checkDefaultVar reports a cliDefaultVar tag of sf naming no registered
+// variable, which would otherwise silently leave the cliDefault in place.
+func checkDefaultVar(sf reflect.StructField) error {
+	name := sf.Tag.Get(tagCLIDefaultVar)
+	if name == "" {
+		return nil
+	}
+	if _, ok := defaultVar(name); !ok {
+		return fmt.Errorf("field %s: cliDefaultVar %q is not registered, see RegisterDefaultVar", sf.Name, name)
+	}
+	return nil
+}
@@ -0,0 +1,64 @@
+package clibind_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+// defaultRegion stands for a variable set with -ldflags "-X".
+var defaultRegion string
+
+func init() {
+	clibind.RegisterDefaultVar("clibind_test.defaultRegion", &defaultRegion)
+}
+
+type defaultVarConfig struct {
+	Region string `cli:"region" cliDefault:"us-east-1" cliDefaultVar:"clibind_test.defaultRegion"`
+}
+
+type unknownDefaultVarConfig struct {
+	Region string `cli:"region" cliDefaultVar:"nope"`
+}
+
+func TestDefaultVar(t *testing.T) {
+	for _, c := range []struct {
+		linked string
+		args   []string
+		want   string
+	}{
+		{"", nil, "us-east-1"},
+		{"eu-west-1", nil, "eu-west-1"},
+		{"eu-west-1", []string{"--region", "ap-south-1"}, "ap-south-1"},
+	} {
+		defaultRegion = c.linked
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, defaultVarConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: c.args})
+		if res.Err != nil {
+			t.Fatalf("linked %q: %v", c.linked, res.Err)
+		}
+		if got := clibindtest.Bound[defaultVarConfig](t, res, "app").Region; got != c.want {
+			t.Errorf("linked %q, args %q: Region = %q, want %q", c.linked, c.args, got, c.want)
+		}
+		if c.args != nil {
+			continue
+		}
+		root = clibind.CommandWithBinding(nil, "app", func(context.Context, defaultVarConfig) error { return nil })
+		res = clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+		if def := "(default: " + c.want + ")"; !strings.Contains(res.Stdout, def) {
+			t.Errorf("linked %q: help misses %s:\n%s", c.linked, def, res.Stdout)
+		}
+	}
+	defaultRegion = ""
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), `cliDefaultVar "nope" is not registered`) {
+			t.Errorf("panic %v, want the unregistered variable", r)
+		}
+	}()
+	clibind.FlagsFromStruct(&unknownDefaultVarConfig{})
+}
@@ -0,0 +1,82 @@
+package clibind
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/urfave/cli/v3"
+)
+
+// A Deprecation describes a flag whose field is tagged cliDeprecated or
+// cliRemoveIn.
+type Deprecation struct {
+	Flag     string
+	Message  string // cliDeprecated, e.g. "use --listen instead"; empty for "true"
+	RemoveIn string // cliRemoveIn, the release the flag goes away in
+}
+
+// String returns the warning printed when the flag is used.
+func (d Deprecation) String() string {
+	s := "flag --" + d.Flag + " is deprecated"
+	if d.RemoveIn != "" {
+		s += " and will be removed in " + d.RemoveIn
+	}
+	if d.Message != "" {
+		s += ": " + d.Message
+	}
+	return s
+}
+
+// usageNote is appended to the usage text of the flag.
+func (d Deprecation) usageNote() string {
+	s := "(deprecated"
+	if d.RemoveIn != "" {
+		s += ", removed in " + d.RemoveIn
+	}
+	if d.Message != "" {
+		s += ": " + d.Message
+	}
+	return s + ")"
+}
+
+func fieldDeprecation(name string, sf reflect.StructField) (Deprecation, bool) {
+	msg, deprecated := sf.Tag.Lookup(tagCLIDeprecated)
+	removeIn := sf.Tag.Get(tagCLIRemoveIn)
+	if !deprecated && removeIn == "" {
+		return Deprecation{}, false
+	}
+	if msg == "true" {
+		msg = ""
+	}
+	return Deprecation{Flag: name, Message: msg, RemoveIn: removeIn}, true
+}
+
+// WithDeprecationWarnings sends the warnings about deprecated flags, issued by
+// WithBinding when one is set, to fn instead of the command's ErrWriter, e.g. to
+// a logger or a channel:
+//
+//	clibind.WithDeprecationWarnings(func(ctx context.Context, d clibind.Deprecation) {
+//	    slog.WarnContext(ctx, d.String(), "flag", d.Flag, "removeIn", d.RemoveIn)
+//	})
+func WithDeprecationWarnings(fn func(ctx context.Context, d Deprecation)) Option {
+	return func(o *options) {
+		o.deprecationWarning = fn
+	}
+}
+
+// warnDeprecated warns about the deprecated flags set on c, cfg being bound.
+func warnDeprecated(ctx context.Context, c *cli.Command, cfg any, o *options) {
+	walkLeaves(reflect.ValueOf(cfg).Elem(), "", func(name string, sf reflect.StructField, _ reflect.Value) bool {
+		d, ok := fieldDeprecation(name, sf)
+		if !ok || !c.IsSet(name) {
+			return true
+		}
+		if o.deprecationWarning != nil {
+			o.deprecationWarning(ctx, d)
+		} else {
+			fmt.Fprintf(c.Root().ErrWriter, "Warning: %s\n", d)
+		}
+		return true
+	})
+}
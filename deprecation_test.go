@@ -0,0 +1,55 @@
+package clibind_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type deprecatedConfig struct {
+	Listen string `cli:"listen" cliDefault:":8080"`
+	Addr   string `cli:"addr,omitempty" cliDeprecated:"use --listen" cliRemoveIn:"v3.0"`
+	Debug  bool   `cli:"debug" cliDeprecated:"true" cliDefault:"false"`
+}
+
+func TestDeprecationWarnings(t *testing.T) {
+	for _, c := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"--addr", ":80"}, "Warning: flag --addr is deprecated and will be removed in v3.0: use --listen\n"},
+		{[]string{"--debug"}, "Warning: flag --debug is deprecated\n"},
+		{[]string{"--listen", ":80"}, ""},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, deprecatedConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: c.args})
+		if res.Err != nil {
+			t.Fatalf("%q: %v", c.args, res.Err)
+		}
+		if res.Stderr != c.want {
+			t.Errorf("%q: stderr %q, want %q", c.args, res.Stderr, c.want)
+		}
+	}
+
+	var got []clibind.Deprecation
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, deprecatedConfig) error { return nil },
+		clibind.WithDeprecationWarnings(func(_ context.Context, d clibind.Deprecation) { got = append(got, d) }))
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--addr", ":80", "--debug"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := []clibind.Deprecation{{Flag: "addr", Message: "use --listen", RemoveIn: "v3.0"}, {Flag: "debug"}}
+	if !slices.Equal(got, want) || res.Stderr != "" {
+		t.Errorf("warned %+v and wrote %q, want %+v only", got, res.Stderr, want)
+	}
+
+	root = clibind.CommandWithBinding(nil, "app", func(context.Context, deprecatedConfig) error { return nil })
+	res = clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+	if !strings.Contains(res.Stdout, "(deprecated, removed in v3.0: use --listen)") || !strings.Contains(res.Stdout, "(deprecated)") {
+		t.Errorf("help misses the deprecation notes:\n%s", res.Stdout)
+	}
+}
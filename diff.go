@@ -0,0 +1,114 @@
+package clibind
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/urfave/cli/v3"
+)
+
+// ConfigChange is a flag whose value differs between two configuration files.
+type ConfigChange struct {
+	Flag string
+	// Old and New are the values the flag gets from each file, formatted as by
+	// WriteConfig with secrets masked. A file without the flag gives its default.
+	Old, New     string
+	InOld, InNew bool
+	// Meaningful is false when the files only spell the same value differently,
+	// e.g. "1h" and "60m", or a file setting a flag to its default.
+	Meaningful bool
+}
+
+// DiffConfigFiles compares two configuration files (see WithConfigFile) as
+// commands binding T read them: after decryption and schema migrations, and
+// flag by flag with values parsed into the field types. Flags set
+// identically in both files are left out.
+func DiffConfigFiles[T any](oldPath, newPath string) ([]ConfigChange, error) {
+	t := reflect.TypeFor[T]()
+	files := [2]*configFile{{path: oldPath, schema: t}, {path: newPath, schema: t}}
+	for _, cf := range files {
+		if err := cf.loadErr(); err != nil {
+			return nil, err
+		}
+	}
+	var changes []ConfigChange
+	var err error
+	walkLeaves(reflect.New(t).Elem(), "", func(name string, sf reflect.StructField, _ reflect.Value) bool {
+		var raw [2]string
+		var in [2]bool
+		var shown, exact [2]string
+		for i, cf := range files {
+			raw[i], in[i] = cf.lookup(name)
+			s := raw[i]
+			if !in[i] {
+				s = fieldDefault(sf)
+			}
+			v := reflect.New(sf.Type).Elem()
+			if s != "" {
+				if perr := setFieldFromString(s, sf, allocReferenced(v)); perr != nil {
+					err = fmt.Errorf("config %s: flag %s: %w", cf.path, name, perr)
+					return false
+				}
+			}
+			shown[i], exact[i] = formatField(sf, v, false), formatField(sf, v, true)
+		}
+		if !in[0] && !in[1] || in[0] && in[1] && raw[0] == raw[1] {
+			return true
+		}
+		changes = append(changes, ConfigChange{
+			Flag:       name,
+			Old:        shown[0],
+			New:        shown[1],
+			InOld:      in[0],
+			InNew:      in[1],
+			Meaningful: exact[0] != exact[1],
+		})
+		return true
+	})
+	return changes, err
+}
+
+// WriteConfigDiff writes changes one per line: "+" for flags only the new file
+// sets, "-" for those only the old one sets, "~" for the others.
+func WriteConfigDiff(w io.Writer, changes []ConfigChange) error {
+	for _, c := range changes {
+		mark := "~"
+		switch {
+		case !c.InOld:
+			mark = "+"
+		case !c.InNew:
+			mark = "-"
+		}
+		note := ""
+		if !c.Meaningful {
+			note = " (formatting only)"
+		}
+		if _, err := fmt.Fprintf(w, "%s %s: %s -> %s%s\n", mark, c.Flag, c.Old, c.New, note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DiffCommand returns a "config-diff OLD NEW" subcommand printing the
+// differences between two configuration files of commands binding T, see
+// DiffConfigFiles and WriteConfigDiff.
+func DiffCommand[T any]() *cli.Command {
+	return &cli.Command{
+		Name:      "config-diff",
+		Usage:     "compare two configuration files",
+		ArgsUsage: "OLD NEW",
+		Action: func(ctx context.Context, c *cli.Command) error {
+			if c.NArg() != 2 {
+				return fmt.Errorf("config-diff: want 2 files, got %d arguments", c.NArg())
+			}
+			changes, err := DiffConfigFiles[T](c.Args().Get(0), c.Args().Get(1))
+			if err != nil {
+				return err
+			}
+			return WriteConfigDiff(c.Root().Writer, changes)
+		},
+	}
+}
@@ -0,0 +1,88 @@
+package clibind_test
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+type diffConfig struct {
+	Host     string                 `cli:"host" cliDefault:"localhost"`
+	Port     int                    `cli:"port" cliDefault:"80"`
+	Timeout  time.Duration          `cli:"timeout" cliDefault:"30s"`
+	Region   string                 `cli:"region,omitempty"`
+	Password clibind.Secret[string] `cli:"password,omitempty"`
+}
+
+// writeFiles writes the files named by the keys of files to a temporary
+// directory and returns it.
+func writeFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, data := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(data), 0o600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestDiffConfigFiles(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"old.yaml": "host: a\nport: 80\ntimeout: 1m\nregion: eu\npassword: old\n",
+		"new.json": `{"host": "b", "timeout": "60s", "password": "new"}`,
+	})
+	changes, err := clibind.DiffConfigFiles[diffConfig](filepath.Join(dir, "old.yaml"), filepath.Join(dir, "new.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []clibind.ConfigChange{
+		{Flag: "host", Old: "a", New: "b", InOld: true, InNew: true, Meaningful: true},
+		{Flag: "port", Old: "80", New: "80", InOld: true},
+		{Flag: "timeout", Old: "1m0s", New: "1m0s", InOld: true, InNew: true},
+		{Flag: "region", Old: "eu", InOld: true, Meaningful: true},
+		{Flag: "password", Old: "[redacted]", New: "[redacted]", InOld: true, InNew: true, Meaningful: true},
+	}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("changes = %+v, want %+v", changes, want)
+	}
+
+	var b strings.Builder
+	if err := clibind.WriteConfigDiff(&b, changes[:4]); err != nil {
+		t.Fatal(err)
+	}
+	const diff = `~ host: a -> b
+- port: 80 -> 80 (formatting only)
+~ timeout: 1m0s -> 1m0s (formatting only)
+- region: eu -> 
+`
+	if b.String() != diff {
+		t.Errorf("diff:\n%s\nwant:\n%s", b.String(), diff)
+	}
+}
+
+func TestDiffConfigFilesErrors(t *testing.T) {
+	dir := writeFiles(t, map[string]string{
+		"ok.yaml":  "host: a\n",
+		"bad.yaml": "port: eighty\n",
+		"v4.yaml":  "schema-version: 4\n",
+	})
+	for _, c := range []struct {
+		name, file, err string
+		diff            func(string, string) ([]clibind.ConfigChange, error)
+	}{
+		{"invalid value", "bad.yaml", "flag port: ", clibind.DiffConfigFiles[diffConfig]},
+		{"missing file", "nope.yaml", "nope.yaml", clibind.DiffConfigFiles[diffConfig]},
+		{"migration", "v4.yaml", "schema version 4 is newer than the supported 3", clibind.DiffConfigFiles[migratedConfig]},
+	} {
+		_, err := c.diff(filepath.Join(dir, "ok.yaml"), filepath.Join(dir, c.file))
+		if err == nil || !strings.Contains(err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %q", c.name, err, c.err)
+		}
+	}
+}
@@ -0,0 +1,36 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	fieldDocsMu sync.RWMutex
+	fieldDocs   = map[reflect.Type]map[string]string{}
+)
+
+// RegisterFieldDocs registers usage texts for the fields of T, keyed by Go field name.
+// FlagsFromStruct uses them for fields without a cliUsage tag.
+//
+// It is normally called from code generated by cmd/clibindgen, which lifts the
+// doc comments of the struct fields, so documentation lives once next to the field.
+// It fails after Freeze.
+func RegisterFieldDocs[T any](docs map[string]string) error {
+	fieldDocsMu.Lock()
+	defer fieldDocsMu.Unlock()
+	t := reflect.TypeFor[T]()
+	if err := checkFrozen(fmt.Sprintf("RegisterFieldDocs[%s]", t)); err != nil {
+		return err
+	}
+	fieldDocs[t] = docs
+	return nil
+}
+
+// fieldDoc returns the registered usage text of the field name of struct type t.
+func fieldDoc(t reflect.Type, name string) string {
+	fieldDocsMu.RLock()
+	defer fieldDocsMu.RUnlock()
+	return fieldDocs[t][name]
+}
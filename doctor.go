@@ -0,0 +1,279 @@
+package clibind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// ErrCheckFailed is returned by the doctor command when a check failed.
+var ErrCheckFailed = errors.New("configuration check failed")
+
+// defaultCheckTimeout bounds the network checks of the doctor command unless
+// WithSourceTimeout sets a default.
+const defaultCheckTimeout = 5 * time.Second
+
+// DoctorCommand returns a "doctor" subcommand validating the configuration of a
+// command binding T without running it. Give it the options of the real command,
+// so it resolves the same sources:
+//
+//	opts := []clibind.Option{clibind.WithAutoEnv(), clibind.WithConfigFile("config")}
+//	root.Commands = append(root.Commands,
+//	    clibind.CommandWithBinding(nil, "serve", serve, opts...),
+//	    clibind.DoctorCommand[ServeConfig](opts...),
+//	)
+//
+// It takes the same flags, but instead of stopping at the first problem it
+// reports a line per check: the config file loads, required flags are set,
+// values pass their validators and post-bind hooks, cliSources and secret
+// references resolve, and values tagged cliCheck point to an existing file
+// ("file") or directory ("dir"), or to a reachable host:port ("tcp") or URL
+// ("http"). Failed sources a later one made up for are warnings. The command
+// returns an error wrapping ErrCheckFailed if any check failed.
+func DoctorCommand[T any](opts ...Option) *cli.Command {
+	o := newOptions(opts)
+	flags, checks := lenientFlags[T](o, opts)
+	cmd := &cli.Command{
+		Name:  "doctor",
+		Usage: "check the configuration without running the command",
+		Flags: flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			defer resetSources(c)
+			var t T
+			return runDoctor(ctx, c, &t, o, checks)
+		},
+	}
+	return cmd
+}
+
+// lenientFlags returns the flags of a command binding T with opts, o, passed
+// through lenient.
+func lenientFlags[T any](o *options, opts []Option) ([]cli.Flag, []flagCheck) {
+	flags := Flags[T](opts...)
+	if o.config != nil {
+		flags = append(o.config.cliFlags(reflect.TypeFor[T]()), flags...)
+	}
+	if o.flagsJSON != nil {
+		flags = append([]cli.Flag{o.flagsJSON.cliFlag(reflect.TypeFor[T]())}, flags...)
+	}
+	if o.configJSON != nil {
+		flags = append([]cli.Flag{o.configJSON.cliFlag(reflect.TypeFor[T]())}, flags...)
+	}
+	if o.setFlag {
+		flags = append(flags, setFlag())
+	}
+	checks := make([]flagCheck, len(flags))
+	for i, fl := range flags {
+		checks[i] = lenient(fl)
+	}
+	return flags, checks
+}
+
+// flagCheck holds what lenient took from a flag, for the doctor to check itself.
+type flagCheck struct {
+	name             string
+	required         bool
+	validator        reflect.Value // func(T) error, or invalid
+	validateDefaults bool
+}
+
+// lenient clears the Required, Validator and Action of fl, which would make
+// urfave abort on the first problem.
+func lenient(fl cli.Flag) flagCheck {
+	fc := flagCheck{name: fl.Names()[0]}
+	v := flagStruct(fl)
+	if v.Kind() != reflect.Struct {
+		return fc
+	}
+	if f := v.FieldByName("Required"); f.IsValid() && f.Kind() == reflect.Bool {
+		fc.required = f.Bool()
+		f.SetBool(false)
+	}
+	if f := v.FieldByName("Validator"); f.IsValid() && f.Kind() == reflect.Func && !f.IsNil() {
+		fc.validator = reflect.ValueOf(f.Interface())
+		f.SetZero()
+	}
+	if f := v.FieldByName("ValidateDefaults"); f.IsValid() && f.Kind() == reflect.Bool {
+		fc.validateDefaults = f.Bool()
+	}
+	if f := v.FieldByName("Action"); f.IsValid() && f.Kind() == reflect.Func {
+		f.SetZero()
+	}
+	return fc
+}
+
+// doctorReport collects the result lines of the doctor command.
+type doctorReport struct {
+	tw     *tabwriter.Writer
+	failed int
+}
+
+func (r *doctorReport) add(status, check, detail string) {
+	if status == "FAIL" {
+		r.failed++
+	}
+	fmt.Fprintf(r.tw, "%s\t%s\t%s\n", status, check, detail)
+}
+
+func (r *doctorReport) check(check string, err error) {
+	if err != nil {
+		r.add("FAIL", check, err.Error())
+	} else {
+		r.add("PASS", check, "")
+	}
+}
+
+func runDoctor(ctx context.Context, c *cli.Command, cfg any, o *options, checks []flagCheck) error {
+	r := &doctorReport{tw: tabwriter.NewWriter(c.Root().Writer, 0, 4, 2, ' ', 0)}
+	if o.config != nil && o.config.path != "" {
+		r.check("config file "+o.config.path, o.config.loadErr())
+	}
+	if o.dotenv != nil {
+		r.check("dotenv file "+o.dotenv.path, o.dotenv.loadErr())
+	}
+	if o.flagsJSON != nil && o.flagsJSON.path != "" {
+		r.check("--"+flagFlagsJSON+" "+o.flagsJSON.path, o.flagsJSON.loadErr())
+	}
+	if o.configJSON != nil && o.configJSON.path != "" {
+		r.check("--"+flagConfigJSON, o.configJSON.loadErr())
+	}
+	for _, fc := range checks {
+		if fc.required && !c.IsSet(fc.name) {
+			r.add("FAIL", "--"+fc.name, "required flag not set")
+			continue
+		}
+		if fc.validator.IsValid() && (fc.validateDefaults || c.IsSet(fc.name)) {
+			if err := callValidator(fc.validator, c.Value(fc.name)); err != nil {
+				r.add("FAIL", "--"+fc.name, err.Error())
+			}
+		}
+	}
+	bindErr := bind(c, cfg, o)
+	r.check("bind", bindErr)
+	if bindErr == nil {
+		prov := &Provenance{Failures: sourceFailures(c)}
+		if err := resolveSources(ctx, c, cfg, o, prov); err != nil {
+			r.add("FAIL", "sources", err.Error())
+		} else {
+			for _, f := range prov.Failures {
+				r.add("WARN", "--"+f.Flag, fmt.Sprintf("source %s: %v", f.Source, f.Err))
+			}
+		}
+		if c.IsSet(flagSet) {
+			r.check("--"+flagSet, applySets(c, cfg, prov))
+		}
+		r.check("secrets", resolveSecrets(ctx, cfg, o))
+		r.check("post-bind", runPostBind(reflect.ValueOf(cfg).Elem()))
+		timeout := o.sourceTimeout("")
+		if timeout <= 0 {
+			timeout = defaultCheckTimeout
+		}
+		walkLeaves(reflect.ValueOf(cfg).Elem(), "", func(name string, sf reflect.StructField, fv reflect.Value) bool {
+			if kind := sf.Tag.Get(tagCLICheck); kind != "" {
+				for _, v := range checkValues(sf, fv) {
+					r.check(fmt.Sprintf("--%s %s %s", name, kind, v), checkValue(ctx, kind, v, timeout))
+				}
+			}
+			return true
+		})
+	}
+	if err := r.tw.Flush(); err != nil {
+		return err
+	}
+	if r.failed > 0 {
+		return fmt.Errorf("%w: %d failed", ErrCheckFailed, r.failed)
+	}
+	return nil
+}
+
+// callValidator calls a flag's func(T) error with the flag's value.
+func callValidator(fn reflect.Value, value any) error {
+	v := reflect.ValueOf(value)
+	if !v.IsValid() || !v.Type().AssignableTo(fn.Type().In(0)) {
+		return nil
+	}
+	err, _ := fn.Call([]reflect.Value{v})[0].Interface().(error)
+	return err
+}
+
+// checkValues returns the non-empty values of the field fv, one per slice element.
+func checkValues(sf reflect.StructField, fv reflect.Value) []string {
+	fv = reflect.Indirect(fv)
+	if !fv.IsValid() {
+		return nil
+	}
+	var vals []string
+	if fv.Kind() == reflect.Slice && !isCustomType(fv.Type()) {
+		for i := range fv.Len() {
+			vals = append(vals, formatScalar(fv.Index(i), sf, true))
+		}
+	} else {
+		vals = append(vals, formatScalar(fv, sf, true))
+	}
+	var nonEmpty []string
+	for _, v := range vals {
+		if v != "" {
+			nonEmpty = append(nonEmpty, v)
+		}
+	}
+	return nonEmpty
+}
+
+// checkValue runs the cliCheck check kind on v.
+func checkValue(ctx context.Context, kind, v string, timeout time.Duration) error {
+	switch kind {
+	case "file", "dir":
+		fi, err := os.Stat(v)
+		switch {
+		case err != nil:
+			return err
+		case kind == "file" && fi.IsDir():
+			return errors.New("is a directory")
+		case kind == "dir" && !fi.IsDir():
+			return errors.New("not a directory")
+		}
+		return nil
+	case "tcp":
+		var d net.Dialer
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		conn, err := d.DialContext(ctx, "tcp", v)
+		if err != nil {
+			return err
+		}
+		return conn.Close()
+	case "http":
+		u, err := url.Parse(v)
+		if err != nil {
+			return err
+		}
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodHead, u.String(), nil)
+		if err != nil {
+			return err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return err
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode >= 500 {
+			return fmt.Errorf("HEAD: %s", resp.Status)
+		}
+		return nil
+	}
+	return fmt.Errorf("unknown cliCheck %q, want file, dir, tcp or http", strings.TrimSpace(kind))
+}
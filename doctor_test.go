@@ -0,0 +1,117 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type doctorConfig struct {
+	Host    string `cli:"host"`
+	Data    string `cli:"data" cliCheck:"dir" cliDefault:"data"`
+	Cert    string `cli:"cert,omitempty" cliCheck:"file"`
+	Backend string `cli:"backend,omitempty" cliCheck:"tcp"`
+	Health  string `cli:"health,omitempty" cliCheck:"http"`
+	Token   string `cli:"token,omitempty" cliSources:"doctordown://token,doctorup://token"`
+	Workers int    `cli:"workers" cliDefault:"4"`
+}
+
+func init() {
+	clibind.RegisterPostBind(func(c *doctorConfig) error {
+		if c.Workers < 1 {
+			return errors.New("workers must be positive")
+		}
+		return nil
+	})
+}
+
+func TestDoctor(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	srv := httptest.NewServer(http.HandlerFunc(func(http.ResponseWriter, *http.Request) {}))
+	defer srv.Close()
+
+	newRoot := func() *cli.Command {
+		opts := []clibind.Option{
+			clibind.WithSetFlag(),
+			clibind.WithSecretResolver("doctordown", func(context.Context, string) (string, error) { return "", errors.New("store down") }),
+			clibind.WithSecretResolver("doctorup", func(context.Context, string) (string, error) { return "t0k", nil }),
+		}
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "serve", func(context.Context, doctorConfig) error { return nil }, opts...),
+				clibind.DoctorCommand[doctorConfig](opts...),
+			},
+		}
+	}
+	files := map[string]string{"data/.keep": "", "cert.pem": "x"}
+
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{
+		Args:  []string{"doctor", "--host", "h", "--cert", "cert.pem", "--backend", ln.Addr().String(), "--health", srv.URL},
+		Files: files,
+	})
+	if res.Err != nil {
+		t.Errorf("err = %v, want every check passing:\n%s", res.Err, res.Stdout)
+	}
+	for _, want := range []string{
+		"PASS bind",
+		"WARN --token",
+		"source doctordown://token: store down",
+		"PASS --data dir data",
+		"PASS --cert file cert.pem",
+		"PASS --backend tcp " + ln.Addr().String(),
+		"PASS --health http " + srv.URL,
+		"PASS post-bind",
+	} {
+		if !strings.Contains(report(res.Stdout), want) {
+			t.Errorf("report misses %q:\n%s", want, res.Stdout)
+		}
+	}
+	if len(res.Calls) != 0 {
+		t.Error("the doctor bound the command")
+	}
+
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{
+		Args:  []string{"doctor", "--cert", "data", "--set", "workers=0", "--data", "cert.pem"},
+		Files: files,
+	})
+	if !errors.Is(res.Err, clibind.ErrCheckFailed) || !strings.Contains(res.Err.Error(), "4 failed") {
+		t.Errorf("err = %v, want 4 checks failed", res.Err)
+	}
+	for _, want := range []string{
+		"FAIL --host",
+		"required flag not set",
+		"FAIL --data dir cert.pem",
+		"not a directory",
+		"FAIL --cert file data",
+		"is a directory",
+		"FAIL post-bind",
+		"workers must be positive",
+	} {
+		if !strings.Contains(report(res.Stdout), want) {
+			t.Errorf("report misses %q:\n%s", want, res.Stdout)
+		}
+	}
+}
+
+// report folds the column padding of a table printed by a tabwriter to single
+// spaces.
+func report(s string) string {
+	lines := strings.Split(s, "\n")
+	for i, line := range lines {
+		lines[i] = strings.Join(strings.Fields(line), " ")
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,161 @@
+package clibind
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"strings"
+	"sync"
+)
+
+// WithDotenv makes every generated flag also look its environment variables up
+// in the dotenv file at path, KEY=value lines as many services keep in a .env
+// for local development:
+//
+//	# comments and blank lines are skipped
+//	export DB_HOST=localhost
+//	DB_PASSWORD='s3cr#t'
+//	GREETING="hello\nworld"
+//
+// A flag looks for the same variables in the file as in the environment: those
+// of its cliEnv tag, or else the one derived by WithAutoEnv, so flags without
+// environment variables do not read the file either. The environment wins
+// over the file, which wins over the config file: the precedence is flag > env
+// > dotenv > file > default. Fields tagged cli:"-" read it too in commands
+// built by CommandWithBinding, DoctorCommand and ExplainCommand. A missing
+// file is not an error, the file is only read on the first lookup, after the
+// command line is parsed.
+//
+// The option carries the loaded file, like WithConfigFile.
+func WithDotenv(path string) Option {
+	d := &dotenvFile{path: path}
+	return func(o *options) {
+		o.dotenv = d
+	}
+}
+
+// dotenvFile is the state shared by the value sources of the generated flags.
+type dotenvFile struct {
+	path string
+
+	once sync.Once
+	vars map[string]string
+	err  error
+}
+
+func (d *dotenvFile) loadErr() error {
+	d.once.Do(func() {
+		data, err := os.ReadFile(d.path)
+		switch {
+		case errors.Is(err, fs.ErrNotExist):
+		case err != nil:
+			d.err = err
+		default:
+			d.vars, d.err = parseDotenv(data)
+			if d.err != nil {
+				d.err = fmt.Errorf("dotenv %s: %w", d.path, d.err)
+			}
+		}
+	})
+	return d.err
+}
+
+func (d *dotenvFile) lookup(name string) (string, bool) {
+	if d.loadErr() != nil {
+		return "", false
+	}
+	v, ok := d.vars[name]
+	return v, ok
+}
+
+// parseDotenv parses KEY=value lines. Values may be single-quoted, taken as
+// is, or double-quoted, with \n, \t, \" and \\ escapes. Unquoted values end at
+// a " #" comment. Variables are not expanded.
+func parseDotenv(data []byte) (map[string]string, error) {
+	vars := map[string]string{}
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; sc.Scan(); n++ {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		key, value, ok := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !ok || key == "" || strings.ContainsAny(key, " \t") {
+			return nil, fmt.Errorf("line %d: want KEY=value", n)
+		}
+		value = strings.TrimSpace(value)
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.IndexByte(value[1:], '\'')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated quote", n)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			var b strings.Builder
+			i := 1
+			for ; i < len(value) && value[i] != '"'; i++ {
+				if value[i] == '\\' && i+1 < len(value) {
+					i++
+					switch value[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(value[i])
+					}
+					continue
+				}
+				b.WriteByte(value[i])
+			}
+			if i == len(value) {
+				return nil, fmt.Errorf("line %d: unterminated quote", n)
+			}
+			value = b.String()
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+		}
+		vars[key] = value
+	}
+	return vars, sc.Err()
+}
+
+// dotenvSource is the cli.ValueSource of one variable in the dotenv file.
+type dotenvSource struct {
+	file *dotenvFile
+	key  string
+}
+
+func (s *dotenvSource) Lookup() (string, bool) { return s.file.lookup(s.key) }
+
+func (s *dotenvSource) String() string {
+	return fmt.Sprintf("key %q in dotenv file %q", s.key, s.file.path)
+}
+
+func (s *dotenvSource) GoString() string {
+	return fmt.Sprintf("&dotenvSource{key:%q}", s.key)
+}
+
+// lookupEnv returns the value of the environment variable name for the cli:"-"
+// fields, falling back to the dotenv file of o, and where it was found.
+func (o *options) lookupEnv(name string) (value, source string, ok bool) {
+	if v, ok := os.LookupEnv(name); ok {
+		return v, fmt.Sprintf("environment variable %q", name), true
+	}
+	if o.dotenv == nil {
+		return "", "", false
+	}
+	src := &dotenvSource{file: o.dotenv, key: name}
+	if v, ok := src.Lookup(); ok {
+		return v, src.String(), true
+	}
+	return "", "", false
+}
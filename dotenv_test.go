@@ -0,0 +1,102 @@
+package clibind_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type dotenvConfig struct {
+	Host  string `cli:"host" cliDefault:"localhost"`
+	Port  int    `cli:"port" cliEnv:"PORT" cliDefault:"80"`
+	Token string `cli:"-" cliEnv:"TOKEN"`
+}
+
+func TestDotenvIsNotAutoEnv(t *testing.T) {
+	newRoot := func(opts ...clibind.Option) *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "serve", func(context.Context, dotenvConfig) error { return nil },
+					append(opts, clibind.WithDotenv(".env"))...),
+			},
+		}
+	}
+	files := map[string]string{".env": "HOST=example.com\nPORT=8080\nTOKEN=t0k\n"}
+	for _, c := range []struct {
+		name string
+		opts []clibind.Option
+		want dotenvConfig
+	}{
+		{"cliEnv only", nil, dotenvConfig{Host: "localhost", Port: 8080, Token: "t0k"}},
+		{"WithAutoEnv", []clibind.Option{clibind.WithAutoEnv()}, dotenvConfig{Host: "example.com", Port: 8080, Token: "t0k"}},
+	} {
+		res := clibindtest.Run(t, newRoot(c.opts...), clibindtest.Input{Args: []string{"serve"}, Files: files})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[dotenvConfig](t, res, "app serve"); got != c.want {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+func TestDotenv(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "serve", func(context.Context, dotenvConfig) error { return nil },
+					clibind.WithAutoEnv(), clibind.WithConfigFile("config"), clibind.WithDotenv(".env")),
+			},
+		}
+	}
+	for _, c := range []struct {
+		name   string
+		args   []string
+		env    map[string]string
+		dotenv string
+		want   dotenvConfig
+	}{
+		{"syntax", nil, nil, "# local\n\nexport HOST='example.com' \nTOKEN=\"t0\\tk\" # comment\n",
+			dotenvConfig{Host: "example.com", Port: 9000, Token: "t0\tk"}},
+		{"over the config file", nil, nil, "PORT=8080 # comment\nTOKEN=t0k\n",
+			dotenvConfig{Host: "config.example.com", Port: 8080, Token: "t0k"}},
+		{"under the environment", nil, map[string]string{"HOST": "env.example.com", "TOKEN": "env"}, "HOST=example.com\nTOKEN=t0k\n",
+			dotenvConfig{Host: "env.example.com", Port: 9000, Token: "env"}},
+		{"under the command line", []string{"--port", "1"}, nil, "PORT=8080\nTOKEN=t0k\n",
+			dotenvConfig{Host: "config.example.com", Port: 1, Token: "t0k"}},
+		{"missing file", []string{"--host", "h"}, map[string]string{"TOKEN": "env"}, "",
+			dotenvConfig{Host: "h", Port: 80, Token: "env"}},
+	} {
+		files := map[string]string{}
+		args := append([]string{"serve"}, c.args...)
+		if c.dotenv != "" {
+			files[".env"] = c.dotenv
+			files["config.yaml"] = "host: config.example.com\nport: 9000\n"
+			args = append(args, "--config", "config.yaml")
+		}
+		res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: args, Env: c.env, Files: files})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[dotenvConfig](t, res, "app serve"); got != c.want {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{
+		Args:  []string{"serve"},
+		Files: map[string]string{".env": "HOST=example.com\nTOKEN='t0k\n"},
+	})
+	if res.Err == nil || !strings.HasSuffix(res.Err.Error(), "dotenv .env: line 2: unterminated quote") {
+		t.Errorf("err = %v, want the syntax error", res.Err)
+	}
+	if len(res.Calls) != 0 {
+		t.Error("the handler ran with an invalid dotenv file")
+	}
+}
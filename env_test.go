@@ -0,0 +1,125 @@
+package clibind_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type envTagConfig struct {
+	Region string `cli:"region" cliEnv:"APP_REGION,AWS_REGION" cliDefault:"us-east-1"`
+	Zone   string `cli:"zone" cliDefault:"a"`
+}
+
+func TestEnvTag(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want envTagConfig
+	}{
+		{"first set wins", nil, map[string]string{"APP_REGION": "eu-west-1", "AWS_REGION": "ap-south-1"}, envTagConfig{"eu-west-1", "a"}},
+		{"fallback", nil, map[string]string{"AWS_REGION": "ap-south-1", "ZONE": "b"}, envTagConfig{"ap-south-1", "a"}},
+		{"flag wins", []string{"--region", "us-west-2"}, map[string]string{"APP_REGION": "eu-west-1"}, envTagConfig{"us-west-2", "a"}},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, envTagConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: c.args, Env: c.env})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[envTagConfig](t, res, "app"); got != c.want {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, envTagConfig) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+	if !strings.Contains(res.Stdout, "[$APP_REGION, $AWS_REGION]") {
+		t.Errorf("help misses the variables of --region:\n%s", res.Stdout)
+	}
+}
+
+type envPrefixDB struct {
+	Host string `cli:"host" cliDefault:"localhost"`
+}
+
+type envPrefixConfig struct {
+	DB     envPrefixDB `cliPrefix:"db-"`
+	Region string      `cli:"region" cliEnv:"REGION" cliDefault:"us-east-1"`
+}
+
+func TestEnvPrefix(t *testing.T) {
+	env := map[string]string{
+		"APP_DB_HOST":  "prefixed",
+		"DB_HOST":      "unprefixed",
+		"APP_X_DBHOST": "named",
+		"REGION":       "eu-west-1",
+		"APP_REGION":   "prefixed",
+	}
+	for _, c := range []struct {
+		name string
+		opts []clibind.Option
+		want envPrefixConfig
+	}{
+		{"auto", []clibind.Option{clibind.WithAutoEnv()}, envPrefixConfig{envPrefixDB{"unprefixed"}, "eu-west-1"}},
+		{"prefix", []clibind.Option{clibind.WithEnvPrefix("APP_")}, envPrefixConfig{envPrefixDB{"prefixed"}, "eu-west-1"}},
+		{"name func", []clibind.Option{
+			clibind.WithEnvPrefix("APP_"),
+			clibind.WithEnvNameFunc(func(flag string) string { return "X_" + strings.ToUpper(strings.ReplaceAll(flag, "-", "")) }),
+		}, envPrefixConfig{envPrefixDB{"named"}, "eu-west-1"}},
+		{"none", nil, envPrefixConfig{envPrefixDB{"localhost"}, "eu-west-1"}},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, envPrefixConfig) error { return nil }, c.opts...)
+		res := clibindtest.Run(t, root, clibindtest.Input{Env: env})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[envPrefixConfig](t, res, "app"); got != c.want {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
+
+type envOnlyConfig struct {
+	Host     string                 `cli:"host" cliDefault:"localhost"`
+	Password clibind.Secret[string] `cli:"-" cliEnv:"DB_PASSWORD"`
+	Pool     int                    `cli:"-" cliEnv:"DB_POOL" cliDefault:"4"`
+	Region   *string                `cli:"-" cliEnv:"REGION"`
+}
+
+func TestEnvOnly(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, envOnlyConfig) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Env: map[string]string{"DB_PASSWORD": "s3cr3t"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	got := clibindtest.Bound[envOnlyConfig](t, res, "app")
+	if got.Password.Value() != "s3cr3t" || got.Pool != 4 || got.Region != nil {
+		t.Errorf("bound password %q, pool %d, region %v; want s3cr3t, 4, nil", got.Password.Value(), got.Pool, got.Region)
+	}
+
+	root = clibind.CommandWithBinding(nil, "app", func(context.Context, envOnlyConfig) error { return nil })
+	res = clibindtest.Run(t, root, clibindtest.Input{Env: map[string]string{"DB_POOL": "8", "REGION": "eu"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "field Password: environment variable DB_PASSWORD not set") {
+		t.Errorf("err = %v, want DB_PASSWORD required", res.Err)
+	}
+
+	root = clibind.CommandWithBinding(nil, "app", func(context.Context, envOnlyConfig) error { return nil },
+		clibind.WithPrintConfig())
+	res = clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--print-config"}, Env: map[string]string{"DB_PASSWORD": "s3cr3t"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if strings.Contains(res.Stdout, "s3cr3t") || !strings.Contains(res.Stdout, "[redacted]") {
+		t.Errorf("--print-config shows the password or misses it:\n%s", res.Stdout)
+	}
+
+	root = clibind.CommandWithBinding(nil, "app", func(context.Context, envOnlyConfig) error { return nil })
+	res = clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+	if strings.Contains(res.Stdout, "password") || strings.Contains(res.Stdout, "DB_POOL") {
+		t.Errorf("help shows an env-only field:\n%s", res.Stdout)
+	}
+}
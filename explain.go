@@ -0,0 +1,145 @@
+package clibind
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+)
+
+const flagExplainConfig = "explain-config"
+
+// WithExplainConfig makes CommandWithBinding add an --explain-config flag that,
+// instead of running the handler, prints a table of every flag with its final
+// value (secrets masked), the source it came from (the command line, an
+// environment variable, the config file, a cliSources reference or the
+// default), and the sources it overrode.
+func WithExplainConfig() Option {
+	return func(o *options) {
+		o.explainConfig = true
+	}
+}
+
+func explainConfigFlag() cli.Flag {
+	return &cli.BoolFlag{Name: flagExplainConfig, Usage: "print where each configuration value comes from and exit"}
+}
+
+// fieldSource explains the value of one flag.
+type fieldSource struct {
+	flag, value, source string
+	overridden          []string
+}
+
+func writeExplanation(w io.Writer, c *cli.Command, cfg any, o *options, prov *Provenance) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "FLAG\tVALUE\tSOURCE\tOVERRIDDEN")
+	for _, f := range explain(c, cfg, o, prov) {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", f.flag, f.value, f.source, strings.Join(f.overridden, ", "))
+	}
+	return tw.Flush()
+}
+
+// explain reconstructs where the bound value of each flag came from. urfave does
+// not record whether a set flag was given on the command line or by one of its
+// sources, but it only consults the sources of flags the command line left
+// unset, and then takes the first one holding a value: so a set flag whose
+// first such source holds the bound value came from there.
+func explain(c *cli.Command, cfg any, o *options, prov *Provenance) []fieldSource {
+	var fields []fieldSource
+	walkLeaves(reflect.ValueOf(cfg).Elem(), "", func(name string, sf reflect.StructField, fv reflect.Value) bool {
+		f := fieldSource{flag: name, value: formatField(sf, fv, false)}
+		bound := formatField(sf, fv, true) // secrets are compared unmasked, and only printed masked
+		if isEnvOnly(sf) {
+			f.source = "default"
+			for _, env := range splitCSV(sf.Tag.Get(tagCLIEnv)) {
+				if _, src, ok := o.lookupEnv(env); ok {
+					f.source = src
+					break
+				}
+			}
+			if ref, ok := prov.Resolved[name]; ok {
+				f.source = ref // --set
+			}
+			fields = append(fields, f)
+			return true
+		}
+		var found []string
+		fromSource := false
+		for _, src := range flagSources(c, name) {
+			v, ok := src.Lookup()
+			if !ok {
+				continue
+			}
+			if len(found) == 0 && c.IsSet(name) {
+				fromSource = sourceValue(v, sf) == bound
+			}
+			found = append(found, src.String())
+		}
+		ref, resolved := prov.Resolved[name]
+		switch {
+		case resolved && strings.HasPrefix(ref, "--"+flagSet+" "):
+			f.source = ref
+			if c.IsSet(name) && !fromSource {
+				f.overridden = append([]string{"--" + name}, found...)
+			} else {
+				f.overridden = found
+			}
+		case c.IsSet(name) && !fromSource:
+			f.source = "--" + name
+			f.overridden = found
+		case c.IsSet(name):
+			f.source, f.overridden = found[0], found[1:]
+		case resolved:
+			f.source = ref
+		default:
+			f.source = "default"
+		}
+		if f.source != "default" && fieldDefault(sf) != "" {
+			f.overridden = append(slices.Clip(f.overridden), "default")
+		}
+		fields = append(fields, f)
+		return true
+	})
+	return fields
+}
+
+// flagSources returns the value sources of the flag named name.
+func flagSources(c *cli.Command, name string) []cli.ValueSource {
+	for _, cmd := range c.Lineage() {
+		for _, fl := range cmd.Flags {
+			if !slices.Contains(fl.Names(), name) {
+				continue
+			}
+			return flagSourceChain(fl)
+		}
+	}
+	return nil
+}
+
+// flagSourceChain returns the value sources of fl, whatever its type.
+func flagSourceChain(fl cli.Flag) []cli.ValueSource {
+	v := flagStruct(fl)
+	if v.Kind() != reflect.Struct {
+		return nil
+	}
+	if src := v.FieldByName("Sources"); src.IsValid() {
+		if chain, ok := src.Interface().(cli.ValueSourceChain); ok {
+			return chain.Chain
+		}
+	}
+	return nil
+}
+
+// sourceValue formats the raw value s of a source like formatField would once
+// bound, secrets unmasked, so the two can be compared.
+func sourceValue(s string, sf reflect.StructField) string {
+	v := reflect.New(sf.Type).Elem()
+	if err := setFieldFromString(s, sf, allocReferenced(v)); err != nil {
+		return s
+	}
+	return formatField(sf, v, true)
+}
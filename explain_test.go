@@ -0,0 +1,172 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+// columns separates the columns of a table printed by a tabwriter.
+var columns = regexp.MustCompile(`\s{2,}`)
+
+type explainConfig struct {
+	Password clibind.Secret[string] `cli:"pw"`
+}
+
+func TestExplainSecretSource(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{"flag", []string{"--pw", "from-flag"}, []string{"pw", "[redacted]", "--pw", `environment variable "PW"`}},
+		{"environment", nil, []string{"pw", "[redacted]", `environment variable "PW"`}},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, explainConfig) error { return nil },
+			clibind.WithAutoEnv(), clibind.WithExplainConfig())
+		res := clibindtest.Run(t, root, clibindtest.Input{
+			Args: append([]string{"--explain-config"}, c.args...),
+			Env:  map[string]string{"PW": "from-env"},
+		})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		var row []string
+		for _, l := range strings.Split(res.Stdout, "\n") {
+			if strings.HasPrefix(l, "pw ") {
+				row = columns.Split(strings.TrimSpace(l), -1)
+			}
+		}
+		if !slices.Equal(row, c.want) {
+			t.Errorf("%s: explained %q, want %q", c.name, row, c.want)
+		}
+		if strings.Contains(res.Stdout, "from-") {
+			t.Errorf("%s: explanation shows the secret:\n%s", c.name, res.Stdout)
+		}
+	}
+}
+
+type explainSourcesConfig struct {
+	Host   string `cli:"host" cliDefault:"localhost"`
+	Port   int    `cli:"port" cliDefault:"8080"`
+	Region string `cli:"region" cliDefault:"us-east-1"`
+	Level  string `cli:"level" cliChoices:"debug,info"`
+	Token  string `cli:"token,omitempty" cliSources:"explaindown://token,explainup://token"`
+}
+
+func TestExplainConfig(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, explainSourcesConfig) error { return nil },
+		clibind.WithAutoEnv(), clibind.WithExplainConfig(), clibind.WithSetFlag(),
+		clibind.WithSecretResolver("explaindown", func(context.Context, string) (string, error) { return "", errors.New("store down") }),
+		clibind.WithSecretResolver("explainup", func(context.Context, string) (string, error) { return "t0k", nil }))
+	res := clibindtest.Run(t, root, clibindtest.Input{
+		Args: []string{"--explain-config", "--host", "h", "--set", "region=eu-west-1", "--level", "info"},
+		Env:  map[string]string{"HOST": "env-host", "PORT": "9000"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if len(res.Calls) != 0 {
+		t.Error("the handler ran along with --explain-config")
+	}
+	rows := map[string][]string{}
+	for _, l := range strings.Split(strings.TrimSpace(res.Stdout), "\n")[1:] {
+		row := columns.Split(strings.TrimSpace(l), -1)
+		rows[row[0]] = row[1:]
+	}
+	for flag, want := range map[string][]string{
+		"host":   {"h", "--host", `environment variable "HOST", default`},
+		"port":   {"9000", `environment variable "PORT"`, "default"},
+		"region": {"eu-west-1", "--set region", "default"},
+		"level":  {"info", "--level"},
+		"token":  {"t0k", "explainup://token"},
+	} {
+		if !slices.Equal(rows[flag], want) {
+			t.Errorf("explained %s as %q, want %q", flag, rows[flag], want)
+		}
+	}
+}
+
+func TestExplainCommand(t *testing.T) {
+	newRoot := func() *cli.Command {
+		opts := []clibind.Option{
+			clibind.WithAutoEnv(),
+			clibind.WithSecretResolver("explaindown", func(context.Context, string) (string, error) { return "", errors.New("store down") }),
+			clibind.WithSecretResolver("explainup", func(context.Context, string) (string, error) { return "t0k", nil }),
+		}
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "serve", func(context.Context, explainSourcesConfig) error { return nil }, opts...),
+				clibind.ExplainCommand[explainSourcesConfig](opts...),
+			},
+		}
+	}
+	for _, c := range []struct {
+		name string
+		args []string
+		env  map[string]string
+		want [][]string
+	}{
+		{"default", []string{"--flag", "port"}, nil, [][]string{
+			{"field:", "clibind_test.explainSourcesConfig.Port"},
+			{"type:", "int"},
+			{"default:", "8080"},
+			{"env:", "PORT"},
+			{"value:", "8080"},
+			{"from:", "default"},
+		}},
+		{"environment", []string{"--flag", "--port"}, map[string]string{"PORT": "9000"}, [][]string{
+			{"value:", "9000"},
+			{"from:", `environment variable "PORT"`},
+			{"overrides:", "default"},
+		}},
+		{"rules", []string{"--flag", "level", "--level", "debug"}, nil, [][]string{
+			{"rules:", "required"},
+			{"rules:", "one of debug, info"},
+			{"value:", "debug"},
+			{"from:", "--level"},
+		}},
+		{"failed source", []string{"--flag", "token"}, nil, [][]string{
+			{"sources:", "explaindown://token (when unset)"},
+			{"value:", "t0k"},
+			{"from:", "explainup://token"},
+			{"failed:", "explaindown://token: store down"},
+		}},
+		{"invalid value", []string{"--flag", "level", "--level", "trace"}, nil, [][]string{
+			{"value:", `(not bound: `},
+		}},
+	} {
+		res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: append([]string{"explain"}, c.args...), Env: c.env})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		var rows [][]string
+		for _, l := range strings.Split(strings.TrimSpace(res.Stdout), "\n") {
+			row := columns.Split(strings.TrimSpace(l), 2)
+			if strings.HasPrefix(l, " ") { // the next value of a list
+				row = []string{rows[len(rows)-1][0], row[0]}
+			}
+			rows = append(rows, row)
+		}
+		for _, want := range c.want {
+			if !slices.ContainsFunc(rows, func(row []string) bool {
+				return len(row) == 2 && row[0] == want[0] && strings.HasPrefix(row[1], want[1])
+			}) {
+				t.Errorf("%s: explanation misses %q:\n%s", c.name, want, res.Stdout)
+			}
+		}
+	}
+
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"explain", "--flag", "nope"}})
+	if res.Err == nil || res.Err.Error() != "unknown flag --nope" {
+		t.Errorf("err = %v, want unknown flag --nope", res.Err)
+	}
+}
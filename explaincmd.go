@@ -0,0 +1,214 @@
+package clibind
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+)
+
+const flagExplainFlag = "flag"
+
+// ExplainCommand returns an "explain" subcommand describing a single flag of a
+// command binding T. Give it the options of the real command, like
+// DoctorCommand:
+//
+//	root.Commands = append(root.Commands,
+//	    clibind.CommandWithBinding(nil, "serve", serve, opts...),
+//	    clibind.ExplainCommand[ServeConfig](opts...),
+//	)
+//
+// Running "mytool explain --flag db-host" prints the Go field path and type of
+// the flag, its default, environment variables, sources and validation rules,
+// and the value it currently resolves to (secrets masked) with where that value
+// came from. The command takes the flags of T, so the value reflects the ones
+// given along with --flag.
+func ExplainCommand[T any](opts ...Option) *cli.Command {
+	o := newOptions(opts)
+	flags, checks := lenientFlags[T](o, opts)
+	for _, fl := range flags {
+		if slices.Contains(fl.Names(), flagExplainFlag) {
+			panic(fmt.Sprintf("clibind: ExplainCommand: %s has a flag --%s of its own", reflect.TypeFor[T](), flagExplainFlag))
+		}
+	}
+	flags = append(flags, &cli.StringFlag{
+		Name:     flagExplainFlag,
+		Usage:    "the `NAME` of the flag to explain",
+		Required: true,
+	})
+	cmd := &cli.Command{
+		Name:      "explain",
+		Usage:     "explain where the value of a flag comes from and what it accepts",
+		UsageText: "explain --flag NAME [flags]",
+		Flags:     flags,
+		Action: func(ctx context.Context, c *cli.Command) error {
+			var t T
+			return runExplain(ctx, c, &t, o, checks, flags)
+		},
+	}
+	return cmd
+}
+
+func runExplain(ctx context.Context, c *cli.Command, cfg any, o *options, checks []flagCheck, flags []cli.Flag) error {
+	name := strings.TrimLeft(c.String(flagExplainFlag), "-")
+	i := slices.IndexFunc(flags, func(fl cli.Flag) bool { return slices.Contains(fl.Names(), name) })
+	if i < 0 {
+		return fmt.Errorf("unknown flag --%s", name)
+	}
+	fl, fc := flags[i], checks[i]
+	name = fl.Names()[0]
+
+	t := reflect.TypeOf(cfg).Elem()
+	var sf reflect.StructField
+	found := false
+	walkLeafFields(t, "", func(n string, f reflect.StructField) {
+		if n == name {
+			sf, found = f, true
+		}
+	})
+	if !found {
+		return fmt.Errorf("flag --%s is not bound to a field of %s", name, t)
+	}
+	paths := map[string]string{}
+	fieldPaths(t, "", "", paths)
+
+	row := flagTableRow(fl)
+	tw := tabwriter.NewWriter(c.Root().Writer, 0, 4, 2, ' ', 0)
+	list := func(key string, vals []string) {
+		if len(vals) == 0 {
+			vals = []string{"(none)"}
+		}
+		for i, v := range vals {
+			if i > 0 {
+				key = ""
+			}
+			fmt.Fprintf(tw, "%s\t%s\n", key, v)
+		}
+	}
+	fmt.Fprintf(tw, "flag:\t%s\n", row[0])
+	fmt.Fprintf(tw, "field:\t%s.%s\n", t, paths[name])
+	fmt.Fprintf(tw, "type:\t%s\n", sf.Type)
+	if usage := row[4].(string); usage != "" {
+		fmt.Fprintf(tw, "usage:\t%s\n", usage)
+	}
+	def := row[1].(string)
+	if def == "" || fc.required {
+		def = "(none)"
+	}
+	fmt.Fprintf(tw, "default:\t%s\n", def)
+	var env []string
+	if df, ok := fl.(cli.DocGenerationFlag); ok {
+		env = df.GetEnvVars()
+	}
+	list("env:", env)
+	var sources []string
+	for _, src := range flagSourceChain(fl) {
+		sources = append(sources, src.String())
+	}
+	for _, ref := range splitCSV(sf.Tag.Get(tagCLISources)) {
+		sources = append(sources, ref+" (when unset)")
+	}
+	list("sources:", sources)
+	list("rules:", fieldRules(name, sf, fc, flags))
+
+	if err := bind(c, cfg, o); err != nil {
+		fmt.Fprintf(tw, "value:\t(not bound: %v)\n", err)
+		return tw.Flush()
+	}
+	prov := &Provenance{}
+	if err := resolveBound(ctx, c, cfg, o, prov); err != nil {
+		fmt.Fprintf(tw, "value:\t(not resolved: %v)\n", err)
+		return tw.Flush()
+	}
+	for _, f := range explain(c, cfg, o, prov) {
+		if f.flag != name {
+			continue
+		}
+		if fc.required && !c.IsSet(name) {
+			fmt.Fprintf(tw, "value:\t(not set)\n")
+			break
+		}
+		fmt.Fprintf(tw, "value:\t%s\n", f.value)
+		fmt.Fprintf(tw, "from:\t%s\n", f.source)
+		if len(f.overridden) > 0 {
+			fmt.Fprintf(tw, "overrides:\t%s\n", strings.Join(f.overridden, ", "))
+		}
+	}
+	for _, f := range prov.Failures {
+		if f.Flag == name {
+			fmt.Fprintf(tw, "failed:\t%s: %v\n", f.Source, f.Err)
+		}
+	}
+	return tw.Flush()
+}
+
+// fieldRules describes the checks values of the field sf, bound to the flag
+// name among flags, must pass.
+func fieldRules(name string, sf reflect.StructField, fc flagCheck, flags []cli.Flag) []string {
+	var rules []string
+	if fc.required {
+		rules = append(rules, "required")
+	}
+	if kv := compoundOf(flags, name); kv != nil {
+		rules = append(rules, "required, alone or in --"+kv.Name)
+	}
+	if schemes := sf.Tag.Get(tagCLIScheme); schemes != "" {
+		rules = append(rules, "URL scheme one of "+strings.Join(splitCSV(schemes), ", "))
+	}
+	if p := sf.Tag.Get(tagCLIParser); p != "" {
+		rules = append(rules, "parsed by "+p)
+	}
+	if choices := sf.Tag.Get(tagCLIChoices); choices != "" {
+		rules = append(rules, "one of "+strings.Join(splitCSV(choices), ", "))
+	}
+	ft := unreferenceType(sf.Type)
+	if ft.Kind() == reflect.Slice {
+		ft = ft.Elem()
+	}
+	if k := ft.Kind(); (k == reflect.Float32 || k == reflect.Float64) && sf.Tag.Get(tagCLIFloats) != "true" {
+		rules = append(rules, "finite (no NaN or ±Inf)")
+	}
+	if p := sf.Tag.Get(tagCLIPrecision); p != "" {
+		rules = append(rules, "rounded to "+p+" decimal places")
+	}
+	switch base := sf.Tag.Get(tagCLIBase); base {
+	case "":
+	case "0":
+		rules = append(rules, "integer, base taken from a 0x, 0o or 0b prefix")
+	default:
+		rules = append(rules, "integer in base "+base)
+	}
+	if unit := sf.Tag.Get(tagCLIUnit); unit != "" {
+		rules = append(rules, "bare integers in "+unit)
+	}
+	if layouts := sf.Tag.Get(tagCLITimeFmt); layouts != "" {
+		rules = append(rules, "time in one of the layouts "+strings.Join(strings.Split(layouts, "|"), ", "))
+	}
+	switch sf.Tag.Get(tagCLICheck) {
+	case "file":
+		rules = append(rules, "an existing file (checked by doctor)")
+	case "dir":
+		rules = append(rules, "an existing directory (checked by doctor)")
+	case "tcp":
+		rules = append(rules, "a reachable host:port (checked by doctor)")
+	case "http":
+		rules = append(rules, "a reachable URL (checked by doctor)")
+	}
+	if since := sf.Tag.Get(tagCLISince); since != "" {
+		rules = append(rules, "available since version "+since)
+	}
+	if until := sf.Tag.Get(tagCLIUntil); until != "" {
+		rules = append(rules, "removed in version "+until)
+	}
+	if d, ok := fieldDeprecation(name, sf); ok {
+		rules = append(rules, d.String())
+	}
+	if isSecret(sf.Type) || isSecretTagged(sf) {
+		rules = append(rules, "secret, masked in help and dumps")
+	}
+	return rules
+}
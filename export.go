@@ -0,0 +1,63 @@
+package clibind
+
+import (
+	"encoding/csv"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// WriteFlagsCSV writes a table of the visible flags Flags[T](opts...) generates,
+// for spreadsheets and compliance documentation: one row per flag with its name,
+// aliases, type, default, environment variables, whether it is required, its
+// category, its usage, and whether it is deprecated (see the cliDeprecated tag)
+// with the release removing it. comma separates the columns, ',' for CSV or '\t'
+// for TSV; fields are quoted as needed either way.
+func WriteFlagsCSV[T any](w io.Writer, comma rune, opts ...Option) error {
+	o := newOptions(opts)
+	flags, err := genFlags(reflect.TypeFor[T](), o)
+	if err != nil {
+		return err
+	}
+	sortFlags(flags, o)
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write([]string{"flag", "aliases", "type", "default", "env", "required", "category", "usage", "deprecated", "remove_in"}); err != nil {
+		return err
+	}
+	for _, fl := range flags {
+		if vf, ok := fl.(cli.VisibleFlag); ok && !vf.IsVisible() {
+			continue
+		}
+		names := fl.Names()
+		row := []string{names[0], strings.Join(names[1:], " "), "", "", "", "false", "", "", "false", ""}
+		if df, ok := fl.(cli.DocGenerationFlag); ok {
+			row[2] = df.TypeName()
+			if df.IsDefaultVisible() {
+				row[3] = df.GetDefaultText()
+				if row[3] == "" && df.TakesValue() {
+					row[3] = df.GetValue()
+				}
+			}
+			row[4] = strings.Join(df.GetEnvVars(), " ")
+			row[7] = strings.ReplaceAll(df.GetUsage(), "`", "") // drop the quotes around placeholder names
+		}
+		if rf, ok := fl.(cli.RequiredFlag); ok {
+			row[5] = strconv.FormatBool(rf.IsRequired())
+		}
+		if cf, ok := fl.(cli.CategorizableFlag); ok {
+			row[6] = cf.GetCategory()
+		}
+		if d, ok := o.deprecations[names[0]]; ok {
+			row[8], row[9] = "true", d.RemoveIn
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
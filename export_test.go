@@ -0,0 +1,67 @@
+package clibind_test
+
+import (
+	"encoding/csv"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/urfave/cli/v3"
+)
+
+type exportConfig struct {
+	Listen string `cli:"listen,omitempty"`
+	Addr   string `cli:"addr" cliDeprecated:"use --listen" cliRemoveIn:"v3.0"`
+	Debug  bool   `cli:"debug" cliDeprecated:"true"`
+	Port   int    `cli:"port" cliDefault:"8080"`
+}
+
+func TestWriteFlagsCSV(t *testing.T) {
+	var b strings.Builder
+	if err := clibind.WriteFlagsCSV[exportConfig](&b, ','); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := csv.NewReader(strings.NewReader(b.String())).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// flag, required, deprecated, remove_in
+	want := map[string][3]string{
+		"listen": {"false", "false", ""},
+		"addr":   {"true", "true", "v3.0"},
+		"debug":  {"true", "true", ""},
+		"port":   {"false", "false", ""},
+	}
+	for _, row := range rows[1:] {
+		if got := [3]string{row[5], row[8], row[9]}; got != want[row[0]] {
+			t.Errorf("--%s: required, deprecated, remove_in = %q, want %q", row[0], got, want[row[0]])
+		}
+	}
+	if len(rows) != len(want)+1 {
+		t.Errorf("%d rows, want a header and %d flags", len(rows), len(want))
+	}
+	for _, fl := range clibind.Flags[exportConfig]() {
+		if fl.Names()[0] == "addr" && len(fl.(*cli.StringFlag).Sources.Chain) != 0 {
+			t.Errorf("--addr has the sources %v, want none", fl.(*cli.StringFlag).Sources.Chain)
+		}
+	}
+}
+
+type exportColumnsConfig struct {
+	Region string `cli:"region,r" cliEnv:"APP_REGION,AWS_REGION" cliDefault:"eu" cliCategory:"Cloud" cliUsage:"the region to run in"`
+	Secret string `cli:"secret" cliSecret:"true" cliDefault:"s3cr3t"`
+	Legacy string `cli:"legacy,omitempty" cliUntil:"2.0"`
+}
+
+func TestWriteFlagsTSV(t *testing.T) {
+	var b strings.Builder
+	if err := clibind.WriteFlagsCSV[exportColumnsConfig](&b, '\t', clibind.WithAutoEnv(), clibind.WithVersion("2.0")); err != nil {
+		t.Fatal(err)
+	}
+	const want = "flag\taliases\ttype\tdefault\tenv\trequired\tcategory\tusage\tdeprecated\tremove_in\n" +
+		"region\tr\tstring\teu\tAPP_REGION AWS_REGION\tfalse\tCloud\tthe region to run in\tfalse\t\n" +
+		"secret\t\tstring\t[redacted]\tSECRET\tfalse\t\t\tfalse\t\n"
+	if b.String() != want {
+		t.Errorf("TSV:\n%s\nwant:\n%s", b.String(), want)
+	}
+}
@@ -0,0 +1,147 @@
+package clibind
+
+import (
+	"fmt"
+	"maps"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v3"
+)
+
+// factory is a named implementation of an interface, see RegisterFactory.
+type factory struct {
+	name   string
+	config reflect.Type // struct type of the implementation's own flags
+	build  func(cfg reflect.Value) (reflect.Value, error)
+}
+
+var (
+	factoriesMu sync.RWMutex
+	factories   = map[reflect.Type][]factory{}
+)
+
+// RegisterFactory makes interface-typed fields of type I bindable: the field's
+// flag takes the name of an implementation, and fn builds it from its own config
+// struct C, whose flags are generated prefixed with the field's flag and name:
+//
+//	clibind.RegisterFactory("s3", func(c S3Config) (Storage, error) { return newS3(c) })
+//	clibind.RegisterFactory("disk", func(c DiskConfig) (Storage, error) { return newDisk(c) })
+//
+//	type Config struct {
+//	    Storage Storage `cli:"storage" cliDefault:"disk"`
+//	}
+//
+//	// app --storage s3 --storage-s3-bucket b --storage-s3-region eu-west-1
+//
+// The flags of every implementation are generated, so none of them is required
+// by urfave; only the chosen implementation's are bound, and those it requires
+// checked then. An empty name leaves the field nil.
+// Registering a name again replaces the factory; it fails after Freeze. It
+// panics if I is not an interface or C not a struct.
+func RegisterFactory[I, C any](name string, fn func(cfg C) (I, error)) error {
+	it, ct := reflect.TypeFor[I](), reflect.TypeFor[C]()
+	if it.Kind() != reflect.Interface {
+		panic(fmt.Sprintf("clibind: RegisterFactory: %s is not an interface", it))
+	}
+	if ct.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("clibind: RegisterFactory: %s is not a struct", ct))
+	}
+	f := factory{name: name, config: ct, build: func(cfg reflect.Value) (reflect.Value, error) {
+		impl, err := fn(cfg.Interface().(C))
+		return reflect.ValueOf(&impl).Elem(), err
+	}}
+	factoriesMu.Lock()
+	defer factoriesMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterFactory[%s](%q)", it, name)); err != nil {
+		return err
+	}
+	for i, g := range factories[it] {
+		if g.name == name {
+			factories[it][i] = f
+			return nil
+		}
+	}
+	factories[it] = append(factories[it], f)
+	return nil
+}
+
+// factoriesOf returns the factories registered for the interface type t.
+func factoriesOf(t reflect.Type) []factory {
+	if t.Kind() != reflect.Interface {
+		return nil
+	}
+	factoriesMu.RLock()
+	defer factoriesMu.RUnlock()
+	return factories[t]
+}
+
+func factoryNames(fs []factory) []string {
+	names := make([]string, len(fs))
+	for i, f := range fs {
+		names[i] = f.name
+	}
+	return names
+}
+
+// factoryPrefix is the prefix of the flags of implementation f of the field
+// whose flag is named name.
+func factoryPrefix(name string, f factory) string {
+	return name + "-" + f.name + "-"
+}
+
+// bindFactory builds the implementation the flag named name selects into fv,
+// reporting whether it set one.
+func bindFactory(ctx *cli.Command, name string, fs []factory, fv reflect.Value, o *options) (bool, error) {
+	impl := ctx.String(name)
+	if impl == "" {
+		return false, nil
+	}
+	for _, f := range fs {
+		if f.name != impl {
+			continue
+		}
+		if err := checkFactoryRequired(ctx, name, f, o); err != nil {
+			return false, err
+		}
+		cfg := reflect.New(f.config).Elem()
+		sub, err := bindStruct(ctx, f.config, factoryPrefix(name, f), nil, o)
+		if err != nil {
+			return false, err
+		}
+		if sub != nil {
+			cfg = *sub
+		}
+		if err := runPostBind(cfg); err != nil {
+			return false, err
+		}
+		v, err := f.build(cfg)
+		if err != nil {
+			return false, fmt.Errorf("build %s %s: %w", fv.Type(), impl, err)
+		}
+		fv.Set(v)
+		return true, nil
+	}
+	return false, fmt.Errorf("%q is not one of %s", impl, strings.Join(factoryNames(fs), ", "))
+}
+
+// checkFactoryRequired reports the flags of implementation f, selected by the
+// flag named name, that would be required in a command of their own but are
+// not set.
+func checkFactoryRequired(ctx *cli.Command, name string, f factory, o *options) error {
+	required := map[string]bool{}
+	o.collectSourcedRequired(f.config, factoryPrefix(name, f), required)
+	var missing []string
+	for _, n := range slices.Sorted(maps.Keys(required)) {
+		if !ctx.IsSet(n) {
+			missing = append(missing, strconv.Quote(n))
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%s requires the flags %s", f.name, strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,71 @@
+package clibind_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type storage interface{ Location() string }
+
+type s3Storage struct {
+	Bucket string `cli:"bucket"`
+	Region string `cli:"region" cliDefault:"eu-west-1"`
+}
+
+func (s s3Storage) Location() string { return "s3://" + s.Bucket + "@" + s.Region }
+
+type diskStorage struct {
+	Dir string `cli:"dir" cliDefault:"/var/lib/app"`
+}
+
+func (s diskStorage) Location() string { return "file://" + s.Dir }
+
+type storageConfig struct {
+	Storage storage `cli:"storage" cliDefault:"disk"`
+}
+
+func init() {
+	must(clibind.RegisterFactory("s3", func(c s3Storage) (storage, error) { return c, nil }))
+	must(clibind.RegisterFactory("disk", func(c diskStorage) (storage, error) { return c, nil }))
+}
+
+func must(err error) {
+	if err != nil {
+		panic(err)
+	}
+}
+
+func TestFactoryStrictFlags(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, storageConfig) error { return nil },
+		clibind.WithStrictBind(clibind.StrictFields|clibind.StrictFlags))
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--storage", "s3", "--storage-s3-bucket", "b"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[storageConfig](t, res, "app").Storage.Location(); got != "s3://b@eu-west-1" {
+		t.Errorf("Storage = %s, want s3://b@eu-west-1", got)
+	}
+}
+
+func TestFactoryRequiredFlags(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return clibind.CommandWithBinding(nil, "app", func(context.Context, storageConfig) error { return nil })
+	}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"--storage", "s3"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), `s3 requires the flags "storage-s3-bucket"`) {
+		t.Errorf("err = %v, want the bucket of s3 missing", res.Err)
+	}
+	// the other implementations' required flags do not matter
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[storageConfig](t, res, "app").Storage.Location(); got != "file:///var/lib/app" {
+		t.Errorf("Storage = %s, want the disk default", got)
+	}
+}
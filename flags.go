@@ -1,7 +1,10 @@
 package clibind
 
 import (
+	"fmt"
 	"reflect"
+	"slices"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -12,39 +15,209 @@ import (
 
 // FlagsFromStruct inspects exported fields with `cli` and other tags and generates cli.Flag definitions.
 // It is safe to pass either a struct or a pointer to a struct. Unexported fields are ignored.
-func FlagsFromStruct(v any) []cli.Flag {
-	rt := unreferenceType(reflect.TypeOf(v))
+// Flags are returned in field declaration order unless WithFlagOrder says otherwise.
+//
+// Tag misuse (e.g. a cli tag on an embedded struct) is a programming error and makes FlagsFromStruct panic.
+func FlagsFromStruct(v any, opts ...Option) []cli.Flag {
+	o := newOptions(opts)
+	flags, err := genFlags(reflect.TypeOf(v), o)
+	if err != nil {
+		panic(fmt.Sprintf("clibind: FlagsFromStruct: %v", err))
+	}
+	sortFlags(flags, o)
+	return flags
+}
+
+// Flags is the type-parameterized FlagsFromStruct: clibind.Flags[Config]() reads
+// better than passing Config{} and cannot be handed a populated instance whose
+// values would be ignored anyway.
+func Flags[T any](opts ...Option) []cli.Flag {
+	o := newOptions(opts)
+	flags, err := genFlags(reflect.TypeFor[T](), o)
+	if err != nil {
+		panic(fmt.Sprintf("clibind: Flags: %v", err))
+	}
+	sortFlags(flags, o)
+	return flags
+}
+
+// FlagsFromStructs generates flags for several independent structs (e.g. HTTPOpts,
+// LogOpts, TraceOpts) and merges them into one flag set. Option values may be mixed
+// in with the structs and apply to all of them:
+//
+//	flags := clibind.FlagsFromStructs(HTTPOpts{}, LogOpts{}, clibind.WithAutoEnv())
+//
+// Flags sharing a name or alias are resolved by the MergePolicy set with
+// WithMergePolicy; by default they make FlagsFromStructs panic.
+func FlagsFromStructs(vs ...any) []cli.Flag {
+	var (
+		opts    []Option
+		structs []any
+	)
+	for _, v := range vs {
+		if opt, ok := v.(Option); ok {
+			opts = append(opts, opt)
+			continue
+		}
+		structs = append(structs, v)
+	}
+
+	o := newOptions(opts)
+	sets := make([][]cli.Flag, 0, len(structs))
+	for _, v := range structs {
+		flags, err := genFlags(reflect.TypeOf(v), o)
+		if err != nil {
+			panic(fmt.Sprintf("clibind: FlagsFromStructs: %v", err))
+		}
+		sets = append(sets, flags)
+	}
+	flags, err := mergeFlags(sets, o.mergePolicy)
+	if err != nil {
+		panic(fmt.Sprintf("clibind: FlagsFromStructs: %v", err))
+	}
+	sortFlags(flags, o)
+	return flags
+}
+
+// genFlags generates the flags of struct (or pointer to struct) type rt in declaration order.
+func genFlags(rt reflect.Type, o *options) ([]cli.Flag, error) {
+	if rt == nil {
+		return nil, nil
+	}
+	rt = unreferenceType(rt)
 	if rt.Kind() != reflect.Struct {
-		return nil
+		return nil, nil
 	}
+	start := time.Now()
 	var flags []cli.Flag
-	genFlagsForStruct(rt, "", &flags) // empty prefix at root
-	return flags
+	if err := genFlagsForStruct(rt, "", "", o, &flags); err != nil { // empty prefix at root
+		return nil, fmt.Errorf("%s: %w", rt, err)
+	}
+	if fn := o.instrumentation.OnFlagsGenerated; fn != nil {
+		fn(FlagsGenerated{Type: rt, Fields: countFields(rt), Flags: len(flags), Duration: time.Since(start)})
+	}
+	return flags, nil
+}
+
+// mergeFlags concatenates flag sets, resolving flags that share a name with policy.
+func mergeFlags(sets [][]cli.Flag, policy MergePolicy) ([]cli.Flag, error) {
+	var out []cli.Flag
+	byName := map[string]cli.Flag{}
+	for _, set := range sets {
+	next:
+		for _, f := range set {
+			for _, name := range f.Names() {
+				prev, ok := byName[name]
+				if !ok {
+					continue
+				}
+				switch {
+				case policy == MergeFirstWins:
+					continue next
+				case policy == MergeRequireIdentical && sameFlag(prev, f):
+					continue next
+				case policy == MergeRequireIdentical:
+					return nil, fmt.Errorf("flag %q has conflicting definitions", name)
+				default:
+					return nil, fmt.Errorf("flag %q is defined more than once", name)
+				}
+			}
+			for _, name := range f.Names() {
+				byName[name] = f
+			}
+			out = append(out, f)
+		}
+	}
+	return out, nil
+}
+
+func sameFlag(a, b cli.Flag) bool {
+	if reflect.TypeOf(a) != reflect.TypeOf(b) {
+		return false
+	}
+	return reflect.DeepEqual(withoutFuncs(a), withoutFuncs(b))
+}
+
+// withoutFuncs returns a copy of the flag struct behind f with its func fields
+// (validators, actions) cleared: they are closures and never DeepEqual.
+func withoutFuncs(f cli.Flag) any {
+	v := flagStruct(f)
+	if v.Kind() != reflect.Struct {
+		return f
+	}
+	c := reflect.New(v.Type()).Elem()
+	c.Set(v)
+	for i := range c.NumField() {
+		if fv := c.Field(i); fv.Kind() == reflect.Func && fv.CanSet() {
+			fv.SetZero()
+		}
+	}
+	return c.Interface()
+}
+
+// sortFlags reorders flags according to the configured FlagOrder. Sorting is stable,
+// so the output only depends on the struct definition.
+func sortFlags(flags []cli.Flag, o *options) {
+	switch o.order {
+	case OrderAlphabetical:
+		sort.SliceStable(flags, func(i, j int) bool {
+			return flags[i].Names()[0] < flags[j].Names()[0]
+		})
+	case OrderCategory:
+		sortCategories(flags, o.categoryOrder, flagCategory)
+	}
+}
+
+func flagCategory(f cli.Flag) string {
+	if cf, ok := f.(cli.CategorizableFlag); ok {
+		return cf.GetCategory()
+	}
+	return ""
 }
 
-func genFlagsForStruct(rt reflect.Type, inheritedPrefix string, out *[]cli.Flag) {
+func genFlagsForStruct(rt reflect.Type, inheritedPrefix, inheritedCategory string, o *options, out *[]cli.Flag) error {
 	for i := 0; i < rt.NumField(); i++ {
 		sf := rt.Field(i)
 		if sf.PkgPath != "" { // unexported
 			continue
 		}
+		if skip, _ := strconv.ParseBool(sf.Tag.Get(tagCLISkipFlag)); skip || isEnvOnly(sf) {
+			continue
+		}
 
-		// If this is a (sub)struct with cliPrefix, recurse
-		if isStructLike(sf.Type) && sf.Tag.Get(tagCLIPrefix) != "" {
-			pfx := inheritedPrefix + sf.Tag.Get(tagCLIPrefix)
-			genFlagsForStruct(unreferenceType(sf.Type), pfx, out)
+		// (sub)structs are recursed into, see nestedPrefix for the naming rules
+		if isStructLike(sf.Type) {
+			if err := checkNested(sf); err != nil {
+				return err
+			}
+			sub := *o
+			if isKV(sf) {
+				name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+				if name == "" {
+					name = strings.ToLower(sf.Name)
+				}
+				name = inheritedPrefix + name
+				sub.compound = genKVFlag(sf, name, o.nestedCategory(sf, inheritedCategory), o)
+				*out = append(*out, sub.compound)
+			}
+			if err := genFlagsForStruct(unreferenceType(sf.Type), nestedPrefix(sf, inheritedPrefix), o.nestedCategory(sf, inheritedCategory), &sub, out); err != nil {
+				return fmt.Errorf("substruct %s: %w", sf.Name, err)
+			}
 			continue
 		}
 
+		// a nullable value or an Optional gets the flag of a pointer to its value
+		if nsf, ok := nullableField(sf); ok {
+			if isStructLike(nsf.Type) {
+				return fmt.Errorf("field %s: %s holds a struct, whose fields are flags of their own", sf.Name, sf.Type)
+			}
+			sf = nsf
+		}
+
 		// Regular field with cli tag
 		name, aliases, omitEmpty := parseNamesWithOptions(sf.Tag.Get(tagCLI))
 		if name == "" {
 			name = strings.ToLower(sf.Name)
-			// still allow anonymous embedded structs (without cliPrefix) to be flattened
-			if sf.Anonymous && isStructLike(sf.Type) {
-				genFlagsForStruct(unreferenceType(sf.Type), inheritedPrefix, out)
-				continue
-			}
 		}
 
 		// apply inherited prefix to the primary name and aliases
@@ -56,61 +229,241 @@ func genFlagsForStruct(rt reflect.Type, inheritedPrefix string, out *[]cli.Flag)
 		}
 
 		usage := sf.Tag.Get(tagCLIUsage)
-		def := sf.Tag.Get(tagCLIDefault)
-		// before your switch:
-		ft := sf.Type
-		kind := unreferenceType(ft).Kind()
+		if usage == "" {
+			usage = fieldDoc(rt, sf.Name)
+		}
+		category := inheritedCategory
+		if c := sf.Tag.Get(tagCLICategory); c != "" {
+			category = c
+		}
+		if err := checkDefaultVar(sf); err != nil {
+			return err
+		}
+		if err := checkParser(sf); err != nil {
+			return err
+		}
+		if fs := factoriesOf(sf.Type); fs != nil {
+			if err := genFactoryFlags(sf, name, aliases, usage, category, fs, o, out); err != nil {
+				return fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+			continue
+		}
+		def := fieldDefault(sf)
+		value := def // static default, empty when def references other flags and is resolved by Bind
+		if hasFlagRefs(def) {
+			value = ""
+		}
+		sources := o.sources(name, sf)
+		ft := unreferenceType(sf.Type)
+		kind := ft.Kind()
+		_, registered := registeredType(ft)
+		registered = registered || hasParser(sf, ft)
+		if registered || isFlagValue(ft) {
+			kind = reflect.Invalid // whatever its kind, the type parses its own values
+		}
+		shownDef := def
+		if isSecretTagged(sf) && value != "" {
+			shownDef = redacted
+		}
+		usage = expandUsage(usage, shownDef, sources.EnvKeys(), splitCSV(sf.Tag.Get(tagCLIChoices)))
+		dep, deprecated := fieldDeprecation(name, sf)
+		if deprecated {
+			usage = strings.TrimSpace(usage + " " + dep.usageNote())
+		}
 
-		required := !omitEmpty && def == ""
+		gate, err := versionGate(name, sf, o.version)
+		if err != nil {
+			return fmt.Errorf("field %s: %w", sf.Name, err)
+		}
+		required := o.flagRequired(sf, omitEmpty, gate)
+		if required && o.compound != nil {
+			o.compound.Config.required = append(o.compound.Config.required, name)
+			required = false
+		}
+		if required && len(o.allValueSources()) > 0 {
+			required = false // see sourcedRequired
+		}
 
+		// slice and map defaults (e.g. UUID lists) are parsed up front, so help never shows a
+		// default that cannot bind
+		if kind == reflect.Slice && value != "" {
+			if err := setSliceFromStrings(splitCSV(value), sf, reflect.New(ft).Elem()); err != nil {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+		}
+		if kind == reflect.Map && value != "" {
+			if err := setMapFromStrings(splitCSV(value), sf, reflect.New(ft).Elem()); err != nil {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+		}
+
+		n := len(*out)
 		switch {
+		case registered:
+			defText := ""
+			if value != "" {
+				v, err := parseValue(value, ft, sf)
+				if err != nil {
+					return fmt.Errorf("field %s default: %w", sf.Name, err)
+				}
+				defText = formatScalar(v, sf, true)
+			}
+			if shownDef != def {
+				defText = shownDef
+			}
+			*out = append(*out, &cli.StringFlag{
+				Name:        name,
+				Aliases:     aliases,
+				Usage:       usage,
+				Category:    category,
+				Value:       value,
+				DefaultText: defText,
+				Sources:     sources,
+				Required:    required,
+			})
+		case isFlagValue(ft):
+			v, err := newFieldValue(ft, value)
+			if err != nil {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+			defText := ""
+			if value != "" {
+				defText = v.String()
+			}
+			if shownDef != def {
+				defText = shownDef
+			}
+			*out = append(*out, &cli.GenericFlag{
+				Name:        name,
+				Aliases:     aliases,
+				Usage:       usage,
+				Category:    category,
+				Value:       v,
+				DefaultText: defText,
+				HideDefault: value == "",
+				Sources:     sources,
+				Required:    required,
+			})
 		case ft == reflect.TypeOf(time.Second):
+			defText, err := durationsText(value, sf)
+			if err != nil {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+			if shownDef != def {
+				defText = shownDef
+			}
 			*out = append(*out, &cli.StringFlag{
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
-				Value:       def,
-				DefaultText: def,
+				Category:    category,
+				Value:       value,
+				DefaultText: defText,
+				Sources:     sources,
+				Required:    required,
+			})
+		case kind == reflect.Bool && sf.Type.Kind() == reflect.Pointer:
+			// *bool is tri-state: --name, --no-name, or neither (nil)
+			f, _ := strconv.ParseBool(value)
+			hideDefault := def == ""
+			if hideDefault {
+				usage = strings.TrimSpace(usage + " (default: unset)")
+			}
+			*out = append(*out, &cli.BoolWithInverseFlag{
+				Name:        name,
+				Aliases:     aliases,
+				Usage:       usage,
+				Category:    category,
+				Value:       f,
+				HideDefault: hideDefault,
+				Sources:     sources,
 				Required:    required,
 			})
 		case kind == reflect.Bool:
-			f, _ := strconv.ParseBool(def)
+			f, _ := strconv.ParseBool(value)
 			*out = append(*out, &cli.BoolFlag{
 				Name:     name,
 				Aliases:  aliases,
 				Usage:    usage,
+				Category: category,
 				Value:    f,
+				Sources:  sources,
 				Required: required,
 			})
 		case isAnyInt(kind):
-			f, _ := strconv.ParseInt(def, 10, 64)
-			*out = append(*out, &cli.Int64Flag{
+			base, err := intBase(sf, 10)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+			parse := parseInt
+			if isBytes(sf) {
+				parse = parseIntSize
+			}
+			f, err := parse(value, base)
+			if err != nil && value != "" && isBytes(sf) {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+			mag := uint64(f)
+			if f < 0 {
+				mag = -mag
+			}
+			*out = append(*out, &intFlag{
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
+				Category:    category,
 				Value:       f,
-				DefaultText: def,
+				DefaultText: intDefaultText(def, f < 0, mag, sf),
+				Sources:     sources,
 				Required:    required,
+				Config:      intConfig{Base: base, Bytes: isBytes(sf)},
 			})
 		case isAnyUint(kind):
-			f, _ := strconv.ParseUint(def, 10, 64)
-			*out = append(*out, &cli.Uint64Flag{
+			base, err := intBase(sf, 10)
+			if err != nil {
+				return fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+			parse := parseUint
+			if isBytes(sf) {
+				parse = parseUintSize
+			}
+			f, err := parse(value, base)
+			if err != nil && value != "" && isBytes(sf) {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+			*out = append(*out, &uintFlag{
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
+				Category:    category,
 				Value:       f,
-				DefaultText: def,
+				DefaultText: intDefaultText(def, false, f, sf),
+				Sources:     sources,
 				Required:    required,
+				Config:      intConfig{Base: base, Bytes: isBytes(sf)},
 			})
 		case kind == reflect.Float32 || kind == reflect.Float64:
-			f, _ := strconv.ParseFloat(def, 64)
-			*out = append(*out, &cli.Float64Flag{
+			f, _ := strconv.ParseFloat(value, 64)
+			if err := checkFinite(f, sf); err != nil {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+			if _, err := roundFloat(f, sf); err != nil {
+				return fmt.Errorf("field %s: %w", sf.Name, err)
+			}
+			*out = append(*out, &floatFlag{
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
+				Category:    category,
 				Value:       f,
-				DefaultText: def,
+				DefaultText: shownDef,
+				Sources:     sources,
 				Required:    required,
+				Config:      floatConfig{Locale: o.numberFormat},
+				Validator: func(f float64) error {
+					return checkFinite(f, sf)
+				},
+				ValidateDefaults: true, // also covers values from env sources
 			})
 
 		case ft == reflect.TypeOf(time.Time{}):
@@ -118,9 +471,11 @@ func genFlagsForStruct(rt reflect.Type, inheritedPrefix string, out *[]cli.Flag)
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
-				DefaultText: def,
+				Category:    category,
+				DefaultText: shownDef,
 
-				Value:    def,
+				Value:    value,
+				Sources:  sources,
 				Required: required,
 			}
 			*out = append(*out, tf)
@@ -130,29 +485,133 @@ func genFlagsForStruct(rt reflect.Type, inheritedPrefix string, out *[]cli.Flag)
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
-				Value:       def,
-				DefaultText: def,
+				Category:    category,
+				Value:       value,
+				DefaultText: shownDef,
+				Sources:     sources,
 				Required:    required,
 			})
-		case kind == reflect.String:
+		case kind == reflect.String || isSecret(ft):
 			*out = append(*out, &cli.StringFlag{
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
-				Value:       def,
-				DefaultText: def,
+				Category:    category,
+				Value:       value,
+				DefaultText: shownDef,
+				Sources:     sources,
+				Required:    required,
+			})
+		case kind == reflect.Slice && ft.Elem().Kind() == reflect.Bool:
+			*out = append(*out, &cli.StringSliceFlag{
+				Name:        name,
+				Aliases:     aliases,
+				Usage:       usage,
+				Category:    category,
+				Value:       splitCSV(value),
+				DefaultText: shownDef,
+				Sources:     sources,
 				Required:    required,
+				Validator: func(vs []string) error {
+					_, err := parseBools(vs)
+					return err
+				},
 			})
 		case kind == reflect.Slice:
+			defText := def
+			if ft.Elem() == reflect.TypeOf(time.Second) {
+				defText, _ = durationsText(value, sf) // validated above
+			}
+			if isSecretTagged(sf) && value != "" {
+				defText = redacted
+			}
+			*out = append(*out, &cli.StringSliceFlag{
+				Name:        name,
+				Aliases:     aliases,
+				Usage:       usage,
+				Category:    category,
+				Value:       splitCSV(value),
+				DefaultText: defText,
+				Sources:     sources,
+				Required:    required,
+			})
+		case kind == reflect.Map:
+			if !isMapKey(ft.Key()) {
+				return fmt.Errorf("field %s: unsupported map key type %s", sf.Name, ft.Key())
+			}
+			entries, err := splitMapEntries(splitCSV(value))
+			if err != nil {
+				return fmt.Errorf("field %s default: %w", sf.Name, err)
+			}
+			defText := shownDef
+			if len(entries) > 0 && shownDef == def {
+				pairs := make([]string, len(entries))
+				for i, e := range entries {
+					pairs[i] = e[0] + "=" + e[1]
+				}
+				defText = strings.Join(pairs, ", ")
+			}
 			*out = append(*out, &cli.StringSliceFlag{
 				Name:        name,
 				Aliases:     aliases,
 				Usage:       usage,
-				Value:       splitCSV(def),
-				DefaultText: def,
+				Category:    category,
+				Value:       splitCSV(value),
+				DefaultText: defText,
+				Sources:     sources,
 				Required:    required,
 			})
 		}
+		if deprecated && len(*out) > n {
+			if o.deprecations == nil {
+				o.deprecations = map[string]Deprecation{}
+			}
+			o.deprecations[name] = dep
+		}
+		if gate != "" && len(*out) > n {
+			gateFlag((*out)[n], gate)
+		}
+		if c := sf.Tag.Get(tagCLIComplete); c != "" && len(*out) > n {
+			(*out)[n] = &completeFlag{Flag: (*out)[n], completer: c}
+		}
+	}
+	return nil
+}
 
+// flagRequired reports whether the flag of the leaf field sf must be
+// given, gate being why the flag is inactive in the version of o, if it is.
+// Pointer fields are optional by nature: Bind leaves them nil when unset,
+// cliSources fill fields after flags are parsed and flags of other versions
+// fail when given.
+func (o *options) flagRequired(sf reflect.StructField, omitEmpty bool, gate string) bool {
+	return !omitEmpty && fieldDefault(sf) == "" && !o.zeroDefaults && sf.Type.Kind() != reflect.Pointer && sf.Tag.Get(tagCLISources) == "" && gate == ""
+}
+
+// genFactoryFlags generates the flag choosing the implementation of the
+// interface field sf, followed by the flags of every implementation, which are
+// never required since only the chosen one's are bound.
+func genFactoryFlags(sf reflect.StructField, name string, aliases []string, usage, category string, fs []factory, o *options, out *[]cli.Flag) error {
+	names := factoryNames(fs)
+	def := fieldDefault(sf)
+	if def != "" && !slices.Contains(names, def) {
+		return fmt.Errorf("default %q is not one of %s", def, strings.Join(names, ", "))
+	}
+	sources := o.sources(name, sf)
+	*out = append(*out, &cli.StringFlag{
+		Name:     name,
+		Aliases:  aliases,
+		Usage:    strings.TrimSpace(expandUsage(usage, def, sources.EnvKeys(), names) + " (one of: " + strings.Join(names, ", ") + ")"),
+		Category: category,
+		Value:    def,
+		Sources:  sources,
+		Required: def == "" && !o.zeroDefaults,
+	})
+	sub := *o
+	sub.zeroDefaults = true
+	for _, f := range fs {
+		if err := genFlagsForStruct(f.config, factoryPrefix(name, f), category, &sub, out); err != nil {
+			return fmt.Errorf("%s: %w", f.name, err)
+		}
 	}
+	return nil
 }
@@ -0,0 +1,282 @@
+package clibind
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagFlagsJSON  = "flags-json"
+	flagConfigJSON = "config-json"
+)
+
+// WithFlagsJSON makes CommandWithBinding add a --flags-json flag reading flag
+// values from a JSON object, in a file or on stdin with "-", so other tools can
+// invoke the command without building argv strings:
+//
+//	echo '{"db-host": "db.internal", "DB.Port": 5432}' | app serve --flags-json -
+//
+// Keys are flag names, nested objects as in configuration files ({"db": {"host":
+// ...}}), or dotted Go field paths. The values take precedence over environment
+// variables and the configuration file, but not over flags on the command line.
+func WithFlagsJSON() Option {
+	fj := &flagsJSON{flag: flagFlagsJSON}
+	return func(o *options) {
+		o.flagsJSON = fj
+	}
+}
+
+// WithConfigJSON makes CommandWithBinding add a hidden --config-json flag
+// taking a whole configuration as one JSON object, for orchestration systems
+// passing a config blob instead of enumerating flags:
+//
+//	app serve --config-json '{"DB": {"Host": "db.internal", "Port": 5432}}' --db-port 5433
+//
+// The object is decoded into the configuration as encoding/json would, honoring
+// json tags and filling the fields without a flag (cli:"-", cliSkipFlag) too.
+// Keys of flags may also be those of --flags-json: flag names or Go field paths.
+// Flags on the command line override the values of the object, which override
+// those of --flags-json, environment variables and the configuration file.
+func WithConfigJSON() Option {
+	fj := &flagsJSON{flag: flagConfigJSON, inline: true}
+	return func(o *options) {
+		o.configJSON = fj
+	}
+}
+
+// flagsJSON is the state shared by the --flags-json (or --config-json) flag
+// and the value sources of the generated flags, loaded on the first lookup like
+// a configFile.
+type flagsJSON struct {
+	flag   string
+	inline bool              // the flag holds the object itself rather than a file name
+	path   string            // the flag's value, set by urfave through Destination
+	paths  map[string]string // field path of each flag name
+	jpaths map[string]string // encoding/json path of each flag name, if inline
+
+	once sync.Once
+	data map[string]any
+	err  error
+}
+
+// cliFlag returns the flag of a command binding the struct type schema.
+func (fj *flagsJSON) cliFlag(schema reflect.Type) cli.Flag {
+	fj.paths = map[string]string{}
+	fieldPaths(schema, "", "", fj.paths)
+	if fj.inline {
+		fj.jpaths = map[string]string{}
+		jsonPaths(schema, "", "", fj.jpaths)
+	}
+	usage := "read flag values from the JSON object in `FILE`, - for stdin"
+	if fj.inline {
+		usage = "set the configuration from a JSON `OBJECT`, overridden by other flags"
+	}
+	return &cli.StringFlag{
+		Name:        fj.flag,
+		Usage:       usage,
+		Hidden:      fj.inline,
+		Destination: &fj.path,
+		Action: func(context.Context, *cli.Command, string) error {
+			return fj.loadErr()
+		},
+	}
+}
+
+// fieldPaths records the dotted Go field path of every flag of the struct type t.
+func fieldPaths(t reflect.Type, prefix, path string, out map[string]string) {
+	t = unreferenceType(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if isStructLike(sf.Type) {
+			fieldPaths(sf.Type, nestedPrefix(sf, prefix), path+sf.Name+".", out)
+			continue
+		}
+		name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		out[prefix+name] = path + sf.Name
+	}
+}
+
+// jsonPaths records the dotted path encoding/json decodes every flag of the
+// struct type t from: json tag names, else Go field names, embedded structs
+// without a tag name being flattened.
+func jsonPaths(t reflect.Type, prefix, path string, out map[string]string) {
+	t = unreferenceType(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		key, ok := jsonKey(sf)
+		if sf.PkgPath != "" || !ok {
+			continue
+		}
+		if isStructLike(sf.Type) {
+			sub := path + key + "."
+			if sf.Anonymous && sf.Tag.Get("json") == "" {
+				sub = path
+			}
+			jsonPaths(sf.Type, nestedPrefix(sf, prefix), sub, out)
+			continue
+		}
+		name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		out[prefix+name] = path + key
+	}
+}
+
+// jsonKey returns the object key encoding/json decodes sf from, false if its
+// json tag is "-".
+func jsonKey(sf reflect.StructField) (string, bool) {
+	name, _, _ := strings.Cut(sf.Tag.Get("json"), ",")
+	switch name {
+	case "-":
+		return "", false
+	case "":
+		return sf.Name, true
+	}
+	return name, true
+}
+
+// decodeMember decodes the member v of the --config-json object into fv, a
+// field without a flag, as encoding/json would.
+func decodeMember(v any, fv reflect.Value) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, fv.Addr().Interface())
+}
+
+// jsonMember returns the member of m encoding/json decodes sf from, its key
+// matched regardless of case.
+func jsonMember(m map[string]any, sf reflect.StructField) (any, bool) {
+	key, ok := jsonKey(sf)
+	if !ok {
+		return nil, false
+	}
+	if v, ok := m[key]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, key) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+func (fj *flagsJSON) loadErr() error {
+	if fj.path == "" {
+		return nil
+	}
+	fj.once.Do(func() {
+		var data []byte
+		switch {
+		case fj.inline:
+			data = []byte(fj.path)
+		case fj.path == "-":
+			data, fj.err = io.ReadAll(os.Stdin)
+		default:
+			data, fj.err = os.ReadFile(fj.path)
+		}
+		if fj.err == nil {
+			fj.data, fj.err = decodeConfig("json", data)
+		}
+		switch {
+		case fj.err != nil && fj.inline:
+			fj.err = fmt.Errorf("--%s: %w", fj.flag, fj.err)
+		case fj.err != nil:
+			fj.err = fmt.Errorf("--%s %s: %w", fj.flag, fj.path, fj.err)
+		}
+	})
+	return fj.err
+}
+
+// lookup finds the value of the flag named name by its name or field path.
+func (fj *flagsJSON) lookup(name string) (string, bool) {
+	if fj.loadErr() != nil || fj.data == nil {
+		return "", false
+	}
+	v, ok := lookupKey(fj.data, name)
+	if !ok {
+		v, ok = lookupPath(fj.data, fj.paths[name])
+	}
+	if !ok && fj.inline {
+		v, ok = lookupFoldedPath(fj.data, fj.jpaths[name])
+	}
+	if !ok && fj.inline {
+		v, ok = lookupFoldedPath(fj.data, fj.paths[name])
+	}
+	if !ok || v == nil {
+		return "", false
+	}
+	return configString(v), true
+}
+
+// lookupPath finds the dotted field path in m, as a key or as nested objects.
+func lookupPath(m map[string]any, path string) (any, bool) {
+	if path == "" {
+		return nil, false
+	}
+	if v, ok := m[path]; ok {
+		return v, true
+	}
+	head, rest, ok := strings.Cut(path, ".")
+	if sub, isMap := m[head].(map[string]any); ok && isMap {
+		return lookupPath(sub, rest)
+	}
+	return nil, false
+}
+
+// lookupFoldedPath is lookupPath matching keys regardless of case.
+func lookupFoldedPath(m map[string]any, path string) (any, bool) {
+	if path == "" {
+		return nil, false
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, path) {
+			return v, true
+		}
+	}
+	head, rest, ok := strings.Cut(path, ".")
+	if !ok {
+		return nil, false
+	}
+	for k, v := range m {
+		if sub, isMap := v.(map[string]any); isMap && strings.EqualFold(k, head) {
+			if v, ok := lookupFoldedPath(sub, rest); ok {
+				return v, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// flagsJSONSource is the cli.ValueSource of one flag in the --flags-json object.
+type flagsJSONSource struct {
+	fj  *flagsJSON
+	key string
+}
+
+func (s *flagsJSONSource) Lookup() (string, bool) { return s.fj.lookup(s.key) }
+
+func (s *flagsJSONSource) String() string {
+	return fmt.Sprintf("key %q in --%s", s.key, s.fj.flag)
+}
+
+func (s *flagsJSONSource) GoString() string {
+	return fmt.Sprintf("&flagsJSONSource{key:%q}", s.key)
+}
@@ -0,0 +1,115 @@
+package clibind_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type jsonDB struct {
+	Host string `cli:"host" cliDefault:"localhost"`
+	Port int    `cli:"port" json:"port_number" cliDefault:"5432"`
+}
+
+type jsonConfig struct {
+	DB      jsonDB            `cli:"db"`
+	Region  string            `cli:"region" cliEnv:"REGION" cliDefault:"eu"`
+	Token   string            `cli:"-" cliEnv:"TOKEN"`
+	Labels  map[string]string `cliSkipFlag:"true" json:"labels"`
+	Retries int               `cli:"retries" cliDefault:"3"`
+}
+
+func newJSONRoot(opts ...clibind.Option) *cli.Command {
+	return &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			clibind.CommandWithBinding(nil, "serve", func(context.Context, jsonConfig) error { return nil }, opts...),
+		},
+	}
+}
+
+func TestConfigJSON(t *testing.T) {
+	const object = `{"db": {"HOST": "db.internal", "port_number": 6543}, "Region": "us", "token": "t0k", "labels": {"team": "infra"}}`
+	res := clibindtest.Run(t, newJSONRoot(clibind.WithConfigJSON()), clibindtest.Input{
+		Args: []string{"serve", "--config-json", object, "--retries", "5"},
+		Env:  map[string]string{"REGION": "ap", "TOKEN": "env"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := jsonConfig{
+		DB:      jsonDB{Host: "db.internal", Port: 6543},
+		Region:  "us",
+		Token:   "t0k",
+		Labels:  map[string]string{"team": "infra"},
+		Retries: 5,
+	}
+	if got := clibindtest.Bound[jsonConfig](t, res, "app serve"); !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+
+	res = clibindtest.Run(t, newJSONRoot(clibind.WithConfigJSON()), clibindtest.Input{
+		Args: []string{"serve", "--config-json", `{"token": "t0k", "labels": "team"}`},
+	})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "--config-json: field Labels: json: cannot unmarshal") {
+		t.Errorf("err = %v, want the decoding error", res.Err)
+	}
+}
+
+func TestFlagsJSON(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		args  []string
+		stdin string
+		want  jsonConfig
+	}{
+		{"flag names", []string{"--flags-json", "flags.json"}, "",
+			jsonConfig{DB: jsonDB{Host: "db.internal", Port: 5432}, Region: "us", Token: "env", Retries: 3}},
+		{"nested objects", []string{"--flags-json", "-"}, `{"db": {"port": 6543}, "retries": 4}`,
+			jsonConfig{DB: jsonDB{Host: "localhost", Port: 6543}, Region: "ap", Token: "env", Retries: 4}},
+		{"field paths", []string{"--flags-json", "-"}, `{"DB.Host": "db.internal", "Retries": 4}`,
+			jsonConfig{DB: jsonDB{Host: "db.internal", Port: 5432}, Region: "ap", Token: "env", Retries: 4}},
+		{"command line wins", []string{"--flags-json", "flags.json", "--region", "eu"}, "",
+			jsonConfig{DB: jsonDB{Host: "db.internal", Port: 5432}, Region: "eu", Token: "env", Retries: 3}},
+	} {
+		res := clibindtest.Run(t, newJSONRoot(clibind.WithFlagsJSON()), clibindtest.Input{
+			Args:  append([]string{"serve"}, c.args...),
+			Env:   map[string]string{"REGION": "ap", "TOKEN": "env"},
+			Files: map[string]string{"flags.json": `{"db-host": "db.internal", "region": "us"}`},
+			Stdin: c.stdin,
+		})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[jsonConfig](t, res, "app serve"); !reflect.DeepEqual(got, c.want) {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+
+	for _, c := range []struct {
+		name  string
+		args  []string
+		files map[string]string
+		err   string
+	}{
+		{"missing file", []string{"--flags-json", "nope.json"}, nil, "--flags-json nope.json: open nope.json"},
+		{"invalid JSON", []string{"--flags-json", "flags.json"}, map[string]string{"flags.json": `{"region": `}, "--flags-json flags.json:"},
+	} {
+		res := clibindtest.Run(t, newJSONRoot(clibind.WithFlagsJSON()), clibindtest.Input{
+			Args:  append([]string{"serve"}, c.args...),
+			Env:   map[string]string{"TOKEN": "env"},
+			Files: c.files,
+		})
+		if res.Err == nil || !strings.Contains(res.Err.Error(), c.err) {
+			t.Errorf("%s: err = %v, want %q", c.name, res.Err, c.err)
+		}
+		if len(res.Calls) != 0 {
+			t.Errorf("%s: the handler ran", c.name)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package clibind
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+
+	"github.com/urfave/cli/v3"
+)
+
+var flagValueType = reflect.TypeFor[flag.Value]()
+
+// isFlagValue reports whether *t implements flag.Value (and so cli.Value, which
+// adds Get), for field types of a pre-existing CLI: such fields get a
+// cli.GenericFlag parsing values with their own Set and printing them with
+// their own String, whatever their kind.
+func isFlagValue(t reflect.Type) bool {
+	return reflect.PointerTo(t).Implements(flagValueType)
+}
+
+// value is the cli.Value of the generic flag of a flag.Value field. Get
+// returns the *T being set, so Bind can copy the parsed value into the field
+// even when T's own Get, if any, returns something else. Help takes the type
+// name as placeholder: --addr value.
+type value struct {
+	flag.Value
+}
+
+func (v value) Get() any { return v.Value }
+
+// IsBoolFlag lets boolean values be given as a bare --name.
+func (v value) IsBoolFlag() bool {
+	bf, ok := v.Value.(interface{ IsBoolFlag() bool })
+	return ok && bf.IsBoolFlag()
+}
+
+// newFieldValue returns a new value of the flag.Value type t set to def, if any.
+func newFieldValue(t reflect.Type, def string) (cli.Value, error) {
+	v := reflect.New(t).Interface().(flag.Value)
+	if def != "" {
+		if err := v.Set(def); err != nil {
+			return nil, err
+		}
+	}
+	return value{v}, nil
+}
+
+// setFlagValueField copies the value of the generic flag name, v, into field
+// of the flag.Value type t. Flags assembled by hand may hold a foreign value,
+// which is then parsed again from its String.
+func setFlagValueField(v any, name string, t reflect.Type, field reflect.Value) error {
+	rv := reflect.ValueOf(v)
+	switch {
+	case rv.IsValid() && rv.Type() == reflect.PointerTo(t) && !rv.IsNil():
+		field.Set(rv.Elem())
+	case rv.IsValid() && rv.Type() == t:
+		field.Set(rv)
+	case v != nil:
+		val, err := parseFlagValue(fmt.Sprint(v), t)
+		if err != nil {
+			return fmt.Errorf("flag %s: %w", name, err)
+		}
+		field.Set(val)
+	}
+	return nil
+}
+
+// parseFlagValue parses s with the Set method of the flag.Value type t.
+func parseFlagValue(s string, t reflect.Type) (reflect.Value, error) {
+	p := reflect.New(t)
+	if err := p.Interface().(flag.Value).Set(s); err != nil {
+		return p.Elem(), err
+	}
+	return p.Elem(), nil
+}
+
+// formatFlagValue returns the String of v, whose type is a flag.Value type.
+func formatFlagValue(v reflect.Value) string {
+	p := reflect.New(v.Type())
+	p.Elem().Set(v)
+	return p.Interface().(flag.Value).String()
+}
@@ -0,0 +1,186 @@
+package gcpsm
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	cloudPlatformScope = "https://www.googleapis.com/auth/cloud-platform"
+	defaultTokenURI    = "https://oauth2.googleapis.com/token"
+	metadataHost       = "metadata.google.internal"
+)
+
+// adc obtains and caches access tokens from Application Default Credentials.
+type adc struct {
+	client *http.Client
+
+	mu      sync.Mutex
+	current string
+	expiry  time.Time
+}
+
+// credentialsFile is the subset of service account and authorized user key files
+// the token exchange needs.
+type credentialsFile struct {
+	Type         string `json:"type"`
+	ClientEmail  string `json:"client_email"`
+	PrivateKeyID string `json:"private_key_id"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+	ExpiresIn   int    `json:"expires_in"`
+}
+
+func (a *adc) token(ctx context.Context) (string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.current != "" && time.Until(a.expiry) > time.Minute {
+		return a.current, nil
+	}
+	tok, err := a.fetch(ctx)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: application default credentials: %w", err)
+	}
+	a.current = tok.AccessToken
+	a.expiry = time.Now().Add(time.Duration(tok.ExpiresIn) * time.Second)
+	return a.current, nil
+}
+
+func (a *adc) fetch(ctx context.Context) (*tokenResponse, error) {
+	path := credentialsPath()
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) && os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") == "" {
+		return a.fromMetadata(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var f credentialsFile
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	switch f.Type {
+	case "service_account":
+		return a.fromServiceAccount(ctx, &f)
+	case "authorized_user":
+		return a.post(ctx, defaultTokenURI, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {f.ClientID},
+			"client_secret": {f.ClientSecret},
+			"refresh_token": {f.RefreshToken},
+		})
+	default:
+		return nil, fmt.Errorf("%s: unsupported credentials type %q", path, f.Type)
+	}
+}
+
+// credentialsPath returns GOOGLE_APPLICATION_CREDENTIALS, or the file written by
+// gcloud auth application-default login.
+func credentialsPath() string {
+	if p := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"); p != "" {
+		return p
+	}
+	dir := os.Getenv("CLOUDSDK_CONFIG")
+	if dir == "" && runtime.GOOS == "windows" {
+		dir = filepath.Join(os.Getenv("APPDATA"), "gcloud")
+	}
+	if dir == "" {
+		home, _ := os.UserHomeDir()
+		dir = filepath.Join(home, ".config", "gcloud")
+	}
+	return filepath.Join(dir, "application_default_credentials.json")
+}
+
+// fromServiceAccount exchanges a self-signed JWT for an access token.
+func (a *adc) fromServiceAccount(ctx context.Context, f *credentialsFile) (*tokenResponse, error) {
+	block, _ := pem.Decode([]byte(f.PrivateKey))
+	if block == nil {
+		return nil, errors.New("service account private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if err != nil || !ok {
+		return nil, errors.New("service account private key is not an RSA key")
+	}
+	tokenURI := f.TokenURI
+	if tokenURI == "" {
+		tokenURI = defaultTokenURI
+	}
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": f.PrivateKeyID})
+	claims, _ := json.Marshal(map[string]any{
+		"iss":   f.ClientEmail,
+		"scope": cloudPlatformScope,
+		"aud":   tokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	enc := base64.RawURLEncoding
+	unsigned := enc.EncodeToString(header) + "." + enc.EncodeToString(claims)
+	sum := sha256.Sum256([]byte(unsigned))
+	sig, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, sum[:])
+	if err != nil {
+		return nil, fmt.Errorf("sign token request: %w", err)
+	}
+	return a.post(ctx, tokenURI, url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {unsigned + "." + enc.EncodeToString(sig)},
+	})
+}
+
+func (a *adc) fromMetadata(ctx context.Context) (*tokenResponse, error) {
+	host := os.Getenv("GCE_METADATA_HOST")
+	if host == "" {
+		host = metadataHost
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, "http://"+host+"/computeMetadata/v1/instance/service-accounts/default/token", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var tok tokenResponse
+	if err := doJSON(a.client, req, &tok); err != nil {
+		return nil, fmt.Errorf("no credentials file and no metadata server: %w", err)
+	}
+	return &tok, nil
+}
+
+func (a *adc) post(ctx context.Context, tokenURI string, form url.Values) (*tokenResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var tok tokenResponse
+	if err := doJSON(a.client, req, &tok); err != nil {
+		return nil, err
+	}
+	return &tok, nil
+}
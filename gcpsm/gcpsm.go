@@ -0,0 +1,158 @@
+// Package gcpsm resolves gcp-sm:// references in clibind secret fields to Google
+// Cloud Secret Manager payloads:
+//
+//	type Config struct {
+//	    DBPassword clibind.Secret[string] `cli:"db-password" cliDefault:"gcp-sm://projects/acme/secrets/db-password"`
+//	}
+//
+//	cmd := clibind.CommandWithBinding(nil, "serve", run, gcpsm.Option())
+//
+// A reference names a secret, projects/P/secrets/S, optionally followed by
+// /versions/V; the latest version is used otherwise. Requests authenticate with
+// Application Default Credentials (see Client.TokenSource).
+package gcpsm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+// Scheme is the reference scheme handled by this package.
+const Scheme = "gcp-sm"
+
+const defaultEndpoint = "https://secretmanager.googleapis.com"
+
+var refPattern = regexp.MustCompile(`^projects/[^/]+/secrets/[^/]+(/versions/[^/]+)?$`)
+
+// Client accesses Secret Manager. The zero value uses http.DefaultClient, the
+// public endpoint and Application Default Credentials.
+type Client struct {
+	HTTPClient *http.Client
+	Endpoint   string
+	// TokenSource returns OAuth2 access tokens; nil means Application Default
+	// Credentials: the GOOGLE_APPLICATION_CREDENTIALS file, then gcloud's
+	// application_default_credentials.json, then the GCE metadata server.
+	TokenSource func(ctx context.Context) (string, error)
+
+	adcOnce sync.Once
+	adc     *adc
+}
+
+// Scheme implements clibind.Provider.
+func (c *Client) Scheme() string { return Scheme }
+
+// Option resolves gcp-sm:// references with a zero Client.
+func Option() clibind.Option {
+	return (&Client{}).Option()
+}
+
+// Option resolves gcp-sm:// references with c.
+func (c *Client) Option() clibind.Option {
+	return clibind.WithSecretResolver(Scheme, c.Resolve)
+}
+
+// Resolve returns the payload of the secret version ref refers to.
+func (c *Client) Resolve(ctx context.Context, ref string) (string, error) {
+	name, ok := strings.CutPrefix(ref, Scheme+"://")
+	if !ok || !refPattern.MatchString(name) {
+		return "", fmt.Errorf("gcpsm: %q is not a %s://projects/P/secrets/S[/versions/V] reference", ref, Scheme)
+	}
+	if !strings.Contains(name, "/versions/") {
+		name += "/versions/latest"
+	}
+	token, err := c.token(ctx)
+	if err != nil {
+		return "", err
+	}
+	endpoint := c.Endpoint
+	if endpoint == "" {
+		endpoint = defaultEndpoint
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(endpoint, "/")+"/v1/"+name+":access", nil)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	var resp struct {
+		Payload struct {
+			Data       string `json:"data"`
+			DataCrc32c string `json:"dataCrc32c"`
+		} `json:"payload"`
+	}
+	if err := doJSON(c.httpClient(), req, &resp); err != nil {
+		return "", err
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Payload.Data)
+	if err != nil {
+		return "", fmt.Errorf("gcpsm: decode payload of %s: %w", name, err)
+	}
+	if resp.Payload.DataCrc32c != "" {
+		want, err := strconv.ParseUint(resp.Payload.DataCrc32c, 10, 32)
+		if err != nil || crc32.Checksum(data, crc32.MakeTable(crc32.Castagnoli)) != uint32(want) {
+			return "", fmt.Errorf("gcpsm: payload of %s failed its checksum", name)
+		}
+	}
+	return string(data), nil
+}
+
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+func (c *Client) token(ctx context.Context) (string, error) {
+	if c.TokenSource != nil {
+		return c.TokenSource(ctx)
+	}
+	c.adcOnce.Do(func() { c.adc = &adc{client: c.httpClient()} })
+	return c.adc.token(ctx)
+}
+
+// doJSON sends req and decodes a successful JSON response into out. Google API
+// errors are reported with their status and message.
+func doJSON(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("gcpsm: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("gcpsm: read %s: %w", req.URL.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Error struct {
+				Status  string `json:"status"`
+				Message string `json:"message"`
+			} `json:"error"`
+			Description string `json:"error_description"` // OAuth2 token endpoints
+		}
+		_ = json.Unmarshal(body, &apiErr)
+		msg := apiErr.Error.Message
+		if msg == "" {
+			msg = apiErr.Description
+		}
+		if msg == "" {
+			msg = strings.TrimSpace(string(body))
+		}
+		return fmt.Errorf("gcpsm: %s: %s %s", req.URL.Path, resp.Status, msg)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("gcpsm: decode %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
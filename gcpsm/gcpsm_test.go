@@ -0,0 +1,252 @@
+package gcpsm_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"hash/crc32"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/eosproject/urfave-cli-bind/gcpsm"
+)
+
+// google fakes the token endpoints, the metadata server and Secret Manager.
+type google struct {
+	*httptest.Server
+	key    *rsa.PrivateKey
+	tokens int // issued
+}
+
+func newGoogle(t *testing.T) *google {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	g := &google{key: key}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", g.token)
+	mux.HandleFunc("GET /computeMetadata/v1/instance/service-accounts/default/token", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Metadata-Flavor") != "Google" {
+			http.Error(w, "missing Metadata-Flavor", http.StatusForbidden)
+			return
+		}
+		g.issue(w, "metadata")
+	})
+	mux.HandleFunc("GET /v1/projects/acme/secrets/{secret}/versions/{version}", func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer ") {
+			http.Error(w, `{"error": {"status": "UNAUTHENTICATED", "message": "no token"}}`, http.StatusUnauthorized)
+			return
+		}
+		version, ok := strings.CutSuffix(r.PathValue("version"), ":access")
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		payload := []byte(strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ") + "/" + version)
+		sum := crc32.Checksum(payload, crc32.MakeTable(crc32.Castagnoli))
+		if r.PathValue("secret") == "corrupt" {
+			sum++
+		}
+		fmt.Fprintf(w, `{"payload": {"data": %q, "dataCrc32c": "%d"}}`, base64.StdEncoding.EncodeToString(payload), sum)
+	})
+	g.Server = httptest.NewServer(mux)
+	t.Cleanup(g.Close)
+	return g
+}
+
+// token exchanges signed service account assertions and refresh tokens.
+func (g *google) token(w http.ResponseWriter, r *http.Request) {
+	switch r.FormValue("grant_type") {
+	case "urn:ietf:params:oauth:grant-type:jwt-bearer":
+		parts := strings.Split(r.FormValue("assertion"), ".")
+		if len(parts) != 3 {
+			http.Error(w, `{"error_description": "malformed assertion"}`, http.StatusBadRequest)
+			return
+		}
+		sig, _ := base64.RawURLEncoding.DecodeString(parts[2])
+		sum := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+		if rsa.VerifyPKCS1v15(&g.key.PublicKey, crypto.SHA256, sum[:], sig) != nil {
+			http.Error(w, `{"error_description": "invalid assertion signature"}`, http.StatusBadRequest)
+			return
+		}
+		var claims struct{ Iss, Scope, Aud string }
+		raw, _ := base64.RawURLEncoding.DecodeString(parts[1])
+		json.Unmarshal(raw, &claims)
+		if claims.Iss != "sa@acme.iam.gserviceaccount.com" || claims.Aud != g.URL+"/token" || !strings.Contains(claims.Scope, "cloud-platform") {
+			http.Error(w, `{"error_description": "unexpected claims"}`, http.StatusBadRequest)
+			return
+		}
+		g.issue(w, "service-account")
+	case "refresh_token":
+		if r.FormValue("refresh_token") != "refresh" || r.FormValue("client_secret") != "secret" {
+			http.Error(w, `{"error_description": "invalid refresh token"}`, http.StatusBadRequest)
+			return
+		}
+		g.issue(w, "user")
+	default:
+		http.Error(w, `{"error_description": "unsupported grant type"}`, http.StatusBadRequest)
+	}
+}
+
+func (g *google) issue(w http.ResponseWriter, token string) {
+	g.tokens++
+	fmt.Fprintf(w, `{"access_token": %q, "expires_in": 3600}`, token)
+}
+
+// client returns a Client sending every request to g, whatever its host.
+func (g *google) client() *gcpsm.Client {
+	return &gcpsm.Client{HTTPClient: &http.Client{Transport: redirect(g.URL)}, Endpoint: g.URL}
+}
+
+type redirect string
+
+func (r redirect) RoundTrip(req *http.Request) (*http.Response, error) {
+	u, _ := url.Parse(string(r))
+	req = req.Clone(req.Context())
+	req.URL.Scheme, req.URL.Host = u.Scheme, u.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// credentials writes a credentials file and points
+// GOOGLE_APPLICATION_CREDENTIALS to it.
+func credentials(t *testing.T, v map[string]string) {
+	data, _ := json.Marshal(v)
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", path)
+}
+
+func TestServiceAccount(t *testing.T) {
+	g := newGoogle(t)
+	key := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: must(x509.MarshalPKCS8PrivateKey(g.key))})
+	credentials(t, map[string]string{
+		"type":           "service_account",
+		"client_email":   "sa@acme.iam.gserviceaccount.com",
+		"private_key_id": "k1",
+		"private_key":    string(key),
+		"token_uri":      g.URL + "/token",
+	})
+	c := g.client()
+	for _, ref := range []string{"gcp-sm://projects/acme/secrets/db", "gcp-sm://projects/acme/secrets/db/versions/3"} {
+		want := "service-account/latest"
+		if strings.HasSuffix(ref, "/3") {
+			want = "service-account/3"
+		}
+		if got, err := c.Resolve(context.Background(), ref); err != nil || got != want {
+			t.Errorf("Resolve(%s) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	if g.tokens != 1 {
+		t.Errorf("%d tokens issued, want 1 reused until it expires", g.tokens)
+	}
+}
+
+func TestAuthorizedUser(t *testing.T) {
+	g := newGoogle(t)
+	credentials(t, map[string]string{
+		"type":          "authorized_user",
+		"client_id":     "id",
+		"client_secret": "secret",
+		"refresh_token": "refresh",
+	})
+	if got, err := g.client().Resolve(context.Background(), "gcp-sm://projects/acme/secrets/db"); err != nil || got != "user/latest" {
+		t.Errorf("Resolve = %q, %v; want user/latest", got, err)
+	}
+}
+
+func TestGcloudCredentials(t *testing.T) {
+	g := newGoogle(t)
+	dir := t.TempDir()
+	data := `{"type": "authorized_user", "client_id": "id", "client_secret": "secret", "refresh_token": "refresh"}`
+	if err := os.WriteFile(filepath.Join(dir, "application_default_credentials.json"), []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("CLOUDSDK_CONFIG", dir)
+	if got, err := g.client().Resolve(context.Background(), "gcp-sm://projects/acme/secrets/db"); err != nil || got != "user/latest" {
+		t.Errorf("Resolve = %q, %v; want user/latest", got, err)
+	}
+}
+
+func TestMetadataServer(t *testing.T) {
+	g := newGoogle(t)
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+	t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+	t.Setenv("GCE_METADATA_HOST", strings.TrimPrefix(g.URL, "http://"))
+	if got, err := g.client().Resolve(context.Background(), "gcp-sm://projects/acme/secrets/db"); err != nil || got != "metadata/latest" {
+		t.Errorf("Resolve = %q, %v; want metadata/latest", got, err)
+	}
+}
+
+func TestCredentialErrors(t *testing.T) {
+	g := newGoogle(t)
+	for _, c := range []struct {
+		name  string
+		setup func(t *testing.T)
+		err   string
+	}{
+		{"missing file", func(t *testing.T) {
+			t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", filepath.Join(t.TempDir(), "missing.json"))
+		}, "no such file"},
+		{"unsupported type", func(t *testing.T) {
+			credentials(t, map[string]string{"type": "external_account"})
+		}, `unsupported credentials type "external_account"`},
+		{"bad key", func(t *testing.T) {
+			credentials(t, map[string]string{"type": "service_account", "private_key": "not pem"})
+		}, "not PEM encoded"},
+		{"rejected refresh token", func(t *testing.T) {
+			credentials(t, map[string]string{"type": "authorized_user", "client_secret": "secret", "refresh_token": "revoked"})
+		}, "invalid refresh token"},
+		{"no metadata server", func(t *testing.T) {
+			t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+			t.Setenv("CLOUDSDK_CONFIG", t.TempDir())
+			t.Setenv("GCE_METADATA_HOST", "127.0.0.1:1")
+		}, "no credentials file and no metadata server"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			c.setup(t)
+			cl := g.client()
+			if c.name == "no metadata server" {
+				cl.HTTPClient = nil // the redirect would reach the fake one
+			}
+			_, err := cl.Resolve(context.Background(), "gcp-sm://projects/acme/secrets/db")
+			if err == nil || !strings.Contains(err.Error(), c.err) {
+				t.Errorf("err = %v, want %q", err, c.err)
+			}
+		})
+	}
+}
+
+func TestPayloadChecksum(t *testing.T) {
+	g := newGoogle(t)
+	c := g.client()
+	c.TokenSource = func(context.Context) (string, error) { return "static", nil }
+	if _, err := c.Resolve(context.Background(), "gcp-sm://projects/acme/secrets/corrupt"); err == nil || !strings.Contains(err.Error(), "failed its checksum") {
+		t.Errorf("err = %v, want a checksum failure", err)
+	}
+	if _, err := c.Resolve(context.Background(), "gcp-sm://acme/db"); err == nil {
+		t.Error("resolved a malformed reference")
+	}
+}
+
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
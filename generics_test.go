@@ -0,0 +1,128 @@
+package clibind_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type Paged[T any] struct {
+	Page   int `cli:"page" cliDefault:"1"`
+	Size   int `cli:"size" cliDefault:"20"`
+	Filter T   `cli:"filter,omitempty"`
+}
+
+type FilterOpts struct {
+	Name string   `cli:"name,omitempty"`
+	Tags []string `cli:"tag,omitempty"`
+}
+
+func flagNames(flags []cli.Flag) []string {
+	var names []string
+	for _, fl := range flags {
+		names = append(names, fl.Names()[0])
+	}
+	return names
+}
+
+func TestGenericFlags(t *testing.T) {
+	for _, tc := range []struct {
+		name  string
+		flags []cli.Flag
+		want  []string
+	}{
+		{"struct", clibind.FlagsFromStruct(Paged[FilterOpts]{}), []string{"page", "size", "filter-name", "filter-tag"}},
+		{"pointer to struct", clibind.Flags[Paged[*FilterOpts]](), []string{"page", "size", "filter-name", "filter-tag"}},
+		{"scalar", clibind.Flags[Paged[string]](), []string{"page", "size", "filter"}},
+	} {
+		if got := flagNames(tc.flags); !slices.Equal(got, tc.want) {
+			t.Errorf("%s: flags = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestGenericBind(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "struct", func(context.Context, Paged[FilterOpts]) error { return nil }),
+				clibind.CommandWithBinding(nil, "pointer", func(context.Context, Paged[*FilterOpts]) error { return nil }),
+				clibind.CommandWithBinding(nil, "scalar", func(context.Context, Paged[string]) error { return nil }),
+			},
+		}
+	}
+
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"struct", "--page", "3", "--filter-name", "x", "--filter-tag", "a", "--filter-tag", "b"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	got := clibindtest.Bound[Paged[FilterOpts]](t, res, "app struct")
+	if got.Page != 3 || got.Size != 20 || got.Filter.Name != "x" || !slices.Equal(got.Filter.Tags, []string{"a", "b"}) {
+		t.Errorf("struct: bound %+v", got)
+	}
+
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"pointer"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[Paged[*FilterOpts]](t, res, "app pointer"); got.Filter != nil {
+		t.Errorf("pointer: Filter = %+v, want nil when no --filter-* flag is given", got.Filter)
+	}
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"pointer", "--filter-name", "y"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[Paged[*FilterOpts]](t, res, "app pointer"); got.Filter == nil || got.Filter.Name != "y" {
+		t.Errorf("pointer: Filter = %+v, want name y", got.Filter)
+	}
+
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"scalar", "--filter", "z"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[Paged[string]](t, res, "app scalar"); got.Filter != "z" {
+		t.Errorf("scalar: Filter = %q, want z", got.Filter)
+	}
+}
+
+type EmbeddedOpts struct {
+	Verbose bool `cli:"verbose"`
+}
+
+type taggedEmbed struct {
+	EmbeddedOpts `cli:"opts"`
+}
+
+func TestEmbedCLITagRejected(t *testing.T) {
+	func() {
+		defer func() {
+			if r := recover(); r == nil || !strings.Contains(r.(string), "embedded struct EmbeddedOpts has cli tag") {
+				t.Errorf("FlagsFromStruct: recovered %v, want a cli tag panic", r)
+			}
+		}()
+		clibind.FlagsFromStruct(taggedEmbed{})
+	}()
+
+	var bindErr error
+	root := &cli.Command{
+		Name:  "app",
+		Flags: []cli.Flag{&cli.BoolFlag{Name: "verbose"}},
+		Action: func(_ context.Context, c *cli.Command) error {
+			var cfg taggedEmbed
+			bindErr = clibind.Bind(c, &cfg)
+			return nil
+		},
+	}
+	if res := clibindtest.Run(t, root, clibindtest.Input{}); res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if bindErr == nil || !strings.Contains(bindErr.Error(), "embedded struct EmbeddedOpts has cli tag") {
+		t.Errorf("Bind: err = %v, want a cli tag error", bindErr)
+	}
+}
@@ -3,6 +3,13 @@ module github.com/eosproject/urfave-cli-bind
 go 1.24
 
 require (
+	github.com/BurntSushi/toml v1.5.0
+	github.com/Masterminds/semver/v3 v3.5.0
 	github.com/gofrs/uuid v4.4.0+incompatible
+	github.com/shopspring/decimal v1.4.0
 	github.com/urfave/cli/v3 v3.5.0
+	golang.org/x/crypto v0.41.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sys v0.35.0 // indirect
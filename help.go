@@ -0,0 +1,164 @@
+package clibind
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/urfave/cli/v3"
+)
+
+const metaCategoryOrder = "clibind.categoryOrder"
+
+// SetCategoryOrder records the display order of flag categories on cmd, for use by
+// HelpPrinter. CommandWithBinding calls it when WithCategoryOrder is given.
+func SetCategoryOrder(cmd *cli.Command, categories ...string) {
+	if cmd.Metadata == nil {
+		cmd.Metadata = map[string]any{}
+	}
+	cmd.Metadata[metaCategoryOrder] = categories
+}
+
+// HelpPrinter is a cli.HelpPrinterFunc rendering flag categories in the order set by
+// SetCategoryOrder instead of urfave/cli's alphabetical order. Install it with
+//
+//	cli.HelpPrinter = clibind.HelpPrinter
+//
+// Commands without a category order are printed by cli.DefaultPrintHelp.
+func HelpPrinter(w io.Writer, templ string, data any) {
+	cmd, ok := data.(*cli.Command)
+	if !ok {
+		cli.DefaultPrintHelp(w, templ, data)
+		return
+	}
+	order, ok := cmd.Metadata[metaCategoryOrder].([]string)
+	if !ok {
+		cli.DefaultPrintHelp(w, templ, data)
+		return
+	}
+
+	templ = strings.ReplaceAll(templ,
+		`{{template "visibleFlagCategoryTemplate" .}}`,
+		`{{template "visibleFlagCategoryTemplate" (clibindOrderedCategories .)}}`)
+	cli.HelpPrinterCustom(w, templ, data, map[string]any{
+		"clibindOrderedCategories": func(c *cli.Command) orderedCategories {
+			cats := c.VisibleFlagCategories()
+			sortCategories(cats, order, func(c cli.VisibleFlagCategory) string { return c.Name() })
+			return orderedCategories(cats)
+		},
+	})
+}
+
+// TableHelpPrinter is a cli.HelpPrinterFunc showing the flags of a command as
+// aligned columns: the flag, its default, the environment variables and config
+// file key (see WithConfigFile) it is read from, and its usage. Flag categories
+// follow SetCategoryOrder like with HelpPrinter. Install it with
+//
+//	cli.HelpPrinter = clibind.TableHelpPrinter
+func TableHelpPrinter(w io.Writer, templ string, data any) {
+	cmd, ok := data.(*cli.Command)
+	if !ok {
+		cli.DefaultPrintHelp(w, templ, data)
+		return
+	}
+	templ = strings.ReplaceAll(templ, `{{template "visibleFlagCategoryTemplate" .}}`, `{{clibindFlagTable .}}`)
+	templ = strings.ReplaceAll(templ, `{{template "visibleFlagTemplate" .}}`, `{{clibindFlagTable .}}`)
+	order, _ := cmd.Metadata[metaCategoryOrder].([]string)
+	cli.HelpPrinterCustom(w, templ, data, map[string]any{
+		"clibindFlagTable": func(c *cli.Command) string {
+			cats := c.VisibleFlagCategories()
+			if order != nil {
+				sortCategories(cats, order, func(c cli.VisibleFlagCategory) string { return c.Name() })
+			}
+			return flagTable(cats)
+		},
+	})
+}
+
+// flagTable renders the rows of TableHelpPrinter, a table per category.
+func flagTable(cats []cli.VisibleFlagCategory) string {
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 4, 2, ' ', 0)
+	fmt.Fprint(tw, "\n   FLAG\tDEFAULT\tENV\tCONFIG\tUSAGE")
+	for _, cat := range cats {
+		if cat.Name() != "" {
+			// tabs keep the columns aligned across categories
+			fmt.Fprintf(tw, "\n\t\t\t\t\n   %s\t\t\t\t\n\t\t\t\t", cat.Name())
+		}
+		for _, fl := range cat.Flags() {
+			fmt.Fprintf(tw, "\n   %s\t%s\t%s\t%s\t%s", flagTableRow(fl)...)
+		}
+	}
+	tw.Flush()
+	lines := strings.Split(b.String(), "\n")
+	for i, l := range lines {
+		lines[i] = strings.TrimRight(l, " ")
+	}
+	return strings.Join(lines, "\n")
+}
+
+func flagTableRow(fl cli.Flag) []any {
+	var names []string
+	for _, n := range fl.Names() {
+		if len(n) == 1 {
+			names = append(names, "-"+n)
+		} else {
+			names = append(names, "--"+n)
+		}
+	}
+	row := []any{strings.Join(names, ", "), "", "", "", ""}
+	if df, ok := fl.(cli.DocGenerationFlag); ok {
+		if df.TakesValue() {
+			row[0] = fmt.Sprintf("%s %s", row[0], df.TypeName())
+		}
+		// the same default as the help of urfave, which quotes strings
+		switch def := df.GetDefaultText(); {
+		case def != "":
+			row[1] = def
+		case df.TakesValue():
+			row[1] = df.GetValue()
+		}
+		row[2] = strings.Join(df.GetEnvVars(), ", ")
+		row[4] = strings.ReplaceAll(df.GetUsage(), "`", "")
+	}
+	if rf, ok := fl.(cli.RequiredFlag); ok && rf.IsRequired() && row[1] == "" {
+		row[1] = "required"
+	}
+	for _, src := range flagSourceChain(fl) {
+		if cs, ok := src.(*configSource); ok {
+			row[3] = cs.key
+		}
+	}
+	return row
+}
+
+// orderedCategories stands in for the command inside the category template.
+type orderedCategories []cli.VisibleFlagCategory
+
+func (o orderedCategories) VisibleFlagCategories() []cli.VisibleFlagCategory { return o }
+
+// sortCategories stably sorts items by the rank of their category in order.
+// Uncategorized items come first unless "" is listed; categories missing from
+// order follow the listed ones alphabetically.
+func sortCategories[T any](items []T, order []string, category func(T) string) {
+	rank := func(c string) int {
+		if i := slices.Index(order, c); i >= 0 {
+			return i
+		}
+		if c == "" {
+			return -1
+		}
+		return len(order)
+	}
+	sort.SliceStable(items, func(i, j int) bool {
+		ci, cj := category(items[i]), category(items[j])
+		ri, rj := rank(ci), rank(cj)
+		if ri != rj {
+			return ri < rj
+		}
+		return ri == len(order) && ci < cj
+	})
+}
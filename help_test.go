@@ -0,0 +1,54 @@
+package clibind_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type helpConfig struct {
+	Host    string `cli:"host" cliUsage:"the server host" cliCategory:"Server"`
+	Port    int    `cli:"port" cliDefault:"8080" cliEnv:"PORT" cliCategory:"Server"`
+	Verbose bool   `cli:"verbose" cliUsage:"log more"`
+	Pool    int    `cli:"pool" cliDefault:"4" cliCategory:"Database"`
+}
+
+func runHelp(t *testing.T, printer cli.HelpPrinterFunc, opts ...clibind.Option) string {
+	t.Helper()
+	saved := cli.HelpPrinter
+	cli.HelpPrinter = printer
+	defer func() { cli.HelpPrinter = saved }()
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, helpConfig) error { return nil }, opts...)
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	return res.Stdout
+}
+
+func TestHelpPrinter(t *testing.T) {
+	out := runHelp(t, clibind.HelpPrinter, clibind.WithCategoryOrder("Server", "Database"))
+	if s, d := strings.Index(out, "Server"), strings.Index(out, "Database"); s < 0 || d < s {
+		t.Errorf("categories not in the order Server, Database:\n%s", out)
+	}
+	if out := runHelp(t, clibind.HelpPrinter); strings.Index(out, "Database") > strings.Index(out, "Server") {
+		t.Errorf("categories not in urfave's alphabetical order without WithCategoryOrder:\n%s", out)
+	}
+}
+
+func TestTableHelpPrinter(t *testing.T) {
+	out := report(runHelp(t, clibind.TableHelpPrinter, clibind.WithCategoryOrder("Server", "Database"), clibind.WithConfigFile("config")))
+	for _, want := range []string{
+		"FLAG DEFAULT ENV CONFIG USAGE",
+		"--verbose required verbose log more",
+		"Server\n\n--host string required host the server host\n--port int 8080 PORT port\n\nDatabase\n\n--pool int 4 pool",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("help misses %q:\n%s", want, out)
+		}
+	}
+}
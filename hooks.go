@@ -0,0 +1,68 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+var (
+	postBindMu sync.RWMutex
+	postBind   = map[reflect.Type][]func(reflect.Value) error{}
+)
+
+// RegisterPostBind registers fn to run whenever Bind has populated a struct of type T,
+// so derived fields (a full address, a DSN) are computed once next to the config
+// instead of in every handler:
+//
+//	clibind.RegisterPostBind(func(c *DBConfig) error {
+//	    c.DSN = fmt.Sprintf("postgres://%s:%d/%s", c.Host, c.Port, c.Name)
+//	    return nil
+//	})
+//
+// Hooks also run for nested structs, innermost first, in registration order.
+// WithBinding runs them once the cliSources, value sources, --set overrides and
+// secret references are applied. It fails after Freeze.
+func RegisterPostBind[T any](fn func(*T) error) error {
+	postBindMu.Lock()
+	defer postBindMu.Unlock()
+	t := reflect.TypeFor[T]()
+	if err := checkFrozen(fmt.Sprintf("RegisterPostBind[%s]", t)); err != nil {
+		return err
+	}
+	postBind[t] = append(postBind[t], func(v reflect.Value) error {
+		return fn(v.Addr().Interface().(*T))
+	})
+	return nil
+}
+
+// runPostBind runs the hooks registered for the type of the addressable struct
+// value v, after those of its nested structs.
+func runPostBind(v reflect.Value) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" || !isStructLike(sf.Type) {
+			continue
+		}
+		fv := v.Field(i)
+		for fv.Kind() == reflect.Pointer && !fv.IsNil() {
+			fv = fv.Elem()
+		}
+		if fv.Kind() != reflect.Struct {
+			continue
+		}
+		if err := runPostBind(fv); err != nil {
+			return fmt.Errorf("substruct %s: %w", sf.Name, err)
+		}
+	}
+	postBindMu.RLock()
+	hooks := postBind[t]
+	postBindMu.RUnlock()
+	for _, hook := range hooks {
+		if err := hook(v); err != nil {
+			return fmt.Errorf("post-bind %s: %w", t, err)
+		}
+	}
+	return nil
+}
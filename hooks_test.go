@@ -0,0 +1,174 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type hookDB struct {
+	Host     string                 `cli:"host" cliSources:"hooktest://host"`
+	Password clibind.Secret[string] `cli:"password,omitempty"`
+	Port     int                    `cli:"port" cliDefault:"5432"`
+	DSN      string                 `cli:"-"`
+}
+
+type hookConfig struct {
+	DB hookDB `cli:"db"`
+}
+
+func init() {
+	clibind.RegisterPostBind(func(c *hookDB) error {
+		c.DSN = fmt.Sprintf("host=%s port=%d password=%s", c.Host, c.Port, c.Password.Value())
+		return nil
+	})
+}
+
+func TestPostBindSeesResolvedValues(t *testing.T) {
+	var after hookConfig
+	resolve := func(_ context.Context, ref string) (string, error) {
+		return map[string]string{"hooktest://host": "resolved-host", "hooktest://pw": "s3cret"}[ref], nil
+	}
+	opts := []clibind.Option{
+		clibind.WithSecretResolver("hooktest", resolve),
+		clibind.WithSetFlag(),
+		clibind.WithAfter(func(_ context.Context, cfg hookConfig, err error) error {
+			after = cfg
+			return err
+		}),
+	}
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, hookConfig) error { return nil }, opts...)
+	res := clibindtest.Run(t, root, clibindtest.Input{
+		Args: []string{"--db-password", "hooktest://pw", "--set", "db.port=6543"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	const want = "host=resolved-host port=6543 password=s3cret"
+	if got := clibindtest.Bound[hookConfig](t, res, "app").DB.DSN; got != want {
+		t.Errorf("handler DSN = %q, want %q", got, want)
+	}
+	if after.DB.DSN != want {
+		t.Errorf("After DSN = %q, want %q", after.DB.DSN, want)
+	}
+}
+
+type afterConfig struct {
+	Token string `cli:"token" cliSources:"aftertest://token"`
+}
+
+func TestAfterGetsHandlerRun(t *testing.T) {
+	var (
+		lookups int
+		failing bool
+	)
+	resolve := func(context.Context, string) (string, error) {
+		if failing {
+			return "", errors.New("store down")
+		}
+		lookups++
+		return fmt.Sprintf("token-%d", lookups), nil
+	}
+	var (
+		called bool
+		got    afterConfig
+		gotErr error
+	)
+	handlerErr := errors.New("handler failed")
+	newRoot := func() *cli.Command {
+		return clibind.CommandWithBinding(nil, "app", func(context.Context, afterConfig) error { return handlerErr },
+			clibind.WithSecretResolver("aftertest", resolve),
+			clibind.WithAfter(func(_ context.Context, cfg afterConfig, err error) error {
+				called, got, gotErr = true, cfg, err
+				return fmt.Errorf("decorated: %w", err)
+			}),
+		)
+	}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{})
+	if !errors.Is(res.Err, handlerErr) || !strings.HasPrefix(res.Err.Error(), "decorated: ") {
+		t.Errorf("err = %v, want the handler's error decorated by After", res.Err)
+	}
+	if got.Token != "token-1" || !errors.Is(gotErr, handlerErr) || lookups != 1 {
+		t.Errorf("After got %+v, %v after %d lookups; want the handler's config and error, sources resolved once", got, gotErr, lookups)
+	}
+
+	called, failing = false, true
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{})
+	if !strings.Contains(fmt.Sprint(res.Err), "store down") || called {
+		t.Errorf("err = %v, After called: %t; want the binding error, After not called", res.Err, called)
+	}
+}
+
+func TestBeforeSharesBinding(t *testing.T) {
+	lookups := 0
+	resolve := func(_ context.Context, ref string) (string, error) {
+		lookups++
+		return map[string]string{"hooktest://host": "resolved-host", "hooktest://pw": "s3cret"}[ref], nil
+	}
+	var before hookConfig
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, hookConfig) error { return nil },
+		clibind.WithSecretResolver("hooktest", resolve),
+		clibind.WithSetFlag(),
+		clibind.WithBefore(func(ctx context.Context, cfg hookConfig) (context.Context, error) {
+			before = cfg
+			return ctx, nil
+		}),
+	)
+	res := clibindtest.Run(t, root, clibindtest.Input{
+		Args: []string{"--db-password", "hooktest://pw", "--set", "db.port=6543"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	const want = "host=resolved-host port=6543 password=s3cret"
+	if before.DB.DSN != want {
+		t.Errorf("Before DSN = %q, want %q", before.DB.DSN, want)
+	}
+	if got := clibindtest.Bound[hookConfig](t, res, "app").DB.DSN; got != want {
+		t.Errorf("handler DSN = %q, want %q", got, want)
+	}
+	if lookups != 2 {
+		t.Errorf("%d lookups, want 2: the handler reuses the Before hook's binding", lookups)
+	}
+}
+
+type usageConfig struct {
+	Host    string `cli:"host" cliDefault:"localhost"`
+	Port    int    `cli:"port" cliDefault:"80"`
+	Region  string `cli:"region" cliEnv:"REGION" cliDefault:"eu"`
+	Verbose bool   `cli:"verbose"`
+}
+
+func TestFlagUsage(t *testing.T) {
+	var used [][]string
+	root := &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			clibind.CommandWithBinding(nil, "serve", func(context.Context, usageConfig) error { return nil },
+				clibind.WithFlagUsage(func(_ context.Context, command string, flags []string) {
+					used = append(used, flags)
+				}),
+			),
+		},
+	}
+	env := map[string]string{"REGION": "us"}
+	for _, args := range [][]string{
+		{"serve", "--port=8080", "--verbose", "--host", "--region", "file.txt"},
+		{"serve"},
+	} {
+		if res := clibindtest.Run(t, root, clibindtest.Input{Args: args, Env: env}); res.Err != nil {
+			t.Fatal(res.Err)
+		}
+	}
+	want := [][]string{{"host", "port", "verbose"}, nil}
+	if !slices.EqualFunc(used, want, slices.Equal) {
+		t.Errorf("flag usage = %q, want %q: neither $REGION nor the value of --host, and nothing the second run", used, want)
+	}
+}
@@ -0,0 +1,58 @@
+package clibind
+
+import (
+	"context"
+	"reflect"
+	"time"
+)
+
+// Instrumentation receives the cost of generating flags and binding them, for
+// large applications to feed metrics or pprof labels and notice when a growing
+// config struct makes startup slow. Either hook may be nil.
+type Instrumentation struct {
+	// OnFlagsGenerated is called each time flags are generated for a struct.
+	OnFlagsGenerated func(FlagsGenerated)
+	// OnBindComplete is called by WithBinding once a configuration is bound and
+	// resolved, or failed to be, before the handler runs.
+	OnBindComplete func(ctx context.Context, b BindComplete)
+}
+
+// FlagsGenerated describes one generation of flags.
+type FlagsGenerated struct {
+	Type     reflect.Type
+	Fields   int // bindable fields, nested structs included
+	Flags    int
+	Duration time.Duration
+}
+
+// BindComplete describes one binding by WithBinding.
+type BindComplete struct {
+	Command string // full name
+	Type    reflect.Type
+	Fields  int
+	// BindDuration is the time spent in Bind, mapping flags onto fields, and
+	// ResolveDuration the time spent resolving cliSources and secrets.
+	BindDuration, ResolveDuration time.Duration
+	Err                           error
+}
+
+// WithInstrumentation reports the cost of generating flags and binding them to
+// in's hooks:
+//
+//	clibind.WithInstrumentation(clibind.Instrumentation{
+//	    OnBindComplete: func(ctx context.Context, b clibind.BindComplete) {
+//	        bindSeconds.WithLabelValues(b.Command).Observe(b.BindDuration.Seconds())
+//	    },
+//	})
+func WithInstrumentation(in Instrumentation) Option {
+	return func(o *options) {
+		o.instrumentation = in
+	}
+}
+
+// countFields returns the number of bindable fields of the struct type t.
+func countFields(t reflect.Type) int {
+	n := 0
+	walkLeafFields(t, "", func(string, reflect.StructField) { n++ })
+	return n
+}
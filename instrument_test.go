@@ -0,0 +1,65 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type instrumentedDB struct {
+	Host string `cli:"host" cliDefault:"localhost"`
+	Port int    `cli:"port" cliDefault:"5432"`
+}
+
+type instrumentedConfig struct {
+	DB      instrumentedDB `cli:"db"`
+	Workers int            `cli:"workers" cliDefault:"1"`
+	Token   string         `cli:"-" cliEnv:"TOKEN"`
+}
+
+func TestInstrumentation(t *testing.T) {
+	var (
+		generated []clibind.FlagsGenerated
+		binds     []clibind.BindComplete
+	)
+	in := clibind.Instrumentation{
+		OnFlagsGenerated: func(g clibind.FlagsGenerated) { generated = append(generated, g) },
+		OnBindComplete:   func(_ context.Context, b clibind.BindComplete) { binds = append(binds, b) },
+	}
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "serve", func(context.Context, instrumentedConfig) error { return nil },
+					clibind.WithInstrumentation(in)),
+			},
+		}
+	}
+
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{
+		Args: []string{"serve", "--workers", "2"},
+		Env:  map[string]string{"TOKEN": "t0k"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	typ := reflect.TypeFor[instrumentedConfig]()
+	if len(generated) == 0 || generated[0].Type != typ || generated[0].Fields != 4 || generated[0].Flags != 3 || generated[0].Duration <= 0 {
+		t.Errorf("flags generated = %+v, want 4 fields of %s making 3 flags", generated, typ)
+	}
+	if len(binds) != 1 || binds[0].Command != "app serve" || binds[0].Type != typ || binds[0].Fields != 4 || binds[0].Err != nil {
+		t.Errorf("binds = %+v, want app serve binding 4 fields", binds)
+	}
+
+	binds = nil
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"serve"}})
+	if len(binds) != 1 || binds[0].Err == nil || !strings.Contains(binds[0].Err.Error(), "TOKEN not set") || !errors.Is(res.Err, binds[0].Err) {
+		t.Errorf("binds = %+v, want the error of the missing TOKEN, %v", binds, res.Err)
+	}
+}
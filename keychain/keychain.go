@@ -0,0 +1,73 @@
+// Package keychain resolves clibind secret fields from the operating system's
+// credential store: the macOS Keychain, the Windows Credential Manager, or a
+// Secret Service provider (GNOME Keyring, KWallet) on Linux.
+//
+// Items are keyed by service (the application name) and account (the full flag
+// name), the layout used by most Go keyring libraries:
+//
+//	cmd := clibind.CommandWithBinding(nil, "deploy", run, keychain.Option("deploy"))
+//
+// makes a clibind.Secret[string] field tagged cli:"token" read the generic
+// password of service "deploy" and account "token" when neither --token nor its
+// environment variable is given. Store it with
+//
+//	security add-generic-password -s deploy -a token -w          # macOS
+//	secret-tool store --label=deploy service deploy username token # Linux
+//	cmdkey /generic:deploy:token /user:token /pass                 # Windows
+package keychain
+
+import (
+	"errors"
+	"fmt"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/urfave/cli/v3"
+)
+
+var (
+	// ErrNotFound is returned by Lookup when the credential store has no such item.
+	ErrNotFound = errors.New("keychain: item not found")
+	// ErrUnsupported is returned by Lookup on platforms without a supported store.
+	ErrUnsupported = errors.New("keychain: no credential store on this platform")
+)
+
+// Lookup returns the secret stored for service and account.
+func Lookup(service, account string) (string, error) {
+	return lookup(service, account)
+}
+
+// Source is a cli.ValueSource reading one credential store item. Lookup failures,
+// including a missing store, count as "not found", so the flag keeps its default.
+type Source struct {
+	Service string
+	Account string
+}
+
+// Lookup implements cli.ValueSource.
+func (s *Source) Lookup() (string, bool) {
+	v, err := lookup(s.Service, s.Account)
+	if err != nil {
+		return "", false
+	}
+	return v, true
+}
+
+// Scheme returns "keychain", so WithSourceTimeout(d, "keychain") bounds the
+// lookups of s.
+func (s *Source) Scheme() string { return "keychain" }
+
+func (s *Source) String() string {
+	return fmt.Sprintf("keychain item %q of service %q", s.Account, s.Service)
+}
+
+func (s *Source) GoString() string {
+	return fmt.Sprintf("&keychain.Source{Service:%q,Account:%q}", s.Service, s.Account)
+}
+
+// Option makes the secret fields of a command resolve from the credential store,
+// with app as the service and the flag name as the account.
+func Option(app string) clibind.Option {
+	return clibind.WithSecretSource(func(flag string) cli.ValueSource {
+		return &Source{Service: app, Account: flag}
+	})
+}
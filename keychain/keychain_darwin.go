@@ -0,0 +1,23 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// errSecItemNotFound is the exit status of security(1) for a missing item.
+const errSecItemNotFound = 44
+
+func lookup(service, account string) (string, error) {
+	out, err := exec.Command("security", "find-generic-password", "-s", service, "-a", account, "-w").Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && exitErr.ExitCode() == errSecItemNotFound {
+		return "", ErrNotFound
+	}
+	if err != nil {
+		return "", fmt.Errorf("keychain: security: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,23 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+func lookup(service, account string) (string, error) {
+	out, err := exec.Command("secret-tool", "lookup", "service", service, "username", account).Output()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(out) == 0 && len(exitErr.Stderr) == 0 {
+		return "", ErrNotFound // secret-tool exits 1 without output for a missing item
+	}
+	if errors.Is(err, exec.ErrNotFound) {
+		return "", ErrUnsupported
+	}
+	if err != nil {
+		return "", fmt.Errorf("keychain: secret-tool: %w", err)
+	}
+	return strings.TrimSuffix(string(out), "\n"), nil
+}
@@ -0,0 +1,7 @@
+//go:build !darwin && !linux && !windows
+
+package keychain
+
+func lookup(service, account string) (string, error) {
+	return "", ErrUnsupported
+}
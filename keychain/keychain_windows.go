@@ -0,0 +1,53 @@
+package keychain
+
+import (
+	"errors"
+	"fmt"
+	"syscall"
+	"unsafe"
+)
+
+var (
+	advapi32     = syscall.NewLazyDLL("advapi32.dll")
+	procCredRead = advapi32.NewProc("CredReadW")
+	procCredFree = advapi32.NewProc("CredFree")
+)
+
+const credTypeGeneric = 1
+
+// credential mirrors the Win32 CREDENTIALW structure.
+type credential struct {
+	Flags              uint32
+	Type               uint32
+	TargetName         *uint16
+	Comment            *uint16
+	LastWritten        syscall.Filetime
+	CredentialBlobSize uint32
+	CredentialBlob     *byte
+	Persist            uint32
+	AttributeCount     uint32
+	Attributes         uintptr
+	TargetAlias        *uint16
+	UserName           *uint16
+}
+
+// lookup reads the generic credential "service:account".
+func lookup(service, account string) (string, error) {
+	target, err := syscall.UTF16PtrFromString(service + ":" + account)
+	if err != nil {
+		return "", fmt.Errorf("keychain: %w", err)
+	}
+	var cred *credential
+	ok, _, err := procCredRead.Call(uintptr(unsafe.Pointer(target)), credTypeGeneric, 0, uintptr(unsafe.Pointer(&cred)))
+	if ok == 0 {
+		if errors.Is(err, syscall.ERROR_NOT_FOUND) {
+			return "", ErrNotFound
+		}
+		return "", fmt.Errorf("keychain: CredReadW: %w", err)
+	}
+	defer procCredFree.Call(uintptr(unsafe.Pointer(cred)))
+	if cred.CredentialBlobSize == 0 {
+		return "", nil
+	}
+	return string(unsafe.Slice(cred.CredentialBlob, cred.CredentialBlobSize)), nil
+}
@@ -0,0 +1,179 @@
+package clibind
+
+import (
+	"fmt"
+	"maps"
+	"net/url"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// kvFlag is the compound flag of a cliKV struct. Unlike cli.StringSliceFlag it
+// never splits values on commas, which may appear in the values of the pairs.
+type kvFlag = cli.FlagBase[[]string, kvConfig, kvValue]
+
+// kvConfig holds the names of the flags of the struct that would have been
+// required: they may be given as pairs instead, so expandKV checks them as
+// urfave cannot.
+type kvConfig struct {
+	required []string
+}
+
+type kvValue struct {
+	val *[]string
+}
+
+func (v kvValue) Create(val []string, p *[]string, _ kvConfig) cli.Value {
+	*p = slices.Clone(val)
+	return &kvValue{val: p}
+}
+
+func (v kvValue) ToString(val []string) string { return strings.Join(val, " ") }
+
+func (v *kvValue) Set(s string) error {
+	*v.val = append(*v.val, s)
+	return nil
+}
+
+func (v *kvValue) Get() any       { return slices.Clone(*v.val) }
+func (v *kvValue) String() string { return strings.Join(*v.val, " ") }
+
+func isKV(sf reflect.StructField) bool {
+	kv, _ := strconv.ParseBool(sf.Tag.Get(tagCLIKV))
+	return kv || isKVQuery(sf)
+}
+
+// isKVQuery reports whether the compound flag of sf takes URL queries
+// ("host=x&port=5432") rather than space-separated pairs.
+func isKVQuery(sf reflect.StructField) bool {
+	return sf.Tag.Get(tagCLIKV) == "query"
+}
+
+// kvPairs splits a value of the compound flag name into key/value pairs.
+func kvPairs(name, s string, query bool) ([][2]string, error) {
+	var pairs [][2]string
+	if query {
+		q, err := url.ParseQuery(strings.TrimPrefix(strings.TrimSpace(s), "?"))
+		if err != nil {
+			return nil, fmt.Errorf("flag %s: %w", name, err)
+		}
+		for _, k := range slices.Sorted(maps.Keys(q)) {
+			for _, v := range q[k] {
+				pairs = append(pairs, [2]string{k, v})
+			}
+		}
+		return pairs, nil
+	}
+	for _, pair := range strings.Fields(s) {
+		k, v, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("flag %s: %q is not key=value", name, pair)
+		}
+		pairs = append(pairs, [2]string{k, v})
+	}
+	return pairs, nil
+}
+
+// kvKeys returns the keys accepted by the compound flag of the struct type t:
+// the names of its flags, without the prefix of the struct.
+func kvKeys(t reflect.Type) []string {
+	var keys []string
+	walkLeafFields(t, "", func(name string, sf reflect.StructField) {
+		if skip, _ := strconv.ParseBool(sf.Tag.Get(tagCLISkipFlag)); !skip && !isEnvOnly(sf) {
+			keys = append(keys, name)
+		}
+	})
+	return keys
+}
+
+// genKVFlag returns the compound flag named name of the cliKV struct field sf.
+func genKVFlag(sf reflect.StructField, name, category string, o *options) *kvFlag {
+	usage := sf.Tag.Get(tagCLIUsage)
+	keys := kvKeys(sf.Type)
+	syntax := "key=value pairs"
+	if isKVQuery(sf) {
+		syntax = "URL query"
+	}
+	usage = strings.TrimSpace(usage + " (" + syntax + " of " + strings.Join(keys, ", ") + ")")
+	_, aliases, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+	return &kvFlag{
+		Name:     name,
+		Aliases:  aliases,
+		Usage:    usage,
+		Category: category,
+		Sources:  o.sources(name, sf),
+	}
+}
+
+// expandKV sets the flags of the cliKV struct type t, named prefix+key, from the
+// key=value pairs given to its compound flag name, as URL queries if query is
+// set. Flags given on their own take precedence; repeating a key appends to
+// slices and overrides scalars.
+func expandKV(ctx *cli.Command, name, prefix string, t reflect.Type, query bool) error {
+	if ctx.IsSet(name) {
+		keys := kvKeys(t)
+		explicit := map[string]bool{}
+		for _, k := range keys {
+			explicit[k] = ctx.IsSet(prefix + k)
+		}
+		raw, _ := ctx.Value(name).([]string)
+		for _, s := range raw {
+			pairs, err := kvPairs(name, s, query)
+			if err != nil {
+				return err
+			}
+			for _, kv := range pairs {
+				k, v := kv[0], kv[1]
+				switch {
+				case !slices.Contains(keys, k):
+					return fmt.Errorf("flag %s: unknown key %q, want one of %s", name, k, strings.Join(keys, ", "))
+				case explicit[k]:
+					continue
+				}
+				if err := ctx.Set(prefix+k, v); err != nil {
+					return fmt.Errorf("flag %s: key %s: %w", name, k, err)
+				}
+			}
+		}
+	}
+	var missing []string
+	if fl := lookupKVFlag(ctx, name); fl != nil {
+		for _, n := range fl.Config.required {
+			if !ctx.IsSet(n) {
+				missing = append(missing, strings.TrimPrefix(n, prefix))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("flag %s: missing %s (give them as key=value pairs or as --%s<key> flags)", name, strings.Join(missing, ", "), prefix)
+	}
+	return nil
+}
+
+// lookupKVFlag returns the compound flag name of cmd or one of its ancestors,
+// nil if there is none.
+func lookupKVFlag(cmd *cli.Command, name string) *kvFlag {
+	for _, c := range cmd.Lineage() {
+		for _, fl := range c.Flags {
+			if kv, ok := fl.(*kvFlag); ok && kv.Name == name {
+				return kv
+			}
+		}
+	}
+	return nil
+}
+
+// compoundOf returns the compound flag among flags that the flag name may be
+// given through instead of being required, nil if there is none.
+func compoundOf(flags []cli.Flag, name string) *kvFlag {
+	for _, fl := range flags {
+		if kv, ok := fl.(*kvFlag); ok && slices.Contains(kv.Config.required, name) {
+			return kv
+		}
+	}
+	return nil
+}
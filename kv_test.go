@@ -0,0 +1,117 @@
+package clibind_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type kvRequiredConfig struct {
+	DB struct {
+		Host string `cli:"host"`
+		Port int    `cli:"port" cliDefault:"5432"`
+	} `cli:"db" cliKV:"true"`
+}
+
+type kvDefaultConfig struct {
+	DB struct {
+		Host string `cli:"host" cliDefault:"localhost"`
+	} `cli:"db" cliKV:"true"`
+}
+
+func TestKVRequiredIsPerCommand(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "required", func(context.Context, kvRequiredConfig) error { return nil }),
+				clibind.CommandWithBinding(nil, "default", func(context.Context, kvDefaultConfig) error { return nil }),
+			},
+		}
+	}
+
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"required"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "flag db: missing host") {
+		t.Errorf("required: err = %v, want missing host", res.Err)
+	}
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"required", "--db", "host=db.internal"}})
+	if res.Err != nil {
+		t.Fatalf("required: %v", res.Err)
+	}
+	if got := clibindtest.Bound[kvRequiredConfig](t, res, "app required").DB.Host; got != "db.internal" {
+		t.Errorf("required: Host = %q, want db.internal", got)
+	}
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"default"}})
+	if res.Err != nil {
+		t.Fatalf("default: %v", res.Err)
+	}
+	if got := clibindtest.Bound[kvDefaultConfig](t, res, "app default").DB.Host; got != "localhost" {
+		t.Errorf("default: Host = %q, want localhost", got)
+	}
+}
+
+func TestKVStrictFlags(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, kvRequiredConfig) error { return nil },
+		clibind.WithStrictBind(clibind.StrictFields|clibind.StrictFlags))
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--db", "host=db.internal port=6543"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[kvRequiredConfig](t, res, "app").DB; got.Host != "db.internal" || got.Port != 6543 {
+		t.Errorf("DB = %+v, want db.internal:6543", got)
+	}
+}
+
+type kvQueryConfig struct {
+	DB struct {
+		Host     string   `cli:"host"`
+		Port     int      `cli:"port" cliDefault:"5432"`
+		Password string   `cli:"password,omitempty"`
+		SSL      bool     `cli:"ssl" cliDefault:"false"`
+		Options  []string `cli:"option,omitempty"`
+	} `cli:"db" cliKV:"query"`
+}
+
+func TestKVQuery(t *testing.T) {
+	for _, c := range []struct {
+		name string
+		args []string
+		host string
+		port int
+		pass string
+		ssl  bool
+		opts []string
+	}{
+		{"query", []string{"--db", "host=db.internal&port=6543&ssl=true"}, "db.internal", 6543, "", true, nil},
+		{"leading ?", []string{"--db", "?host=db.internal"}, "db.internal", 5432, "", false, nil},
+		{"percent-encoding", []string{"--db", "host=x&password=a%26b%3Dc"}, "x", 5432, "a&b=c", false, nil},
+		{"repeated keys", []string{"--db", "host=x&option=a&option=b"}, "x", 5432, "", false, []string{"a", "b"}},
+		{"own flag wins", []string{"--db", "host=x&port=1", "--db-port", "2"}, "x", 2, "", false, nil},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, kvQueryConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: c.args})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		db := clibindtest.Bound[kvQueryConfig](t, res, "app").DB
+		if db.Host != c.host || db.Port != c.port || db.Password != c.pass || db.SSL != c.ssl || !slices.Equal(db.Options, c.opts) {
+			t.Errorf("%s: bound %+v", c.name, db)
+		}
+	}
+
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, kvQueryConfig) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--db", "host=x&password=%zz"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "flag db: invalid URL escape") {
+		t.Errorf("err = %v, want the query error", res.Err)
+	}
+	root = clibind.CommandWithBinding(nil, "app", func(context.Context, kvQueryConfig) error { return nil })
+	res = clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+	if !strings.Contains(res.Stdout, "(URL query of host, port, password, ssl, option)") {
+		t.Errorf("help misses the keys of --db:\n%s", res.Stdout)
+	}
+}
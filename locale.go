@@ -0,0 +1,101 @@
+package clibind
+
+import (
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// numberFormat is how a locale writes numbers.
+type numberFormat struct {
+	decimal string
+	groups  *regexp.Regexp // whole numbers with the locale's separators
+	group   string         // separators, removed before parsing
+}
+
+// commaLanguages write decimals with a comma.
+var commaLanguages = []string{
+	"bg", "ca", "cs", "da", "de", "el", "es", "et", "eu", "fi", "fr", "gl", "hr", "hu", "id",
+	"is", "it", "lt", "lv", "nb", "nl", "nn", "no", "pl", "pt", "ro", "ru", "sk", "sl", "sr",
+	"sv", "tr", "uk", "vi",
+}
+
+// pointTerritories are exceptions to commaLanguages.
+var pointTerritories = []string{"de_CH", "it_CH", "es_MX", "es_US"}
+
+// WithNumberLocale makes the float flags of a command accept numbers written
+// the way a locale does, such as "1.234,56" for "de_DE", for CLIs used by
+// non-English speakers. locale is a POSIX locale name (de_DE.UTF-8, fr, pt_BR),
+// or "auto" to take it from LC_ALL, LC_NUMERIC or LANG; "" keeps the default of
+// accepting only Go syntax. Go syntax ("1.5", "1e3") is still tried first, so
+// values from config files and scripts bind the same either way; use a decimal
+// separator along with grouping ("1.234,0"), as "1.234" alone reads as 1.234.
+// The environment variables and the config file of a flag honor the locale too,
+// but not slice elements, map values, --set overrides or cliDefault values.
+func WithNumberLocale(locale string) Option {
+	if locale == "auto" {
+		locale = envLocale()
+	}
+	var nf *numberFormat
+	if locale != "" {
+		nf = localeFormat(locale)
+	}
+	return func(o *options) {
+		o.numberFormat = nf
+	}
+}
+
+func envLocale() string {
+	for _, name := range []string{"LC_ALL", "LC_NUMERIC", "LANG"} {
+		if v := os.Getenv(name); v != "" {
+			return v
+		}
+	}
+	return ""
+}
+
+func localeFormat(locale string) *numberFormat {
+	locale, _, _ = strings.Cut(locale, ".")
+	locale, _, _ = strings.Cut(locale, "@")
+	locale = strings.ReplaceAll(locale, "-", "_")
+	lang, _, _ := strings.Cut(locale, "_")
+	lang = strings.ToLower(lang)
+	switch {
+	case slices.Contains(pointTerritories, locale):
+		return newNumberFormat(".", "'")
+	case slices.Contains(commaLanguages, lang):
+		return newNumberFormat(",", ". \u00a0\u202f") // spaces as in French
+	}
+	return newNumberFormat(".", ", '")
+}
+
+func newNumberFormat(decimal, group string) *numberFormat {
+	g := "[" + regexp.QuoteMeta(group) + "]"
+	d := regexp.QuoteMeta(decimal)
+	return &numberFormat{
+		decimal: decimal,
+		group:   group,
+		groups:  regexp.MustCompile(`^[+-]?([0-9]+|[0-9]{1,3}(` + g + `[0-9]{3})+)(` + d + `[0-9]+)?$`),
+	}
+}
+
+// parseFloat parses s with strconv, or else as written in the locale of nf, if
+// not nil.
+func parseFloat(s string, nf *numberFormat) (float64, error) {
+	f, err := strconv.ParseFloat(s, 64)
+	if err == nil || nf == nil || !nf.groups.MatchString(s) {
+		return f, err
+	}
+	s = strings.Map(func(r rune) rune {
+		switch {
+		case strings.ContainsRune(nf.group, r):
+			return -1
+		case string(r) == nf.decimal:
+			return '.'
+		}
+		return r
+	}, s)
+	return strconv.ParseFloat(s, 64)
+}
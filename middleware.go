@@ -0,0 +1,326 @@
+package clibind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// ErrBind wraps errors binding flags into the configuration of a typed handler.
+var ErrBind = errors.New("bind flags")
+
+// Handler is a typed command handler receiving the bound configuration.
+type Handler[T any] func(ctx context.Context, cfg T) error
+
+// Middleware wraps a Handler, giving a standard place for cross-cutting concerns
+// such as logging, recovery or metrics around typed command handlers.
+type Middleware[T any] func(next Handler[T]) Handler[T]
+
+// Use returns an Option applying mw to the handler of WithBinding or
+// CommandWithBinding. The first middleware is the outermost one:
+//
+//	clibind.WithBinding(run, clibind.Use(loggingMW, recoverMW, metricsMW))
+func Use[T any](mw ...Middleware[T]) Option {
+	return func(o *options) {
+		for _, m := range mw {
+			o.middleware = append(o.middleware, m)
+		}
+	}
+}
+
+// chain wraps fn with the middleware collected in o. Middleware of another config
+// type than T is a programming error and panics.
+func chain[T any](fn Handler[T], o *options) Handler[T] {
+	for i := len(o.middleware) - 1; i >= 0; i-- {
+		mw, ok := o.middleware[i].(Middleware[T])
+		if !ok {
+			panic(fmt.Sprintf("clibind: middleware %T used with a handler of %T", o.middleware[i], fn))
+		}
+		fn = mw(fn)
+	}
+	return fn
+}
+
+// WithRecover makes WithBinding recover panics of the typed handler and return them
+// as a *PanicError, so one bad subcommand cannot crash wrapper tooling.
+func WithRecover() Option {
+	return func(o *options) {
+		o.recoverPanics = true
+	}
+}
+
+// PanicError is returned in place of a panic recovered from a typed handler.
+type PanicError struct {
+	Command string // full name of the command whose handler panicked
+	Value   any    // value passed to panic
+	Stack   []byte // stack trace of the panicking goroutine
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("command %s panicked: %v\n%s", e.Command, e.Value, e.Stack)
+}
+
+// Unwrap returns the panic value if it is an error.
+func (e *PanicError) Unwrap() error {
+	err, _ := e.Value.(error)
+	return err
+}
+
+// BeforeWithBinding wraps a typed hook as a cli.BeforeFunc: flags are bound into a T
+// first, as WithBinding does, then fn runs with the bound configuration. It suits
+// setup shared by many subcommands, like opening database connections. The context
+// returned by fn is passed on to the command, as with any Before, and a WithBinding
+// Action of the same command and type reuses the configuration instead of binding
+// it again, so the hook and the handler see the same values.
+func BeforeWithBinding[T any](
+	fn func(ctx context.Context, cfg T) (context.Context, error),
+) cli.BeforeFunc {
+	return beforeWithBinding(fn, newOptions(nil))
+}
+
+// beforeWithBinding is BeforeWithBinding binding with o.
+func beforeWithBinding[T any](fn func(ctx context.Context, cfg T) (context.Context, error), o *options) cli.BeforeFunc {
+	return func(ctx context.Context, c *cli.Command) (context.Context, error) {
+		defer resetSources(c)
+		t, prov, err := bindCommand[T](ctx, c, o)
+		if err != nil {
+			return ctx, err
+		}
+		ctx = context.WithValue(ctx, boundRunKey{}, &boundRun{cmd: c, cfg: t, prov: prov})
+		return fn(IntoContext(ctx, t), t)
+	}
+}
+
+// WithBefore makes CommandWithBinding install fn as the command's Before, see BeforeWithBinding.
+func WithBefore[T any](fn func(ctx context.Context, cfg T) (context.Context, error)) Option {
+	return func(o *options) {
+		o.before = fn
+	}
+}
+
+// before returns the Before hook collected in o, if any.
+func before[T any](o *options) cli.BeforeFunc {
+	if o.before == nil {
+		return nil
+	}
+	fn, ok := o.before.(func(context.Context, T) (context.Context, error))
+	if !ok {
+		var t T
+		panic(fmt.Sprintf("clibind: Before hook %T used with a handler of %T", o.before, t))
+	}
+	return beforeWithBinding(fn, o)
+}
+
+// WithAfter makes CommandWithBinding install fn as the command's After, for teardown
+// and error decoration. fn receives the configuration bound for the handler and the
+// handler's error (nil if the handler did not run, as with --print-config), and its
+// result becomes the command's error. fn is not called when binding failed.
+func WithAfter[T any](fn func(ctx context.Context, cfg T, runErr error) error) Option {
+	return func(o *options) {
+		o.after = fn
+	}
+}
+
+// afterRun carries the outcome of a run from the Action to the After installed by
+// installAfter. Its Before puts one in the context, which urfave then passes on to
+// both.
+type afterRun struct {
+	cfg any // the T bound by WithBinding, nil until then
+	err error
+}
+
+type afterRunKey struct{}
+
+// recordBound stores cfg in the afterRun of ctx, if any, for the After hook.
+func recordBound(ctx context.Context, cfg any) {
+	if run, ok := ctx.Value(afterRunKey{}).(*afterRun); ok {
+		run.cfg = cfg
+	}
+}
+
+// installAfter wires the After hook collected in o into cmd, whose Before and
+// Action must already be set.
+func installAfter[T any](cmd *cli.Command, o *options) {
+	if o.after == nil {
+		return
+	}
+	hook, ok := o.after.(func(context.Context, T, error) error)
+	if !ok {
+		var t T
+		panic(fmt.Sprintf("clibind: After hook %T used with a handler of %T", o.after, t))
+	}
+
+	before, action := cmd.Before, cmd.Action
+	cmd.Before = func(ctx context.Context, c *cli.Command) (context.Context, error) {
+		ctx = context.WithValue(ctx, afterRunKey{}, &afterRun{})
+		if before == nil {
+			return ctx, nil
+		}
+		bctx, err := before(ctx, c)
+		if bctx == nil {
+			bctx = ctx
+		}
+		return bctx, err
+	}
+	cmd.Action = func(ctx context.Context, c *cli.Command) error {
+		run, ok := ctx.Value(afterRunKey{}).(*afterRun)
+		if !ok {
+			return action(ctx, c)
+		}
+		run.err = action(ctx, c)
+		return nil // returned by After, once the hook had a chance to decorate it
+	}
+	cmd.After = func(ctx context.Context, c *cli.Command) error {
+		run, ok := ctx.Value(afterRunKey{}).(*afterRun)
+		if !ok {
+			return nil // Before failed, and that is the command's error
+		}
+		cfg, ok := run.cfg.(T)
+		if !ok {
+			return run.err // binding failed
+		}
+		return hook(IntoContext(ctx, cfg), cfg, run.err)
+	}
+}
+
+// WithTimeoutField makes WithBinding derive the handler's context deadline from the
+// time.Duration field bound to the flag named flag, so --timeout actually cancels the
+// operation. A zero or negative duration means no deadline.
+func WithTimeoutField(flag string) Option {
+	return func(o *options) {
+		o.timeoutField = flag
+	}
+}
+
+// withTimeout applies the deadline configured by WithTimeoutField to ctx.
+func withTimeout[T any](ctx context.Context, cfg *T, o *options) (context.Context, context.CancelFunc, error) {
+	if o.timeoutField == "" {
+		return ctx, func() {}, nil
+	}
+	fv, ok := findField(reflect.ValueOf(cfg).Elem(), o.timeoutField)
+	if !ok {
+		return ctx, nil, fmt.Errorf("timeout field %q not found", o.timeoutField)
+	}
+	fv = unreferenceValue(fv)
+	d, ok := fv.Interface().(time.Duration)
+	if !ok {
+		return ctx, nil, fmt.Errorf("timeout field %q is %s, not time.Duration", o.timeoutField, fv.Type())
+	}
+	if d <= 0 {
+		return ctx, func() {}, nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	return ctx, cancel, nil
+}
+
+// WithExitCode makes WithBinding return an error carrying exit code code (see
+// cli.ExitCoder) whenever the handler's error matches target according to errors.Is.
+// It may be given several times; the first matching target wins:
+//
+//	clibind.WithExitCode(clibind.ErrBind, 2),
+//	clibind.WithExitCode(fs.ErrNotExist, 3),
+//	clibind.WithExitCode(nil, 1), // nil matches any error
+func WithExitCode(target error, code int) Option {
+	return func(o *options) {
+		o.exitCodes = append(o.exitCodes, exitCode{target: target, code: code})
+	}
+}
+
+type exitCode struct {
+	target error
+	code   int
+}
+
+// exitCodeError is a cli.ExitCoder keeping the wrapped error reachable by errors.Is/As.
+type exitCodeError struct {
+	err  error
+	code int
+}
+
+func (e *exitCodeError) Error() string { return e.err.Error() }
+func (e *exitCodeError) ExitCode() int { return e.code }
+func (e *exitCodeError) Unwrap() error { return e.err }
+
+// mapExitCode wraps err with the exit code of the first matching target.
+func mapExitCode(err error, codes []exitCode) error {
+	if err == nil {
+		return nil
+	}
+	var ec cli.ExitCoder
+	if errors.As(err, &ec) {
+		return err // the handler chose its exit code already
+	}
+	for _, c := range codes {
+		if c.target == nil || errors.Is(err, c.target) {
+			return &exitCodeError{err: err, code: c.code}
+		}
+	}
+	return err
+}
+
+// WithFlagUsage registers fn to learn which flags a WithBinding command is used
+// with, e.g. to find out which options can be deprecated. After parsing, and
+// before binding, fn receives the command's full name and the sorted names of
+// the flags given on the command line, but neither those set by a source (an
+// environment variable, the config file) nor any values.
+func WithFlagUsage(fn func(ctx context.Context, command string, flags []string)) Option {
+	return func(o *options) {
+		o.flagUsage = append(o.flagUsage, fn)
+	}
+}
+
+// reportFlagUsage passes the flags given on the command line of c to the
+// WithFlagUsage callbacks.
+func reportFlagUsage(ctx context.Context, c *cli.Command, o *options) {
+	if len(o.flagUsage) == 0 {
+		return
+	}
+	set := slices.Sorted(maps.Keys(commandLineFlags(c)))
+	for _, fn := range o.flagUsage {
+		fn(ctx, c.FullName(), set)
+	}
+}
+
+// commandArgs returns the arguments c parsed, headed by its name: those its
+// parent passed on, or os.Args for the root as urfave does.
+func commandArgs(c *cli.Command) []string {
+	if lineage := c.Lineage(); len(lineage) > 1 {
+		return lineage[1].Args().Slice()
+	}
+	return os.Args
+}
+
+// commandLineFlags returns the names of the flags of c given on its command
+// line, see commandArgs, up to the arguments left to a subcommand or after "--".
+func commandLineFlags(c *cli.Command) map[string]bool {
+	args := commandArgs(c)
+	given := map[string]bool{}
+	for i := 1; i < len(args); i++ { // after the name of c
+		if args[i] == "--" || c.Command(args[i]) != nil {
+			break
+		}
+		name, ok := strings.CutPrefix(args[i], "-")
+		if !ok || name == "" {
+			continue // a positional argument
+		}
+		name, _, hasValue := strings.Cut(strings.TrimPrefix(name, "-"), "=")
+		for _, fl := range c.Flags {
+			if !slices.Contains(fl.Names(), name) {
+				continue
+			}
+			given[fl.Names()[0]] = true
+			if df, ok := fl.(cli.DocGenerationFlag); ok && df.TakesValue() && !hasValue {
+				i++ // its value
+			}
+		}
+	}
+	return given
+}
@@ -0,0 +1,111 @@
+package clibind
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+)
+
+// schemaVersionKey is the configuration file key holding the schema version of
+// the file. Files without it are version 1.
+const schemaVersionKey = "schema-version"
+
+// A SchemaVersioner is a configuration struct declaring the version of its
+// configuration file schema, see RegisterMigration.
+type SchemaVersioner interface {
+	SchemaVersion() int
+}
+
+var (
+	migrationsMu sync.RWMutex
+	migrations   = map[reflect.Type]map[int]func(map[string]any) error{}
+)
+
+// RegisterMigration registers fn to upgrade the configuration files (see
+// WithConfigFile) of commands binding T from schema version from to from+1. T
+// declares its current version by implementing SchemaVersioner, and files state
+// theirs under the "schema-version" key. A file of an older version goes through
+// every migration up to the current one before any flag reads it:
+//
+//	func (ServeConfig) SchemaVersion() int { return 2 }
+//
+//	clibind.RegisterMigration[ServeConfig](1, func(cfg map[string]any) error {
+//	    cfg["listen-addr"] = cfg["addr"] // renamed in version 2
+//	    delete(cfg, "addr")
+//	    return nil
+//	})
+//
+// fn edits the decoded file in place; nested objects are map[string]any too.
+// It fails after Freeze.
+func RegisterMigration[T any](from int, fn func(cfg map[string]any) error) error {
+	migrationsMu.Lock()
+	defer migrationsMu.Unlock()
+	t := reflect.TypeFor[T]()
+	if err := checkFrozen(fmt.Sprintf("RegisterMigration[%s](%d)", t, from)); err != nil {
+		return err
+	}
+	if migrations[t] == nil {
+		migrations[t] = map[int]func(map[string]any) error{}
+	}
+	migrations[t][from] = fn
+	return nil
+}
+
+// migrateConfig upgrades the decoded configuration file data to the schema
+// version of the struct type t.
+func migrateConfig(t reflect.Type, data map[string]any) error {
+	if t == nil {
+		return nil
+	}
+	sv, ok := reflect.New(t).Elem().Interface().(SchemaVersioner)
+	if !ok {
+		return nil
+	}
+	current := sv.SchemaVersion()
+	version, err := fileSchemaVersion(data)
+	if err != nil {
+		return err
+	}
+	if version > current {
+		return fmt.Errorf("schema version %d is newer than the supported %d", version, current)
+	}
+	migrationsMu.RLock()
+	steps := migrations[t]
+	migrationsMu.RUnlock()
+	for ; version < current; version++ {
+		fn, ok := steps[version]
+		if !ok {
+			return fmt.Errorf("no migration from schema version %d", version)
+		}
+		if err := fn(data); err != nil {
+			return fmt.Errorf("migrate from schema version %d: %w", version, err)
+		}
+	}
+	delete(data, schemaVersionKey)
+	return nil
+}
+
+func fileSchemaVersion(data map[string]any) (int, error) {
+	v, ok := data[schemaVersionKey]
+	if !ok {
+		return 1, nil
+	}
+	var s string
+	switch v := v.(type) {
+	case json.Number:
+		s = v.String()
+	case int:
+		return v, nil
+	case string:
+		s = v
+	default:
+		return 0, fmt.Errorf("%s: %v is not an integer", schemaVersionKey, v)
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %q is not an integer", schemaVersionKey, s)
+	}
+	return n, nil
+}
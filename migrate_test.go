@@ -0,0 +1,69 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type migratedConfig struct {
+	Listen  string `cli:"listen" cliDefault:":80"`
+	Workers int    `cli:"workers" cliDefault:"1"`
+}
+
+func (migratedConfig) SchemaVersion() int { return 3 }
+
+func init() {
+	clibind.RegisterMigration[migratedConfig](1, func(cfg map[string]any) error {
+		if cfg["addr"] == "broken" {
+			return errors.New("cannot move addr")
+		}
+		cfg["listen"] = cfg["addr"] // renamed in version 2
+		delete(cfg, "addr")
+		return nil
+	})
+	clibind.RegisterMigration[migratedConfig](2, func(cfg map[string]any) error {
+		if pool, ok := cfg["pool"].(map[string]any); ok {
+			cfg["workers"] = pool["size"] // flattened in version 3
+			delete(cfg, "pool")
+		}
+		return nil
+	})
+}
+
+func TestMigrations(t *testing.T) {
+	for _, c := range []struct {
+		name, file, err string
+		want            migratedConfig
+	}{
+		{"version 1", "addr: ':8080'\npool:\n  size: 4\n", "", migratedConfig{Listen: ":8080", Workers: 4}},
+		{"version 2", "schema-version: 2\nlisten: ':8081'\npool:\n  size: 2\n", "", migratedConfig{Listen: ":8081", Workers: 2}},
+		{"current", "schema-version: 3\nlisten: ':8082'\nworkers: 8\n", "", migratedConfig{Listen: ":8082", Workers: 8}},
+		{"newer", "schema-version: 4\n", "schema version 4 is newer than the supported 3", migratedConfig{}},
+		{"failed migration", "addr: broken\n", "migrate from schema version 1: cannot move addr", migratedConfig{}},
+		{"invalid version", "schema-version: two\n", `schema-version: "two" is not an integer`, migratedConfig{}},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, migratedConfig) error { return nil },
+			clibind.WithConfigFile("config"))
+		res := clibindtest.Run(t, root, clibindtest.Input{
+			Args:  []string{"--config", "config.yaml"},
+			Files: map[string]string{"config.yaml": c.file},
+		})
+		if c.err != "" {
+			if res.Err == nil || !strings.Contains(res.Err.Error(), c.err) {
+				t.Errorf("%s: err = %v, want %q", c.name, res.Err, c.err)
+			}
+			continue
+		}
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[migratedConfig](t, res, "app"); got != c.want {
+			t.Errorf("%s: bound %+v, want %+v", c.name, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+package clibind
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// minisignKey is a minisign public key.
+type minisignKey struct {
+	id  [8]byte
+	key ed25519.PublicKey
+}
+
+// parseMinisignKey accepts the base64 key line of a minisign .pub file, or the
+// whole file.
+func parseMinisignKey(s string) (*minisignKey, error) {
+	lines := minisignLines(s)
+	if len(lines) == 0 {
+		return nil, errors.New("empty minisign public key")
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[len(lines)-1])
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != "Ed" {
+		return nil, errors.New("not a minisign public key")
+	}
+	k := &minisignKey{key: ed25519.PublicKey(raw[10:])}
+	copy(k.id[:], raw[2:10])
+	return k, nil
+}
+
+// verify checks the minisign signature file sig of data, including the
+// signature of its trusted comment.
+func (k *minisignKey) verify(data, sig []byte) error {
+	lines := minisignLines(string(sig))
+	if len(lines) != 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return errors.New("malformed minisign signature")
+	}
+	raw, err := base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(raw) != 2+8+ed25519.SignatureSize {
+		return errors.New("malformed minisign signature")
+	}
+	if !bytes.Equal(raw[2:10], k.id[:]) {
+		return fmt.Errorf("signed with key %X, want %X", raw[2:10], k.id[:])
+	}
+	signature := raw[10:]
+	switch string(raw[:2]) {
+	case "Ed":
+	case "ED": // prehashed, the default since minisign 0.10
+		sum := blake2b.Sum512(data)
+		data = sum[:]
+	default:
+		return fmt.Errorf("unsupported minisign algorithm %q", raw[:2])
+	}
+	if !ed25519.Verify(k.key, data, signature) {
+		return errors.New("invalid signature")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil {
+		return errors.New("malformed minisign signature")
+	}
+	comment := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if !ed25519.Verify(k.key, append(bytes.Clone(signature), comment...), global) {
+		return errors.New("invalid signature of the trusted comment")
+	}
+	return nil
+}
+
+// minisignLines returns the non-empty lines of s; in a public key file, the
+// untrusted comment line is dropped.
+func minisignLines(s string) []string {
+	var lines []string
+	for _, l := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		if l = strings.TrimSpace(l); l != "" {
+			lines = append(lines, l)
+		}
+	}
+	return lines
+}
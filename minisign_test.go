@@ -0,0 +1,79 @@
+package clibind_test
+
+import (
+	"context"
+	"encoding/hex"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"golang.org/x/crypto/blake2b"
+)
+
+// TestBLAKE2b512 pins the prehash of minisign signatures to the BLAKE2b-512
+// test vectors of RFC 7693.
+func TestBLAKE2b512(t *testing.T) {
+	for _, c := range []struct{ in, want string }{
+		// RFC 7693, Appendix A
+		{"abc", "ba80a53f981c4d0d6a2797b69f12f6e94c212f14685ac4b74b12bb6fdbffa2d1" +
+			"7d87c5392aab792dc252d5de4533cc9518d38aa8dbf1925ab92386edd4009923"},
+		{"", "786a02f742015903c6c6fd852552d272912f4740e15847618a86e217f71f5419" +
+			"d25e1031afee585313896444934eb04b903a685b1448b755d56f701afe9be2ce"},
+	} {
+		sum := blake2b.Sum512([]byte(c.in))
+		if got := hex.EncodeToString(sum[:]); got != c.want {
+			t.Errorf("BLAKE2b-512(%q) = %s, want %s", c.in, got, c.want)
+		}
+	}
+}
+
+type signedConfig struct {
+	Host string `cli:"host"`
+	Port int    `cli:"port"`
+}
+
+func readTestdata(t *testing.T, name string) string {
+	t.Helper()
+	b, err := os.ReadFile(filepath.Join("testdata", "minisign", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(b)
+}
+
+// The fixtures were signed by aead.dev/minisign, a minisign implementation:
+// config.json.minisig is prehashed (algorithm ED, the default of minisign),
+// config.json.legacy.minisig is not (Ed).
+func TestConfigSignature(t *testing.T) {
+	pub, data := readTestdata(t, "minisign.pub"), readTestdata(t, "config.json")
+	for _, c := range []struct {
+		name, data, sig, err string
+	}{
+		{"prehashed", data, readTestdata(t, "config.json.minisig"), ""},
+		{"legacy", data, readTestdata(t, "config.json.legacy.minisig"), ""},
+		{"tampered", strings.Replace(data, "5432", "5433", 1), readTestdata(t, "config.json.minisig"), "invalid signature"},
+		{"trusted comment", data, strings.Replace(readTestdata(t, "config.json.minisig"), "prehashed", "tampered", 1), "invalid signature of the trusted comment"},
+	} {
+		root := clibind.CommandWithBinding(nil, "app", func(context.Context, signedConfig) error { return nil },
+			clibind.WithConfigFile("config"), clibind.WithConfigPublicKey(pub))
+		res := clibindtest.Run(t, root, clibindtest.Input{
+			Args:  []string{"--config", "config.json"},
+			Files: map[string]string{"config.json": c.data, "config.json.minisig": c.sig},
+		})
+		if c.err != "" {
+			if res.Err == nil || !strings.Contains(res.Err.Error(), c.err) {
+				t.Errorf("%s: err = %v, want %q", c.name, res.Err, c.err)
+			}
+			continue
+		}
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[signedConfig](t, res, "app"); got.Host != "db.internal" || got.Port != 5432 {
+			t.Errorf("%s: bound %+v", c.name, got)
+		}
+	}
+}
@@ -0,0 +1,102 @@
+package clibind_test
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type Inner struct {
+	Host string `cli:"host,omitempty"`
+}
+
+type (
+	anonNone   struct{ Inner }
+	anonPrefix struct {
+		Inner `cliPrefix:"p-"`
+	}
+	anonPointer struct{ *Inner }
+	namedNone   struct{ DB Inner }
+	namedCLI    struct {
+		DB Inner `cli:"db"`
+	}
+	namedPrefix struct {
+		DB Inner `cliPrefix:"p-"`
+	}
+	namedBoth struct {
+		DB Inner `cli:"db" cliPrefix:"p-"`
+	}
+	namedPointer struct {
+		DB *Inner `cli:"db"`
+	}
+	inheritedAnon struct {
+		Outer anonPrefix `cli:"o"`
+	}
+	inheritedNamed struct {
+		Outer namedCLI `cliPrefix:"o-"`
+	}
+)
+
+// checkNested checks that T gets the single flag want, and that Bind fills the
+// field host returns from it.
+func checkNested[T any](t *testing.T, want string, host func(T) string) {
+	t.Helper()
+	if got := flagNames(clibind.FlagsFromStruct(new(T))); !slices.Equal(got, []string{want}) {
+		t.Errorf("FlagsFromStruct: flags = %v, want [%s]", got, want)
+	}
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, T) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--" + want, "db.internal"}})
+	if res.Err != nil {
+		t.Fatalf("Bind: %v", res.Err)
+	}
+	if got := host(clibindtest.Bound[T](t, res, "app")); got != "db.internal" {
+		t.Errorf("Bind: host = %q, want db.internal", got)
+	}
+}
+
+func TestNestedPrefix(t *testing.T) {
+	t.Run("anonymous/no tag", func(t *testing.T) {
+		checkNested(t, "host", func(c anonNone) string { return c.Host })
+	})
+	t.Run("anonymous/cliPrefix", func(t *testing.T) {
+		checkNested(t, "p-host", func(c anonPrefix) string { return c.Host })
+	})
+	t.Run("anonymous/pointer", func(t *testing.T) {
+		checkNested(t, "host", func(c anonPointer) string {
+			if c.Inner == nil {
+				return ""
+			}
+			return c.Host
+		})
+	})
+	t.Run("named/no tag", func(t *testing.T) {
+		checkNested(t, "host", func(c namedNone) string { return c.DB.Host })
+	})
+	t.Run("named/cli", func(t *testing.T) {
+		checkNested(t, "db-host", func(c namedCLI) string { return c.DB.Host })
+	})
+	t.Run("named/cliPrefix", func(t *testing.T) {
+		checkNested(t, "p-host", func(c namedPrefix) string { return c.DB.Host })
+	})
+	t.Run("named/cli and cliPrefix", func(t *testing.T) {
+		checkNested(t, "p-host", func(c namedBoth) string { return c.DB.Host })
+	})
+	t.Run("named/pointer", func(t *testing.T) {
+		checkNested(t, "db-host", func(c namedPointer) string {
+			if c.DB == nil {
+				return ""
+			}
+			return c.DB.Host
+		})
+	})
+	t.Run("inherited/anonymous", func(t *testing.T) {
+		checkNested(t, "o-p-host", func(c inheritedAnon) string { return c.Outer.Host })
+	})
+	t.Run("inherited/named", func(t *testing.T) {
+		checkNested(t, "o-db-host", func(c inheritedNamed) string { return c.Outer.DB.Host })
+	})
+	// anonymous/cli is rejected, see TestEmbedCLITagRejected
+}
@@ -0,0 +1,66 @@
+package clibind
+
+import (
+	"reflect"
+	"strings"
+)
+
+// isNullable reports whether t is one of the sql.Null types, NullString,
+// NullInt64, NullBool, NullTime... and Null[T], a value and its Valid flag, or
+// an Optional[T], a value and its IsSet flag.
+func isNullable(t reflect.Type) bool {
+	if isOptional(t) {
+		return true
+	}
+	return t.Kind() == reflect.Struct && t.PkgPath() == "database/sql" && strings.HasPrefix(t.Name(), "Null") &&
+		t.NumField() == 2 && t.Field(1).Name == "Valid" && t.Field(1).Type.Kind() == reflect.Bool
+}
+
+// nullableField returns sf with the type of a field holding a nullable value
+// replaced by a pointer to the value: flags and Bind treat it as such, nil
+// being the unset, invalid value.
+func nullableField(sf reflect.StructField) (reflect.StructField, bool) {
+	if !isNullable(sf.Type) {
+		return sf, false
+	}
+	sf.Type = reflect.PointerTo(sf.Type.Field(0).Type)
+	return sf, true
+}
+
+// setNullable sets the nullable fv to the valid value v.
+func setNullable(fv, v reflect.Value) {
+	fv.Field(0).Set(v)
+	fv.Field(1).SetBool(true)
+}
+
+// nullableValue returns the value of the nullable v, or false when it is
+// invalid. The value of an Optional that is not set counts when it is not
+// zero, as it is then the default of its field.
+func nullableValue(v reflect.Value) (reflect.Value, bool) {
+	if !v.Field(1).Bool() && (!isOptional(v.Type()) || v.Field(0).IsZero()) {
+		return reflect.Value{}, false
+	}
+	return v.Field(0), true
+}
+
+// nullableCodec parses and formats the nullable values of type t where a
+// single string stands for them (config files, cliSources, dumps): empty is
+// invalid, anything else the valid value it parses to.
+func nullableCodec(t reflect.Type) typeCodec {
+	return typeCodec{
+		parse: func(s string, sf reflect.StructField) (reflect.Value, error) {
+			v := reflect.New(t).Elem()
+			if err := setFieldFromString(s, sf, v.Field(0)); err != nil {
+				return v, err
+			}
+			v.Field(1).SetBool(true)
+			return v, nil
+		},
+		format: func(v reflect.Value) string {
+			if val, ok := nullableValue(v); ok {
+				return formatField(reflect.StructField{}, val, true)
+			}
+			return ""
+		},
+	}
+}
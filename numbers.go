@@ -0,0 +1,326 @@
+package clibind
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// intFlag and uintFlag replace cli.Int64Flag and cli.Uint64Flag so command-line
+// values get the same parsing as slice elements and defaults (see parseInt).
+type (
+	intFlag  = cli.FlagBase[int64, intConfig, intValue]
+	uintFlag = cli.FlagBase[uint64, intConfig, uintValue]
+)
+
+// intConfig configures the parsing of intFlag and uintFlag values.
+type intConfig struct {
+	Base  int
+	Bytes bool // byte sizes such as 10MB or 1GiB, see parseSize
+}
+
+// floatFlag replaces cli.Float64Flag so command-line values honor WithNumberLocale.
+type floatFlag = cli.FlagBase[float64, floatConfig, floatValue]
+
+// floatConfig configures the parsing of floatFlag values.
+type floatConfig struct {
+	Locale *numberFormat // nil for Go syntax only
+}
+
+type intValue struct {
+	val   *int64
+	base  int
+	bytes bool
+}
+
+func (i intValue) Create(val int64, p *int64, c intConfig) cli.Value {
+	*p = val
+	return &intValue{val: p, base: c.Base, bytes: c.Bytes}
+}
+
+func (i intValue) ToString(v int64) string { return strconv.FormatInt(v, displayBase(i.base)) }
+
+func (i *intValue) Set(s string) error {
+	parse := parseInt
+	if i.bytes {
+		parse = parseIntSize
+	}
+	v, err := parse(s, i.base)
+	if err != nil {
+		return err
+	}
+	*i.val = v
+	return nil
+}
+
+func (i *intValue) Get() any       { return *i.val }
+func (i *intValue) String() string { return strconv.FormatInt(*i.val, displayBase(i.base)) }
+
+type uintValue struct {
+	val   *uint64
+	base  int
+	bytes bool
+}
+
+func (u uintValue) Create(val uint64, p *uint64, c intConfig) cli.Value {
+	*p = val
+	return &uintValue{val: p, base: c.Base, bytes: c.Bytes}
+}
+
+func (u uintValue) ToString(v uint64) string { return strconv.FormatUint(v, displayBase(u.base)) }
+
+func (u *uintValue) Set(s string) error {
+	parse := parseUint
+	if u.bytes {
+		parse = parseUintSize
+	}
+	v, err := parse(s, u.base)
+	if err != nil {
+		return err
+	}
+	*u.val = v
+	return nil
+}
+
+func (u *uintValue) Get() any       { return *u.val }
+func (u *uintValue) String() string { return strconv.FormatUint(*u.val, displayBase(u.base)) }
+
+type floatValue struct {
+	val    *float64
+	locale *numberFormat
+}
+
+func (f floatValue) Create(val float64, p *float64, c floatConfig) cli.Value {
+	*p = val
+	return &floatValue{val: p, locale: c.Locale}
+}
+
+func (f floatValue) ToString(v float64) string { return strconv.FormatFloat(v, 'g', -1, 64) }
+
+func (f *floatValue) Set(s string) error {
+	v, err := parseFloat(s, f.locale)
+	if err != nil {
+		return err
+	}
+	*f.val = v
+	return nil
+}
+
+func (f *floatValue) Get() any       { return *f.val }
+func (f *floatValue) String() string { return strconv.FormatFloat(*f.val, 'g', -1, 64) }
+
+func displayBase(base int) int {
+	if base == 0 {
+		return 10
+	}
+	return base
+}
+
+// decimalPattern matches decimal numbers with optional digit separators, fraction
+// and exponent, e.g. 1_000_000, 1e6 or 2.5e3.
+var decimalPattern = regexp.MustCompile(`^[+-]?[0-9]+(_[0-9]+)*(\.[0-9]+(_[0-9]+)*)?([eE][+-]?[0-9]+)?$`)
+
+// maxExponent bounds the exponent of scientific notation; anything larger cannot
+// fit 64 bits and would only make big.Rat do needless work.
+const maxExponent = 40
+
+// parseInt parses s in the given base like strconv.ParseInt. For base 0 and 10,
+// decimal values may also use '_' digit separators and scientific notation, as
+// long as the result is a whole number: 1e6 and 1.5e3 are accepted, 1.5 is not.
+func parseInt(s string, base int) (int64, error) {
+	i, err := strconv.ParseInt(s, base, 64)
+	if err == nil || base != 0 && base != 10 {
+		return i, err
+	}
+	r, perr := parseExact("ParseInt", s)
+	if perr != nil {
+		return 0, perr
+	}
+	if r == nil {
+		return 0, err
+	}
+	if !r.Num().IsInt64() {
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+	}
+	return r.Num().Int64(), nil
+}
+
+// parseUint is the unsigned counterpart of parseInt.
+func parseUint(s string, base int) (uint64, error) {
+	u, err := strconv.ParseUint(s, base, 64)
+	if err == nil || base != 0 && base != 10 {
+		return u, err
+	}
+	r, perr := parseExact("ParseUint", s)
+	if perr != nil {
+		return 0, perr
+	}
+	if r == nil {
+		return 0, err
+	}
+	if !r.Num().IsUint64() {
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+	}
+	return r.Num().Uint64(), nil
+}
+
+// parseExact parses a decimal number exactly and requires it to be whole. It
+// returns nil, nil when s is not a decimal number at all, so callers can report
+// their own syntax error.
+func parseExact(fn, s string) (*big.Rat, error) {
+	if !decimalPattern.MatchString(s) {
+		return nil, nil
+	}
+	if i := strings.IndexAny(s, "eE"); i >= 0 {
+		exp, err := strconv.Atoi(s[i+1:])
+		if err != nil || exp > maxExponent || exp < -maxExponent {
+			return nil, &strconv.NumError{Func: fn, Num: s, Err: strconv.ErrRange}
+		}
+	}
+	r, ok := new(big.Rat).SetString(strings.ReplaceAll(s, "_", ""))
+	if !ok {
+		return nil, nil
+	}
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q is not a whole number", s)
+	}
+	return r, nil
+}
+
+// sizePattern matches byte sizes: a decimal number, possibly with a fraction,
+// and a unit.
+var sizePattern = regexp.MustCompile(`^([+-]?[0-9]+(?:_[0-9]+)*(?:\.[0-9]+)?)\s*([a-zA-Z]+)$`)
+
+// sizeUnits maps the lower-cased units of parseSize to their multipliers.
+var sizeUnits = map[string]uint64{
+	"b":  1,
+	"kb": 1e3, "mb": 1e6, "gb": 1e9, "tb": 1e12, "pb": 1e15, "eb": 1e18,
+	"kib": 1 << 10, "mib": 1 << 20, "gib": 1 << 30, "tib": 1 << 40, "pib": 1 << 50, "eib": 1 << 60,
+}
+
+// parseSize parses a byte size: a number followed by an SI (kB, MB, GB, ...,
+// powers of 1000) or IEC (KiB, MiB, GiB, ..., powers of 1024) unit, in any case
+// and optionally separated by a space, so 10MB, 1.5 GiB and 512kib all work.
+// The size must be a whole number of bytes. It returns nil, nil when s has no
+// unit, for callers to parse it as a plain number.
+func parseSize(fn, s string) (*big.Rat, error) {
+	m := sizePattern.FindStringSubmatch(strings.TrimSpace(s))
+	if m == nil {
+		return nil, nil
+	}
+	mult, ok := sizeUnits[strings.ToLower(m[2])]
+	if !ok {
+		return nil, fmt.Errorf("%q: unknown unit %q, want B, kB, MB, GB, TB, PB, EB or KiB, MiB, GiB, TiB, PiB, EiB", s, m[2])
+	}
+	r, ok := new(big.Rat).SetString(strings.ReplaceAll(m[1], "_", ""))
+	if !ok {
+		return nil, &strconv.NumError{Func: fn, Num: s, Err: strconv.ErrSyntax}
+	}
+	r.Mul(r, new(big.Rat).SetInt(new(big.Int).SetUint64(mult)))
+	if !r.IsInt() {
+		return nil, fmt.Errorf("%q is not a whole number of bytes", s)
+	}
+	return r, nil
+}
+
+// parseIntSize parses s as a byte size (see parseSize), or else like parseInt.
+func parseIntSize(s string, base int) (int64, error) {
+	r, err := parseSize("ParseInt", s)
+	switch {
+	case err != nil:
+		return 0, err
+	case r == nil:
+		return parseInt(s, base)
+	case !r.Num().IsInt64():
+		return 0, &strconv.NumError{Func: "ParseInt", Num: s, Err: strconv.ErrRange}
+	}
+	return r.Num().Int64(), nil
+}
+
+// parseUintSize is the unsigned counterpart of parseIntSize.
+func parseUintSize(s string, base int) (uint64, error) {
+	r, err := parseSize("ParseUint", s)
+	switch {
+	case err != nil:
+		return 0, err
+	case r == nil:
+		return parseUint(s, base)
+	case !r.Num().IsUint64():
+		return 0, &strconv.NumError{Func: "ParseUint", Num: s, Err: strconv.ErrRange}
+	}
+	return r.Num().Uint64(), nil
+}
+
+// isBytes reports whether the integer field sf holds a byte size (cliBytes).
+func isBytes(sf reflect.StructField) bool {
+	bytes, _ := strconv.ParseBool(sf.Tag.Get(tagCLIBytes))
+	return bytes
+}
+
+// groupFrom is the magnitude from which integer defaults get thousands separators;
+// smaller numbers such as ports read fine as they are.
+const groupFrom = 1_000_000
+
+var (
+	byteUnits   = []string{"B", "KiB", "MiB", "GiB", "TiB", "PiB", "EiB"}
+	siByteUnits = []string{"B", "kB", "MB", "GB", "TB", "PB", "EB"}
+)
+
+// intDefaultText renders the default of an integer field for help output: byte
+// counts (cliBytes) in the largest unit dividing them evenly, SI if the default
+// is written with an SI unit (10MB reads 10 MB) and IEC otherwise, and large
+// decimal values with thousands separators. The flag keeps the machine value;
+// defaults written in another base, like 0xFF, are shown as written.
+func intDefaultText(def string, neg bool, mag uint64, sf reflect.StructField) string {
+	if def == "" || hasFlagRefs(def) {
+		return def
+	}
+	if isSecretTagged(sf) {
+		return redacted
+	}
+	if base, err := intBase(sf, 10); err != nil || base != 0 && base != 10 {
+		return def
+	}
+	digits := strings.ToLower(strings.TrimLeft(def, "+-"))
+	if strings.HasPrefix(digits, "0x") || strings.HasPrefix(digits, "0o") || strings.HasPrefix(digits, "0b") {
+		return def
+	}
+	sign := ""
+	if neg {
+		sign = "-"
+	}
+	if isBytes(sf) {
+		units, step := byteUnits, uint64(1024)
+		if m := sizePattern.FindStringSubmatch(strings.TrimSpace(def)); m != nil && sizeUnits[strings.ToLower(m[2])]%1000 == 0 {
+			units, step = siByteUnits, 1000
+		}
+		unit := 0
+		for mag >= step && mag%step == 0 && unit < len(units)-1 {
+			mag /= step
+			unit++
+		}
+		return sign + groupDigits(mag) + " " + units[unit]
+	}
+	if mag < groupFrom {
+		return def
+	}
+	return sign + groupDigits(mag)
+}
+
+// groupDigits formats n with ',' between groups of three digits.
+func groupDigits(n uint64) string {
+	s := strconv.FormatUint(n, 10)
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && (len(s)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
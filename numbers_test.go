@@ -0,0 +1,192 @@
+package clibind_test
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type baseConfig struct {
+	Mode    int  `cli:"mode" cliDefault:"010"`
+	Perm    uint `cli:"perm" cliDefault:"010"`
+	Octal   int  `cli:"octal" cliBase:"0" cliDefault:"010"`
+	Grouped int  `cli:"grouped" cliDefault:"1_000"`
+}
+
+func TestIntBase(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name:     "app",
+			Commands: []*cli.Command{clibind.CommandWithBinding(nil, "chmod", func(context.Context, baseConfig) error { return nil })},
+		}
+	}
+	for _, c := range []struct {
+		args []string
+		want baseConfig
+	}{
+		{nil, baseConfig{Mode: 10, Perm: 10, Octal: 8, Grouped: 1000}},
+		{[]string{"--mode", "0755", "--perm", "017", "--octal", "0x10"}, baseConfig{Mode: 755, Perm: 17, Octal: 16, Grouped: 1000}},
+	} {
+		res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: append([]string{"chmod"}, c.args...)})
+		if res.Err != nil {
+			t.Fatalf("%v: %v", c.args, res.Err)
+		}
+		if got := clibindtest.Bound[baseConfig](t, res, "app chmod"); got != c.want {
+			t.Errorf("%v: bound %+v, want %+v", c.args, got, c.want)
+		}
+	}
+	if res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"chmod", "--mode", "0x10"}}); res.Err == nil {
+		t.Error("--mode 0x10 was accepted without cliBase:\"0\"")
+	}
+}
+
+type sizeConfig struct {
+	Cache  int64  `cli:"cache" cliBytes:"true" cliDefault:"10MB"`
+	Buffer uint   `cli:"buffer" cliBytes:"true" cliDefault:"1500kB"`
+	Upload int    `cli:"upload" cliBytes:"true" cliDefault:"64MiB"`
+	Block  uint64 `cli:"block" cliBytes:"true" cliDefault:"1048576"`
+	Odd    int    `cli:"odd" cliBytes:"true" cliDefault:"1001B"`
+}
+
+func TestBytesDefaultText(t *testing.T) {
+	want := map[string]string{"cache": "10 MB", "buffer": "1,500 kB", "upload": "64 MiB", "block": "1 MiB", "odd": "1,001 B"}
+	for _, fl := range clibind.Flags[sizeConfig]() {
+		name := fl.Names()[0]
+		if got := fl.(cli.DocGenerationFlag).GetDefaultText(); got != want[name] {
+			t.Errorf("--%s default shown as %q, want %q", name, got, want[name])
+		}
+	}
+}
+
+type localeConfig struct {
+	Ratio float64 `cli:"ratio" cliEnv:"RATIO" cliDefault:"1.5"`
+}
+
+func TestNumberLocale(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "de", func(context.Context, localeConfig) error { return nil },
+					clibind.WithNumberLocale("de_DE.UTF-8")),
+				clibind.CommandWithBinding(nil, "go", func(context.Context, localeConfig) error { return nil }),
+			},
+		}
+	}
+	for _, c := range []struct {
+		args []string
+		env  map[string]string
+		want float64
+	}{
+		{[]string{"de", "--ratio", "1.234,5"}, nil, 1234.5},
+		{[]string{"de", "--ratio", "2.5"}, nil, 2.5},
+		{[]string{"de"}, map[string]string{"RATIO": "0,25"}, 0.25},
+		{[]string{"de"}, nil, 1.5},
+		{[]string{"go", "--ratio", "1e3"}, nil, 1000},
+	} {
+		res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: c.args, Env: c.env})
+		if res.Err != nil {
+			t.Errorf("%v: %v", c.args, res.Err)
+			continue
+		}
+		if got := clibindtest.Bound[localeConfig](t, res, "app "+c.args[0]).Ratio; got != c.want {
+			t.Errorf("%v: Ratio = %v, want %v", c.args, got, c.want)
+		}
+	}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"go", "--ratio", "1,5"}})
+	if res.Err == nil {
+		t.Error("go --ratio 1,5 bound, want the command without a locale to reject it")
+	}
+}
+
+type unitConfig struct {
+	Timeout time.Duration   `cli:"timeout" cliUnit:"s" cliDefault:"30"`
+	Backoff []time.Duration `cli:"backoff,omitempty" cliUnit:"ms"`
+}
+
+func TestDurationUnit(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name:     "app",
+			Commands: []*cli.Command{clibind.CommandWithBinding(nil, "wait", func(context.Context, unitConfig) error { return nil })},
+		}
+	}
+	for _, c := range []struct {
+		args    []string
+		timeout time.Duration
+		backoff []time.Duration
+	}{
+		{nil, 30 * time.Second, nil},
+		{[]string{"--timeout", "5"}, 5 * time.Second, nil},
+		{[]string{"--timeout", "1m30s", "--backoff", "100", "--backoff", "2s"}, 90 * time.Second, []time.Duration{100 * time.Millisecond, 2 * time.Second}},
+	} {
+		res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: append([]string{"wait"}, c.args...)})
+		if res.Err != nil {
+			t.Fatalf("%v: %v", c.args, res.Err)
+		}
+		got := clibindtest.Bound[unitConfig](t, res, "app wait")
+		if got.Timeout != c.timeout || !slices.Equal(got.Backoff, c.backoff) {
+			t.Errorf("%v: bound %+v, want Timeout %v and Backoff %v", c.args, got, c.timeout, c.backoff)
+		}
+	}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"wait", "--timeout", "99999999999999"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "99999999999999s overflows") {
+		t.Errorf("err = %v, want the overflow reported", res.Err)
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), `invalid cliUnit "d", want ns, us, ms, s, m or h`) {
+			t.Errorf("recovered %v, want the invalid cliUnit reported", r)
+		}
+	}()
+	clibind.Flags[struct {
+		Timeout time.Duration `cli:"timeout" cliUnit:"d" cliDefault:"1"`
+	}]()
+}
+
+type precisionConfig struct {
+	Price   float64   `cli:"price" cliPrecision:"2" cliDefault:"2.675"`
+	Weights []float64 `cli:"weight,omitempty" cliPrecision:"1"`
+	Ratio   float32   `cli:"ratio" cliPrecision:"0" cliDefault:"-2.5"`
+}
+
+func TestPrecision(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name:     "app",
+			Commands: []*cli.Command{clibind.CommandWithBinding(nil, "price", func(context.Context, precisionConfig) error { return nil })},
+		}
+	}
+	for _, c := range []struct {
+		args []string
+		want precisionConfig
+	}{
+		{nil, precisionConfig{Price: 2.68, Ratio: -3}},
+		{[]string{"--price", "1.005", "--weight", "0.25", "--weight", "1.04", "--ratio", "0.4"}, precisionConfig{Price: 1.01, Weights: []float64{0.3, 1}, Ratio: 0}},
+	} {
+		res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: append([]string{"price"}, c.args...)})
+		if res.Err != nil {
+			t.Fatalf("%v: %v", c.args, res.Err)
+		}
+		got := clibindtest.Bound[precisionConfig](t, res, "app price")
+		if got.Price != c.want.Price || got.Ratio != c.want.Ratio || !slices.Equal(got.Weights, c.want.Weights) {
+			t.Errorf("%v: bound %+v, want %+v", c.args, got, c.want)
+		}
+	}
+
+	defer func() {
+		if r := recover(); r == nil || !strings.Contains(fmt.Sprint(r), `invalid cliPrecision "-1", want a number of decimal places`) {
+			t.Errorf("recovered %v, want the invalid cliPrecision reported", r)
+		}
+	}()
+	clibind.Flags[struct {
+		Price float64 `cli:"price" cliPrecision:"-1" cliDefault:"1"`
+	}]()
+}
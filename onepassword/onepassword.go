@@ -0,0 +1,209 @@
+// Package onepassword resolves 1Password secret references (op://vault/item/field)
+// in clibind secret fields:
+//
+//	type Config struct {
+//	    APIKey clibind.Secret[string] `cli:"api-key" cliDefault:"op://dev/stripe/credential"`
+//	}
+//
+//	cmd := clibind.CommandWithBinding(nil, "serve", run, onepassword.Option())
+//
+// References are read through a 1Password Connect server when OP_CONNECT_HOST and
+// OP_CONNECT_TOKEN are set, and with the op CLI ("op read") otherwise, which
+// covers desktop app integration and service account tokens alike.
+package onepassword
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"strings"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+// Scheme is the reference scheme handled by this package.
+const Scheme = "op"
+
+// Client resolves references. The zero value reads OP_CONNECT_HOST and
+// OP_CONNECT_TOKEN and falls back to the op CLI.
+type Client struct {
+	ConnectHost  string
+	ConnectToken string
+	HTTPClient   *http.Client
+}
+
+// Scheme implements clibind.Provider.
+func (c *Client) Scheme() string { return Scheme }
+
+// Option resolves op:// references with a zero Client.
+func Option() clibind.Option {
+	return (&Client{}).Option()
+}
+
+// Option resolves op:// references with c.
+func (c *Client) Option() clibind.Option {
+	return clibind.WithSecretResolver(Scheme, c.Resolve)
+}
+
+// reference is a parsed op://vault/item/[section/]field reference.
+type reference struct {
+	vault, item, section, field string
+}
+
+func parseReference(ref string) (reference, error) {
+	rest, ok := strings.CutPrefix(ref, Scheme+"://")
+	if ok && !strings.Contains(rest, "?") { // attribute queries (?attribute=otp) need the CLI's logic
+		parts := strings.Split(rest, "/")
+		switch len(parts) {
+		case 3:
+			return reference{vault: parts[0], item: parts[1], field: parts[2]}, nil
+		case 4:
+			return reference{vault: parts[0], item: parts[1], section: parts[2], field: parts[3]}, nil
+		}
+	}
+	return reference{}, fmt.Errorf("onepassword: %q is not an op://vault/item/[section/]field reference", ref)
+}
+
+// Resolve returns the field value ref points to.
+func (c *Client) Resolve(ctx context.Context, ref string) (string, error) {
+	r, err := parseReference(ref)
+	if err != nil {
+		return "", err
+	}
+	host, token := c.ConnectHost, c.ConnectToken
+	if host == "" {
+		host, token = os.Getenv("OP_CONNECT_HOST"), os.Getenv("OP_CONNECT_TOKEN")
+	}
+	if host != "" && token != "" {
+		return c.connect(ctx, strings.TrimSuffix(host, "/"), token, r)
+	}
+	return readCLI(ctx, ref)
+}
+
+func readCLI(ctx context.Context, ref string) (string, error) {
+	var stderr strings.Builder
+	cmd := exec.CommandContext(ctx, "op", "read", "--no-newline", ref)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("onepassword: op read: %s", msg)
+		}
+		return "", fmt.Errorf("onepassword: op read: %w", err)
+	}
+	return string(out), nil
+}
+
+type connectItem struct {
+	Fields []struct {
+		ID      string `json:"id"`
+		Label   string `json:"label"`
+		Value   string `json:"value"`
+		Section *struct {
+			ID string `json:"id"`
+		} `json:"section"`
+	} `json:"fields"`
+	Sections []struct {
+		ID    string `json:"id"`
+		Label string `json:"label"`
+	} `json:"sections"`
+}
+
+// connect reads the field through the Connect API. Vaults and items may be
+// given by name or ID, like with the op CLI.
+func (c *Client) connect(ctx context.Context, host, token string, r reference) (string, error) {
+	vaultID, err := c.findID(ctx, host, token, "/v1/vaults", r.vault)
+	if err != nil {
+		return "", err
+	}
+	itemID, err := c.findID(ctx, host, token, "/v1/vaults/"+vaultID+"/items", r.item)
+	if err != nil {
+		return "", err
+	}
+	var item connectItem
+	if err := c.get(ctx, host, token, "/v1/vaults/"+vaultID+"/items/"+itemID, &item); err != nil {
+		return "", err
+	}
+	sectionID := ""
+	if r.section != "" {
+		for _, s := range item.Sections {
+			if s.ID == r.section || s.Label == r.section {
+				sectionID = s.ID
+			}
+		}
+		if sectionID == "" {
+			return "", fmt.Errorf("onepassword: item %q has no section %q", r.item, r.section)
+		}
+	}
+	for _, f := range item.Fields {
+		if f.ID != r.field && f.Label != r.field {
+			continue
+		}
+		if sectionID != "" && (f.Section == nil || f.Section.ID != sectionID) {
+			continue
+		}
+		return f.Value, nil
+	}
+	return "", fmt.Errorf("onepassword: item %q has no field %q", r.item, r.field)
+}
+
+// findID returns the ID of the vault or item at path whose name (or ID) is nameOrID.
+func (c *Client) findID(ctx context.Context, host, token, path, nameOrID string) (string, error) {
+	attr := "title"
+	if path == "/v1/vaults" {
+		attr = "name"
+	}
+	var found []struct {
+		ID string `json:"id"`
+	}
+	filter := url.Values{"filter": {fmt.Sprintf("%s eq %q", attr, nameOrID)}}
+	if err := c.get(ctx, host, token, path+"?"+filter.Encode(), &found); err != nil {
+		return "", err
+	}
+	switch len(found) {
+	case 0:
+		return nameOrID, nil // not a name; let the next request fail if it is no ID either
+	case 1:
+		return found[0].ID, nil
+	}
+	return "", fmt.Errorf("onepassword: %q matches %d entries at %s, use its ID", nameOrID, len(found), path)
+}
+
+func (c *Client) get(ctx context.Context, host, token, path string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, host+path, nil)
+	if err != nil {
+		return fmt.Errorf("onepassword: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	client := c.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("onepassword: %w", err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("onepassword: read %s: %w", req.URL.Path, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		var apiErr struct {
+			Message string `json:"message"`
+		}
+		if json.Unmarshal(body, &apiErr) != nil || apiErr.Message == "" {
+			apiErr.Message = strings.TrimSpace(string(body))
+		}
+		return fmt.Errorf("onepassword: %s: %s %s", req.URL.Path, resp.Status, apiErr.Message)
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("onepassword: decode %s: %w", req.URL.Path, err)
+	}
+	return nil
+}
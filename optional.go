@@ -0,0 +1,38 @@
+package clibind
+
+import "reflect"
+
+// Optional holds a flag value together with whether the flag was given, so a
+// handler can tell an omitted flag from one explicitly set to the zero value
+// without a pointer field:
+//
+//	type Config struct {
+//	    Retries clibind.Optional[int]  `cli:"retries"`
+//	    Verbose clibind.Optional[bool] `cli:"verbose"`
+//	}
+//
+// The flag is the one T would get, never required; an Optional[bool] is a
+// --[no-]verbose flag. Value is the bound value, or the cliDefault of the field
+// when the flag is omitted, and IsSet whether the flag was given on the command
+// line or through its sources (environment variables, config file...).
+type Optional[T any] struct {
+	Value T
+	IsSet bool
+}
+
+// NewOptional returns v as a set Optional, e.g. to fill one in outside of Bind.
+func NewOptional[T any](v T) Optional[T] {
+	return Optional[T]{Value: v, IsSet: true}
+}
+
+func (*Optional[T]) optional() {}
+
+// optionalMarker is implemented by *Optional[T] for every T.
+type optionalMarker interface{ optional() }
+
+var optionalMarkerType = reflect.TypeFor[optionalMarker]()
+
+// isOptional reports whether t is an Optional[T].
+func isOptional(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(optionalMarkerType)
+}
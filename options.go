@@ -0,0 +1,342 @@
+package clibind
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+// Option customizes how flags are generated from a struct and how typed handlers run.
+type Option func(*options)
+
+type options struct {
+	autoEnv            bool
+	envName            func(flag string) string
+	envPrefix          string
+	prefixCategories   bool
+	order              FlagOrder
+	categoryOrder      []string
+	mergePolicy        MergePolicy
+	zeroDefaults       bool
+	numberFormat       *numberFormat // see WithNumberLocale
+	compound           *kvFlag       // the cliKV flag the flags being generated belong to
+	middleware         []any         // Middleware[T] of the handler's config type
+	recoverPanics      bool
+	before             any // func(context.Context, T) (context.Context, error)
+	after              any // func(context.Context, T, error) error
+	flagUsage          []func(ctx context.Context, command string, flags []string)
+	deprecationWarning func(ctx context.Context, d Deprecation)
+	deprecations       map[string]Deprecation // by flag name, filled by flag generation
+	strict             StrictMode
+	timeoutField       string
+	exitCodes          []exitCode
+	printConfig        bool
+	explainConfig      bool
+	secretSources      []func(flag string) cli.ValueSource
+	resolvers          map[string]SecretResolver // by reference scheme
+	config             *configFile
+	flagsJSON          *flagsJSON
+	configJSON         *flagsJSON
+	setFlag            bool
+	dotenv             *dotenvFile
+	valueSources       []ValueSource // see WithValueSource
+	defaultTimeout     time.Duration
+	schemeTimeouts     map[string]time.Duration
+	instrumentation    Instrumentation
+	version            string // see WithVersion
+}
+
+func newOptions(opts []Option) *options {
+	o := &options{envName: EnvName}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithAutoEnv makes every generated flag also read its value from an environment
+// variable derived from the full (prefixed) flag name, see EnvName.
+func WithAutoEnv() Option {
+	return func(o *options) {
+		o.autoEnv = true
+	}
+}
+
+// WithEnvPrefix is WithAutoEnv with prefix prepended to every derived variable
+// name, so with WithEnvPrefix("MYAPP_") a "db-host" flag reads MYAPP_DB_HOST.
+// It also applies to names derived by a WithEnvNameFunc, but not to the ones
+// given by cliEnv tags.
+func WithEnvPrefix(prefix string) Option {
+	return func(o *options) {
+		o.autoEnv = true
+		o.envPrefix = prefix
+	}
+}
+
+// WithEnvNameFunc replaces the strategy used to derive environment variable names
+// from flag names. It implies WithAutoEnv.
+//
+//	clibind.WithEnvNameFunc(func(flag string) string { return "APP_" + clibind.EnvName(flag) })
+func WithEnvNameFunc(fn func(flag string) string) Option {
+	return func(o *options) {
+		o.autoEnv = true
+		o.envName = fn
+	}
+}
+
+// WithPrefixCategories groups the flags of every struct field carrying a cliPrefix
+// under a help category derived from the field name ("Database" becomes
+// "Database options"). An explicit cliCategory tag always takes precedence.
+func WithPrefixCategories() Option {
+	return func(o *options) {
+		o.prefixCategories = true
+	}
+}
+
+// WithZeroDefaults treats a field without cliDefault as defaulting to its type's zero
+// value, so the generated flag is optional instead of required.
+func WithZeroDefaults() Option {
+	return func(o *options) {
+		o.zeroDefaults = true
+	}
+}
+
+// FlagOrder controls the order of the flags returned by FlagsFromStruct.
+type FlagOrder int
+
+const (
+	// OrderDeclaration keeps the field declaration order, nested structs expanded in place. This is the default.
+	OrderDeclaration FlagOrder = iota
+	// OrderAlphabetical sorts flags by their primary name.
+	OrderAlphabetical
+	// OrderCategory groups flags by category name, uncategorized flags first,
+	// keeping declaration order within a category.
+	OrderCategory
+)
+
+// WithFlagOrder sets the order of the generated flags.
+func WithFlagOrder(order FlagOrder) Option {
+	return func(o *options) {
+		o.order = order
+	}
+}
+
+// WithCategoryOrder sets the display order of flag categories, e.g.
+// WithCategoryOrder("", "Server", "Database", "Advanced") where "" stands for
+// uncategorized flags. It implies OrderCategory; categories not listed follow
+// alphabetically. CommandWithBinding also records the order for HelpPrinter.
+func WithCategoryOrder(categories ...string) Option {
+	return func(o *options) {
+		o.order = OrderCategory
+		o.categoryOrder = categories
+	}
+}
+
+// MergePolicy decides what FlagsFromStructs does when several structs define a flag with the same name.
+type MergePolicy int
+
+const (
+	// MergeError rejects any flag name or alias defined more than once. This is the default.
+	MergeError MergePolicy = iota
+	// MergeFirstWins keeps the first definition and drops later flags sharing one of its names.
+	MergeFirstWins
+	// MergeRequireIdentical keeps a single copy of flags defined identically by several
+	// structs (e.g. a shared --verbose) and rejects differing definitions.
+	MergeRequireIdentical
+)
+
+// WithMergePolicy sets how FlagsFromStructs resolves flags defined by several structs.
+func WithMergePolicy(p MergePolicy) Option {
+	return func(o *options) {
+		o.mergePolicy = p
+	}
+}
+
+// EnvName is the default environment variable naming strategy. It upper-cases the
+// flag name and converts dashes and dots to underscores, so a "host" field under
+// cliPrefix:"db-" becomes DB_HOST.
+func EnvName(flag string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(flag))
+}
+
+// envSources returns the environment variables attached to the flag with the
+// given (prefixed) name: those of its cliEnv tag, else the derived one.
+func (o *options) envSources(name string, sf reflect.StructField) cli.ValueSourceChain {
+	if vars := splitCSV(sf.Tag.Get(tagCLIEnv)); len(vars) > 0 {
+		return cli.EnvVars(vars...)
+	}
+	if !o.autoEnv {
+		return cli.ValueSourceChain{}
+	}
+	return cli.EnvVars(o.envPrefix + o.envName(name))
+}
+
+// WithSecretSource makes every secret field (see Secret and the cliSecret tag) also
+// look its value up in the source fn returns for the field's full flag name; fn may
+// return nil to skip a flag. Sources are consulted after environment variables and
+// in the order the options are given, so several secret stores can be chained.
+// Subpackages such as keychain provide ready-made sources.
+func WithSecretSource(fn func(flag string) cli.ValueSource) Option {
+	return func(o *options) {
+		o.secretSources = append(o.secretSources, fn)
+	}
+}
+
+// WithSourceTimeout bounds how long a single source may take to resolve a value:
+// a provider reference (cliSources, secret references), a WithSecretSource
+// lookup or a ValueSource lookup. A source that times out counts as failed, is
+// recorded in the Provenance, and the next source of the chain is tried. Given
+// schemes, d only applies to references of those schemes and to the sources
+// whose Scheme method returns one of them; otherwise it is the default for all
+// sources.
+func WithSourceTimeout(d time.Duration, schemes ...string) Option {
+	return func(o *options) {
+		if len(schemes) == 0 {
+			o.defaultTimeout = d
+			return
+		}
+		if o.schemeTimeouts == nil {
+			o.schemeTimeouts = map[string]time.Duration{}
+		}
+		for _, s := range schemes {
+			o.schemeTimeouts[s] = d
+		}
+	}
+}
+
+func (o *options) sourceTimeout(scheme string) time.Duration {
+	if d, ok := o.schemeTimeouts[scheme]; ok {
+		return d
+	}
+	return o.defaultTimeout
+}
+
+// sources returns the value sources of the flag generated for sf: its environment
+// variable, the configuration file, and the secret sources for secret fields.
+func (o *options) sources(name string, sf reflect.StructField) cli.ValueSourceChain {
+	chain := o.envSources(name, sf)
+	if o.flagsJSON != nil {
+		chain = cli.NewValueSourceChain(append([]cli.ValueSource{&flagsJSONSource{fj: o.flagsJSON, key: name}}, chain.Chain...)...)
+	}
+	if o.configJSON != nil {
+		chain = cli.NewValueSourceChain(append([]cli.ValueSource{&flagsJSONSource{fj: o.configJSON, key: name}}, chain.Chain...)...)
+	}
+	if o.dotenv != nil {
+		for _, key := range chain.EnvKeys() {
+			chain.Append(cli.NewValueSourceChain(&dotenvSource{file: o.dotenv, key: key}))
+		}
+	}
+	if o.config != nil {
+		chain.Append(cli.NewValueSourceChain(o.config.source(name)))
+	}
+	if !isSecretTagged(sf) {
+		return chain
+	}
+	for _, fn := range o.secretSources {
+		if src := fn(name); src != nil {
+			if o.sourceTimeout(sourceScheme(src)) > 0 {
+				src = &timeoutSource{ValueSource: src, o: o, flag: name}
+			}
+			chain.Append(cli.NewValueSourceChain(src))
+		}
+	}
+	return chain
+}
+
+// nestedCategory returns the help category of the flags generated for the nested struct field sf.
+func (o *options) nestedCategory(sf reflect.StructField, inherited string) string {
+	if c := sf.Tag.Get(tagCLICategory); c != "" {
+		return c
+	}
+	if o.prefixCategories && sf.Tag.Get(tagCLIPrefix) != "" {
+		return humanizeFieldName(sf.Name) + " options"
+	}
+	return inherited
+}
+
+// timeoutSource looks a WithSecretSource source up through callResolver, within
+// the timeout of its scheme, so a slow store cannot hang the CLI: the rest of
+// the chain (and the default) still apply. The lookup runs once per run, its
+// outcome kept for the later Lookups of the chain and for sourceFailures until
+// resetSources ends the run; a lookup outliving its timeout thus leaves a single
+// goroutine behind, which ends when the source returns.
+type timeoutSource struct {
+	cli.ValueSource
+	o    *options
+	flag string
+
+	mu     sync.Mutex
+	looked bool
+	v      string
+	err    error
+}
+
+func (s *timeoutSource) Lookup() (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.looked {
+		s.v, s.err = s.o.callResolver(context.Background(), sourceScheme(s.ValueSource), s.flag, func(context.Context, string) (string, error) {
+			v, ok := s.ValueSource.Lookup()
+			if !ok {
+				return "", errNoValue
+			}
+			return v, nil
+		})
+		s.looked = true
+	}
+	return s.v, s.err == nil
+}
+
+// failure returns the error of the lookup of this run, if it failed.
+func (s *timeoutSource) failure() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if errors.Is(s.err, errNoValue) {
+		return nil
+	}
+	return s.err
+}
+
+// resetSources forgets the lookups of the WithSecretSource sources of the
+// flags of c, so that the next run of c looks them up again.
+func resetSources(c *cli.Command) {
+	for _, fl := range c.Flags {
+		for _, src := range flagSourceChain(fl) {
+			if s, ok := src.(*timeoutSource); ok {
+				s.mu.Lock()
+				s.looked, s.v, s.err = false, "", nil
+				s.mu.Unlock()
+			}
+		}
+	}
+}
+
+// sourceScheme returns the scheme whose WithSourceTimeout applies to src: that
+// of its Scheme method, if it has one.
+func sourceScheme(src any) string {
+	if s, ok := src.(interface{ Scheme() string }); ok {
+		return s.Scheme()
+	}
+	return ""
+}
+
+// sourceFailures returns the lookups of the WithSecretSource sources of the
+// flags of c that timed out.
+func sourceFailures(c *cli.Command) []SourceFailure {
+	var failures []SourceFailure
+	for _, fl := range c.Flags {
+		for _, src := range flagSourceChain(fl) {
+			if s, ok := src.(*timeoutSource); ok {
+				if err := s.failure(); err != nil {
+					failures = append(failures, SourceFailure{Flag: s.flag, Source: s.String(), Err: err})
+				}
+			}
+		}
+	}
+	return failures
+}
@@ -0,0 +1,116 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// parser is a named converter, see RegisterParser.
+type parser struct {
+	typ   reflect.Type
+	parse func(s string) (reflect.Value, error)
+}
+
+var (
+	parsersMu sync.RWMutex
+	parsers   = map[string]parser{}
+)
+
+// RegisterParser makes parse available to cliParser tags as name, so fields of
+// the same Go type can be parsed and validated differently:
+//
+//	clibind.RegisterParser("hexcolor", func(s string) (string, error) {
+//	    if !hexColor.MatchString(s) {
+//	        return "", fmt.Errorf("%q is not a #rrggbb color", s)
+//	    }
+//	    return strings.ToLower(s), nil
+//	})
+//
+//	type Theme struct {
+//	    Accent string `cli:"accent" cliParser:"hexcolor"`
+//	    Name   string `cli:"name"`
+//	}
+//
+// The field takes a string flag and parse replaces the built-in parsing of T
+// for its value, for each element of a []T field and for each value of a map
+// with T values; map keys parse as usual. An empty value leaves the field zero.
+// Values are printed as T normally is, so parse should accept what it prints.
+// A tag naming no parser, or one for another type, makes flag generation
+// panic. Registering a name again replaces the parser; it fails after Freeze.
+func RegisterParser[T any](name string, parse func(string) (T, error)) error {
+	t := reflect.TypeFor[T]()
+	if parse == nil {
+		panic(fmt.Sprintf("clibind: RegisterParser(%q): nil parse func", name))
+	}
+	p := parser{typ: t, parse: func(s string) (reflect.Value, error) {
+		v, err := parse(s)
+		return reflect.ValueOf(&v).Elem(), err
+	}}
+	parsersMu.Lock()
+	defer parsersMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterParser[%s](%q)", t, name)); err != nil {
+		return err
+	}
+	parsers[name] = p
+	return nil
+}
+
+// fieldParser returns the cliParser of sf if it parses values of type t.
+func fieldParser(sf reflect.StructField, t reflect.Type) (parser, bool) {
+	name := sf.Tag.Get(tagCLIParser)
+	if name == "" {
+		return parser{}, false
+	}
+	parsersMu.RLock()
+	defer parsersMu.RUnlock()
+	p, ok := parsers[name]
+	return p, ok && p.typ == t
+}
+
+// checkParser reports a cliParser tag of sf naming no registered parser, or a
+// parser of values of another type than those of sf.
+func checkParser(sf reflect.StructField) error {
+	name := sf.Tag.Get(tagCLIParser)
+	if name == "" {
+		return nil
+	}
+	parsersMu.RLock()
+	p, ok := parsers[name]
+	parsersMu.RUnlock()
+	if !ok {
+		return fmt.Errorf("field %s: cliParser %q is not registered, see RegisterParser", sf.Name, name)
+	}
+	t := unreferenceType(sf.Type)
+	if !isCustomType(t) {
+		switch {
+		case t.Kind() == reflect.Slice:
+			t = t.Elem()
+		case t.Kind() == reflect.Map && t.Elem().Kind() == reflect.Slice && !isCustomType(t.Elem()):
+			t = t.Elem().Elem()
+		case t.Kind() == reflect.Map:
+			t = t.Elem()
+		}
+	}
+	if p.typ != t {
+		return fmt.Errorf("field %s: cliParser %q parses %s values, not %s", sf.Name, name, p.typ, t)
+	}
+	return nil
+}
+
+func hasParser(sf reflect.StructField, t reflect.Type) bool {
+	_, ok := fieldParser(sf, t)
+	return ok
+}
+
+// parseValue parses s into a new value of type t for the field sf, with its
+// cliParser if it has one for t.
+func parseValue(s string, t reflect.Type, sf reflect.StructField) (reflect.Value, error) {
+	if p, ok := fieldParser(sf, t); ok {
+		if s == "" {
+			return reflect.New(t).Elem(), nil
+		}
+		return p.parse(s)
+	}
+	return parseScalar(s, t, sf)
+}
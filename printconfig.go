@@ -0,0 +1,112 @@
+package clibind
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/urfave/cli/v3"
+)
+
+const (
+	flagPrintConfig = "print-config"
+	flagShowSecrets = "show-secrets"
+)
+
+// WithPrintConfig makes CommandWithBinding add a --print-config flag that prints
+// the bound configuration (see WriteConfig) instead of running the handler.
+// Secrets are masked unless --show-secrets is given as well.
+func WithPrintConfig() Option {
+	return func(o *options) {
+		o.printConfig = true
+	}
+}
+
+func printConfigFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.BoolFlag{Name: flagPrintConfig, Usage: "print the resolved configuration and exit"},
+		&cli.BoolFlag{Name: flagShowSecrets, Usage: "reveal secret values in --" + flagPrintConfig},
+	}
+}
+
+// WriteConfig writes cfg, a struct or a pointer to one, as "flag=value" lines in
+// field declaration order, using the flag names FlagsFromStruct generates. Fields
+// typed Secret[T] or tagged cliSecret:"true" are written as "[redacted]" unless
+// showSecrets is true. Unset pointer fields are written with an empty value.
+func WriteConfig(w io.Writer, cfg any, showSecrets bool) error {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return fmt.Errorf("WriteConfig: %T is not a struct", cfg)
+	}
+	var err error
+	walkLeaves(v, "", func(name string, sf reflect.StructField, fv reflect.Value) bool {
+		_, err = fmt.Fprintf(w, "%s=%s\n", name, formatField(sf, fv, showSecrets))
+		return err == nil
+	})
+	return err
+}
+
+// formatField renders a bound field the way it would be written on the command line.
+func formatField(sf reflect.StructField, fv reflect.Value, showSecrets bool) string {
+	for fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return ""
+		}
+		fv = fv.Elem()
+	}
+	if !showSecrets && isSecretTagged(sf) && !fv.IsZero() {
+		return redacted
+	}
+	switch {
+	case isCustomType(fv.Type()):
+	case fv.Kind() == reflect.Slice:
+		parts := make([]string, fv.Len())
+		for i := range parts {
+			parts[i] = formatScalar(fv.Index(i), sf, showSecrets)
+		}
+		return strings.Join(parts, ",")
+	case fv.Kind() == reflect.Map:
+		var parts []string
+		for _, k := range fv.MapKeys() {
+			val := fv.MapIndex(k)
+			if val.Kind() != reflect.Slice || isCustomType(val.Type()) {
+				parts = append(parts, formatScalar(k, sf, showSecrets)+"="+formatScalar(val, sf, showSecrets))
+				continue
+			}
+			vals := make([]string, val.Len())
+			for i := range vals {
+				vals[i] = formatScalar(val.Index(i), sf, showSecrets)
+			}
+			parts = append(parts, formatScalar(k, sf, showSecrets)+"="+strings.Join(vals, ";"))
+		}
+		slices.Sort(parts)
+		return strings.Join(parts, ",")
+	}
+	return formatScalar(fv, sf, showSecrets)
+}
+
+func formatScalar(v reflect.Value, sf reflect.StructField, showSecrets bool) string {
+	if c, ok := registeredType(v.Type()); ok {
+		return c.format(v)
+	}
+	switch {
+	case isFlagValue(v.Type()):
+		return formatFlagValue(v)
+	case isSecret(v.Type()):
+		s := v.Interface().(secretValue)
+		if showSecrets {
+			return s.reveal()
+		}
+		return s.String()
+	case v.Type() == reflect.TypeOf(time.Time{}):
+		layout, _, _ := strings.Cut(sf.Tag.Get(tagCLITimeFmt), "|")
+		if layout == "" {
+			layout = defaultTimeFmt
+		}
+		return v.Interface().(time.Time).Format(layout)
+	}
+	return fmt.Sprint(v.Interface())
+}
@@ -0,0 +1,40 @@
+package clibind
+
+import (
+	"context"
+	"fmt"
+)
+
+// Provenance records where a bound configuration came from. WithBinding stores it
+// in the handler's context, see ProvenanceFromContext.
+type Provenance struct {
+	// Failures lists every source that failed or timed out while resolving
+	// cliSources, value sources and WithSecretSource sources, including those
+	// a later source made up for.
+	Failures []SourceFailure
+	// Resolved maps the flags filled from a cliSources reference, a value
+	// source or --set (see WithSetFlag) to where the value came from.
+	Resolved map[string]string
+}
+
+// SourceFailure is a source that could not provide a flag's value.
+type SourceFailure struct {
+	Flag   string
+	Source string
+	Err    error
+}
+
+func (f SourceFailure) Error() string {
+	return fmt.Sprintf("flag %s: source %s: %v", f.Flag, f.Source, f.Err)
+}
+
+func (f SourceFailure) Unwrap() error { return f.Err }
+
+type provenanceKey struct{}
+
+// ProvenanceFromContext returns the Provenance of the configuration bound by
+// WithBinding. ok is false outside of a WithBinding handler.
+func ProvenanceFromContext(ctx context.Context) (p *Provenance, ok bool) {
+	p, ok = ctx.Value(provenanceKey{}).(*Provenance)
+	return p, ok
+}
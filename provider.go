@@ -0,0 +1,55 @@
+package clibind
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// A Provider resolves scheme://... references, e.g. to secrets kept in a vault.
+// Providers back the cliSources tag and the references held by secret fields
+// (see WithSecretResolver).
+type Provider interface {
+	// Scheme returns the reference scheme handled, without "://".
+	Scheme() string
+	// Resolve returns the value ref refers to.
+	Resolve(ctx context.Context, ref string) (string, error)
+}
+
+var (
+	providersMu sync.RWMutex
+	providers   = map[string]Provider{}
+)
+
+// RegisterProvider makes p available to every command for its scheme, replacing
+// any provider registered before for the same scheme. It is typically called from
+// an init function of the package implementing p. Resolvers passed to a command
+// with WithProvider or WithSecretResolver take precedence. It fails after Freeze.
+func RegisterProvider(p Provider) error {
+	providersMu.Lock()
+	defer providersMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterProvider(%q)", p.Scheme())); err != nil {
+		return err
+	}
+	providers[p.Scheme()] = p
+	return nil
+}
+
+// WithProvider registers p for the command only.
+func WithProvider(p Provider) Option {
+	return WithSecretResolver(p.Scheme(), p.Resolve)
+}
+
+// resolver returns the resolver of scheme, looking at the command's own
+// resolvers before the registry.
+func (o *options) resolver(scheme string) (SecretResolver, bool) {
+	if r, ok := o.resolvers[scheme]; ok {
+		return r, true
+	}
+	providersMu.RLock()
+	defer providersMu.RUnlock()
+	if p, ok := providers[scheme]; ok {
+		return p.Resolve, true
+	}
+	return nil, false
+}
@@ -0,0 +1,139 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+// stubProvider resolves scheme://key references from a map, after a delay it
+// sits out without looking at the context.
+type stubProvider struct {
+	scheme string
+	delay  time.Duration
+	values map[string]string
+}
+
+func (p stubProvider) Scheme() string { return p.scheme }
+
+func (p stubProvider) Resolve(_ context.Context, ref string) (string, error) {
+	time.Sleep(p.delay)
+	v, ok := p.values[strings.TrimPrefix(ref, p.scheme+"://")]
+	if !ok {
+		return "", errors.New("not found")
+	}
+	return v, nil
+}
+
+type providerConfig struct {
+	Host     string                 `cli:"host" cliSources:"provslow://host,provfast://host"`
+	Port     int                    `cli:"port" cliDefault:"80"`
+	Password clibind.Secret[string] `cli:"password,omitempty"`
+}
+
+func newProviderRoot(prov **clibind.Provenance, opts ...clibind.Option) *cli.Command {
+	return &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			clibind.CommandWithBinding(nil, "serve", func(ctx context.Context, _ providerConfig) error {
+				*prov, _ = clibind.ProvenanceFromContext(ctx)
+				return nil
+			}, opts...),
+		},
+	}
+}
+
+func TestProviderTimeout(t *testing.T) {
+	var prov *clibind.Provenance
+	opts := []clibind.Option{
+		clibind.WithProvider(stubProvider{"provslow", time.Second, map[string]string{"host": "slow.internal", "pw": "late"}}),
+		clibind.WithProvider(stubProvider{"provfast", 0, map[string]string{"host": "fast.internal", "pw": "s3cr3t"}}),
+		clibind.WithSourceTimeout(10*time.Millisecond, "provslow"),
+	}
+	start := time.Now()
+	res := clibindtest.Run(t, newProviderRoot(&prov, opts...), clibindtest.Input{
+		Args: []string{"serve", "--password", "provfast://pw"},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("took %s, want the 10ms timeout of provslow", d)
+	}
+	cfg := clibindtest.Bound[providerConfig](t, res, "app serve")
+	if cfg.Host != "fast.internal" || cfg.Password.Value() != "s3cr3t" {
+		t.Errorf("bound host %q, password %q; want fast.internal, s3cr3t", cfg.Host, cfg.Password.Value())
+	}
+	if prov == nil || prov.Resolved["host"] != "provfast://host" || len(prov.Resolved) != 1 {
+		t.Errorf("resolved = %+v, want host from provfast://host", prov)
+	}
+	if len(prov.Failures) != 1 || prov.Failures[0].Flag != "host" || prov.Failures[0].Source != "provslow://host" ||
+		prov.Failures[0].Err.Error() != "timed out after 10ms" {
+		t.Errorf("failures = %+v, want provslow://host timing out for --host", prov.Failures)
+	}
+
+	// a secret reference times out too, and has no source to fall back on
+	res = clibindtest.Run(t, newProviderRoot(&prov, opts...), clibindtest.Input{
+		Args: []string{"serve", "--password", "provslow://pw"},
+	})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "flag password: resolve provslow://pw: timed out after 10ms") {
+		t.Errorf("err = %v, want the secret reference timing out", res.Err)
+	}
+}
+
+func TestProviderFailures(t *testing.T) {
+	var prov *clibind.Provenance
+	res := clibindtest.Run(t, newProviderRoot(&prov,
+		clibind.WithProvider(stubProvider{"provslow", 0, nil}),
+		clibind.WithProvider(stubProvider{"provfast", 0, nil}),
+	), clibindtest.Input{Args: []string{"serve"}})
+	var failure clibind.SourceFailure
+	if !errors.As(res.Err, &failure) || failure.Flag != "host" || failure.Err.Error() != "not found" {
+		t.Errorf("err = %v, want a SourceFailure of --host", res.Err)
+	}
+	if len(res.Calls) != 0 {
+		t.Error("the handler ran without a host")
+	}
+
+	// the command line leaves the sources alone
+	res = clibindtest.Run(t, newProviderRoot(&prov), clibindtest.Input{Args: []string{"serve", "--host", "h"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if len(prov.Resolved) != 0 || len(prov.Failures) != 0 {
+		t.Errorf("provenance = %+v, want nothing resolved", prov)
+	}
+}
+
+func TestRegisteredProvider(t *testing.T) {
+	if err := clibind.RegisterProvider(stubProvider{"provreg", 0, map[string]string{"pw": "registered"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range []struct {
+		name string
+		opts []clibind.Option
+		want string
+	}{
+		{"registry", nil, "registered"},
+		{"command resolver first", []clibind.Option{clibind.WithSecretResolver("provreg", func(context.Context, string) (string, error) {
+			return "own", nil
+		})}, "own"},
+	} {
+		var prov *clibind.Provenance
+		res := clibindtest.Run(t, newProviderRoot(&prov, c.opts...), clibindtest.Input{
+			Args: []string{"serve", "--host", "h", "--password", "provreg://pw"},
+		})
+		if res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if got := clibindtest.Bound[providerConfig](t, res, "app serve").Password.Value(); got != c.want {
+			t.Errorf("%s: password %q, want %q", c.name, got, c.want)
+		}
+	}
+}
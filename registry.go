@@ -0,0 +1,31 @@
+package clibind
+
+import (
+	"errors"
+	"fmt"
+	"sync/atomic"
+)
+
+// ErrFrozen is wrapped by the errors of the Register functions once Freeze has
+// been called.
+var ErrFrozen = errors.New("registries are frozen")
+
+var frozen atomic.Bool
+
+// Freeze locks every global registry of the package: providers, factories,
+// completers, default variables, value sources, types, parsers, post-bind
+// hooks, migrations and field docs. Call it at the start of main, once init
+// functions have registered everything; later registrations fail with an error
+// wrapping ErrFrozen instead of changing what commands already running see. The
+// registries stay safe for concurrent use either way.
+func Freeze() {
+	frozen.Store(true)
+}
+
+// checkFrozen returns the error of a late call to the registration what.
+func checkFrozen(what string) error {
+	if frozen.Load() {
+		return fmt.Errorf("clibind: %s: %w", what, ErrFrozen)
+	}
+	return nil
+}
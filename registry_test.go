@@ -0,0 +1,74 @@
+package clibind_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type frozenConfig struct {
+	Host string `cli:"host" cliDefault:"localhost"`
+}
+
+func TestRegistriesAreConcurrent(t *testing.T) {
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			clibind.RegisterCompleter("concurrent", func(context.Context) []string { return nil })
+			clibind.RegisterFieldDocs[frozenConfig](map[string]string{"Host": "the host"})
+		}()
+		go func() {
+			defer wg.Done()
+			clibind.FlagsFromStruct(&frozenConfig{})
+		}()
+	}
+	wg.Wait()
+}
+
+// TestFreeze runs in a child process, as Freeze cannot be undone.
+func TestFreeze(t *testing.T) {
+	if os.Getenv("CLIBIND_TEST_FREEZE") == "" {
+		cmd := exec.Command(os.Args[0], "-test.run=^TestFreeze$")
+		cmd.Env = append(os.Environ(), "CLIBIND_TEST_FREEZE=1")
+		if out, err := cmd.CombinedOutput(); err != nil {
+			t.Fatalf("%v:\n%s", err, out)
+		}
+		return
+	}
+
+	clibind.Freeze()
+	for _, c := range []struct {
+		what string
+		err  error
+	}{
+		{`RegisterProvider("frozen")`, clibind.RegisterProvider(stubProvider{scheme: "frozen"})},
+		{`RegisterFactory[clibind_test.storage]("frozen")`, clibind.RegisterFactory("frozen", func(c diskStorage) (storage, error) { return c, nil })},
+		{`RegisterCompleter("frozen")`, clibind.RegisterCompleter("frozen", func(context.Context) []string { return nil })},
+		{`RegisterDefaultVar("frozen")`, clibind.RegisterDefaultVar("frozen", new(string))},
+		{`RegisterValueSource(map)`, clibind.RegisterValueSource(mapSource{})},
+		{`RegisterType[clibind_test.color]`, clibind.RegisterType(parseColor, nil)},
+		{`RegisterParser[string]("frozen")`, clibind.RegisterParser("frozen", func(s string) (string, error) { return s, nil })},
+		{`RegisterPostBind[clibind_test.frozenConfig]`, clibind.RegisterPostBind(func(*frozenConfig) error { return nil })},
+		{`RegisterMigration[clibind_test.frozenConfig](1)`, clibind.RegisterMigration[frozenConfig](1, func(map[string]any) error { return nil })},
+		{`RegisterFieldDocs[clibind_test.frozenConfig]`, clibind.RegisterFieldDocs[frozenConfig](nil)},
+	} {
+		if !errors.Is(c.err, clibind.ErrFrozen) || !strings.Contains(c.err.Error(), c.what) {
+			t.Errorf("%s: err = %v, want ErrFrozen", c.what, c.err)
+		}
+	}
+
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, frozenConfig) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--host", "h"}})
+	if res.Err != nil || clibindtest.Bound[frozenConfig](t, res, "app").Host != "h" {
+		t.Errorf("binding after Freeze: %v", res.Err)
+	}
+}
@@ -0,0 +1,155 @@
+package clibind
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// remoteClient fetches https:// configuration files.
+var remoteClient = &http.Client{Timeout: 30 * time.Second}
+
+// maxRemoteConfig bounds the size of a downloaded configuration file.
+const maxRemoteConfig = 10 << 20
+
+// remoteMeta is stored next to a cached remote configuration file.
+type remoteMeta struct {
+	URL          string `json:"url"`
+	ETag         string `json:"etag,omitempty"`
+	LastModified string `json:"lastModified,omitempty"`
+}
+
+// fetchRemote downloads the https:// configuration file (or its signature) at
+// url, checking the Content-Type of the response with checkType unless nil.
+// Responses are cached in the user cache directory and revalidated with
+// If-None-Match and If-Modified-Since on later runs. When the server cannot be
+// reached or fails, the cached copy is used so retries keep working offline.
+func fetchRemote(url string, checkType func(string) error) ([]byte, error) {
+	cachePath, meta := remoteCache(url)
+	cached, cacheErr := os.ReadFile(cachePath)
+
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json, application/yaml;q=0.9, application/toml;q=0.9")
+	if cacheErr == nil {
+		if meta.ETag != "" {
+			req.Header.Set("If-None-Match", meta.ETag)
+		}
+		if meta.LastModified != "" {
+			req.Header.Set("If-Modified-Since", meta.LastModified)
+		}
+	}
+	resp, err := remoteClient.Do(req)
+	if err != nil {
+		if cacheErr == nil {
+			return cached, nil
+		}
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified && cacheErr == nil:
+		return cached, nil
+	case resp.StatusCode >= 500 && cacheErr == nil:
+		return cached, nil
+	case resp.StatusCode != http.StatusOK:
+		return nil, fmt.Errorf("GET: %s", resp.Status)
+	}
+	if checkType != nil {
+		if err := checkType(resp.Header.Get("Content-Type")); err != nil {
+			return nil, err
+		}
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxRemoteConfig+1))
+	if err != nil {
+		return nil, fmt.Errorf("GET: %w", err)
+	}
+	if len(data) > maxRemoteConfig {
+		return nil, fmt.Errorf("larger than %d bytes", maxRemoteConfig)
+	}
+	storeRemoteCache(cachePath, data, remoteMeta{
+		URL:          url,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	})
+	return data, nil
+}
+
+// checkConfigContentType accepts JSON, YAML, TOML and plain text (as served by raw
+// file hosts), and rejects anything else, typically the HTML of a login page.
+func checkConfigContentType(ct string) error {
+	if ct == "" {
+		return nil
+	}
+	mt, _, err := mime.ParseMediaType(ct)
+	if err != nil {
+		return fmt.Errorf("content type %q: %w", ct, err)
+	}
+	switch {
+	case mt == "application/json", mt == "text/json", mt == "text/plain", strings.HasSuffix(mt, "+json"):
+		return nil
+	case mt == "application/yaml", mt == "application/x-yaml", mt == "text/yaml", mt == "text/x-yaml", strings.HasSuffix(mt, "+yaml"):
+		return nil
+	case mt == "application/toml", strings.HasSuffix(mt, "+toml"):
+		return nil
+	}
+	return fmt.Errorf("unexpected content type %q, want JSON, YAML or TOML", mt)
+}
+
+// remoteCache returns where url is cached and the metadata of the cached copy.
+func remoteCache(url string) (string, remoteMeta) {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		dir = os.TempDir()
+	}
+	sum := sha256.Sum256([]byte(url))
+	path := filepath.Join(dir, "clibind", "config", hex.EncodeToString(sum[:16]))
+	var meta remoteMeta
+	if data, err := os.ReadFile(path + ".meta"); err == nil {
+		_ = json.Unmarshal(data, &meta)
+	}
+	if meta.URL != url {
+		meta = remoteMeta{} // not ours, don't revalidate against it
+	}
+	return path, meta
+}
+
+// storeRemoteCache writes the cache entry atomically. Failing to cache is not an
+// error: the next run just downloads the file again.
+func storeRemoteCache(path string, data []byte, meta remoteMeta) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return
+	}
+	m, _ := json.Marshal(meta)
+	if writeFileAtomic(path, data) == nil {
+		_ = writeFileAtomic(path+".meta", m)
+	}
+}
+
+func writeFileAtomic(path string, data []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(data)
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+	}
+	return err
+}
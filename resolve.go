@@ -0,0 +1,209 @@
+package clibind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// A SecretResolver returns the secret a reference such as
+// gcp-sm://projects/p/secrets/db points to.
+type SecretResolver func(ctx context.Context, ref string) (string, error)
+
+// WithSecretResolver makes WithBinding resolve the scheme://... references of
+// cliSources tags (see Provider), and those held by secret fields (see Secret and
+// the cliSecret tag): the handler sees what resolve returns instead of the
+// reference. Secret references may come from flags, environment variables or
+// cliDefault alike. Each failure names the flag whose reference could not be
+// resolved.
+func WithSecretResolver(scheme string, resolve SecretResolver) Option {
+	return func(o *options) {
+		if o.resolvers == nil {
+			o.resolvers = map[string]SecretResolver{}
+		}
+		o.resolvers[scheme] = resolve
+	}
+}
+
+// resolveBound completes the configuration bind populated into cfg the way
+// WithBinding does: the sources of unset flags, then --set overrides and secret
+// references, and the post-bind hooks last, so they see the final values.
+func resolveBound(ctx context.Context, c *cli.Command, cfg any, o *options, prov *Provenance) error {
+	if err := resolveSources(ctx, c, cfg, o, prov); err != nil {
+		return err
+	}
+	if err := applySets(c, cfg, prov); err != nil {
+		return fmt.Errorf("%w: %w", ErrBind, err)
+	}
+	if err := resolveSecrets(ctx, cfg, o); err != nil {
+		return err
+	}
+	if err := runPostBind(reflect.ValueOf(cfg).Elem()); err != nil {
+		return fmt.Errorf("%w: %w", ErrBind, err)
+	}
+	return nil
+}
+
+// resolveSources fills the fields of cfg whose flag was not set from the
+// references listed in their cliSources tag, then from the value sources of the
+// command (see WithValueSource), the first that has a value winning. Every
+// failure is recorded in prov, but only reported when no source had a value.
+func resolveSources(ctx context.Context, c *cli.Command, cfg any, o *options, prov *Provenance) error {
+	var err error
+	srcs := o.allValueSources()
+	required := o.sourcedRequired(reflect.TypeOf(cfg).Elem())
+	walkLeaves(reflect.ValueOf(cfg).Elem(), "", func(name string, sf reflect.StructField, fv reflect.Value) bool {
+		if c.IsSet(name) {
+			return true
+		}
+		var errs []error
+		// try sets the field to the value s got from source, unless getting it failed
+		try := func(source, s string, terr error) bool {
+			if terr == nil {
+				terr = setFieldFromString(s, sf, allocReferenced(fv))
+			}
+			if terr == nil {
+				if prov.Resolved == nil {
+					prov.Resolved = map[string]string{}
+				}
+				prov.Resolved[name] = source
+				return true
+			}
+			failure := SourceFailure{Flag: name, Source: source, Err: terr}
+			prov.Failures = append(prov.Failures, failure)
+			errs = append(errs, failure)
+			return false
+		}
+		for _, ref := range splitCSV(sf.Tag.Get(tagCLISources)) {
+			s, rerr := resolveRef(ctx, ref, o)
+			if try(ref, s, rerr) {
+				return true
+			}
+		}
+		if !isEnvOnly(sf) && factoriesOf(sf.Type) == nil {
+			for _, src := range srcs {
+				s, ok, lerr := o.lookupValue(ctx, src, name)
+				if (ok || lerr != nil) && try(src.String(), s, lerr) {
+					return true
+				}
+			}
+		}
+		if required[name] && len(errs) == 0 {
+			errs = append(errs, fmt.Errorf("flag --%s not set, and no value source has it", name))
+		}
+		err = errors.Join(errs...)
+		return err == nil
+	})
+	return err
+}
+
+// resolveRef resolves ref with the provider of its scheme.
+func resolveRef(ctx context.Context, ref string, o *options) (string, error) {
+	scheme, _, ok := strings.Cut(ref, "://")
+	if !ok {
+		return "", errors.New("not a scheme://... reference")
+	}
+	resolve, ok := o.resolver(scheme)
+	if !ok {
+		return "", fmt.Errorf("no provider registered for scheme %q", scheme)
+	}
+	return o.callResolver(ctx, scheme, ref, resolve)
+}
+
+// callResolver runs resolve, giving up after the timeout configured for scheme
+// even if resolve does not honor ctx.
+func (o *options) callResolver(ctx context.Context, scheme, ref string, resolve SecretResolver) (string, error) {
+	d := o.sourceTimeout(scheme)
+	if d <= 0 {
+		return resolve(ctx, ref)
+	}
+	ctx, cancel := context.WithTimeout(ctx, d)
+	defer cancel()
+	type result struct {
+		s   string
+		err error
+	}
+	done := make(chan result, 1)
+	go func() {
+		s, err := resolve(ctx, ref)
+		done <- result{s, err}
+	}()
+	select {
+	case r := <-done:
+		return r.s, r.err
+	case <-ctx.Done():
+		return "", fmt.Errorf("timed out after %s", d)
+	}
+}
+
+// resolveSecrets replaces the secret references in the bound struct cfg points to.
+func resolveSecrets(ctx context.Context, cfg any, o *options) error {
+	var err error
+	walkLeaves(reflect.ValueOf(cfg).Elem(), "", func(name string, sf reflect.StructField, fv reflect.Value) bool {
+		if !isSecretTagged(sf) {
+			return true
+		}
+		fv = reflect.Indirect(fv)
+		if fv.Kind() == reflect.Slice {
+			for i := 0; i < fv.Len() && err == nil; i++ {
+				err = resolveSecret(ctx, fv.Index(i), o)
+			}
+		} else if fv.IsValid() {
+			err = resolveSecret(ctx, fv, o)
+		}
+		if err != nil {
+			err = fmt.Errorf("flag %s: %w", name, err)
+		}
+		return err == nil
+	})
+	return err
+}
+
+// resolveSecret resolves the reference held by the string or Secret value v, if any.
+func resolveSecret(ctx context.Context, v reflect.Value, o *options) error {
+	var ref string
+	switch {
+	case isSecret(v.Type()):
+		ref = v.Interface().(secretValue).reveal()
+	case v.Kind() == reflect.String:
+		ref = v.String()
+	default:
+		return nil
+	}
+	scheme, ok := refScheme(ref)
+	if !ok {
+		return nil
+	}
+	resolve, ok := o.resolver(scheme)
+	if !ok {
+		return nil
+	}
+	s, err := o.callResolver(ctx, scheme, ref, resolve)
+	if err != nil {
+		return fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	if isSecret(v.Type()) {
+		v.Addr().Interface().(secretSetter).setSecret(s)
+	} else {
+		v.SetString(s)
+	}
+	return nil
+}
+
+// ageArmor starts an ASCII-armored age payload.
+const ageArmor = "-----BEGIN AGE ENCRYPTED FILE-----"
+
+// refScheme returns the scheme of the reference a secret value holds. An inline
+// armored age payload counts as a reference of scheme "age", so encrypted
+// values can be kept in flags, environment variables and config files alike.
+func refScheme(v string) (string, bool) {
+	if strings.HasPrefix(strings.TrimSpace(v), ageArmor) {
+		return "age", true
+	}
+	scheme, _, ok := strings.Cut(v, "://")
+	return scheme, ok
+}
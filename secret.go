@@ -0,0 +1,88 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+const redacted = "[redacted]"
+
+// Secret holds a sensitive flag value such as a password or an API token. It binds
+// like a plain string flag, but formatting it (fmt verbs, String, GoString) never
+// reveals the value, so a config struct can be logged without leaking it:
+//
+//	type Config struct {
+//	    Token clibind.Secret[string] `cli:"token"`
+//	    Key   clibind.Secret[[]byte] `cli:"key"`
+//	}
+//
+// Use Value to read the secret.
+type Secret[T ~string | ~[]byte] struct {
+	value T
+	set   bool
+}
+
+// NewSecret wraps v, e.g. to fill in a Secret outside of Bind.
+func NewSecret[T ~string | ~[]byte](v T) Secret[T] {
+	return Secret[T]{value: v, set: true}
+}
+
+// Value returns the wrapped value.
+func (s Secret[T]) Value() T { return s.value }
+
+// IsSet reports whether a value was bound, which may be the empty string.
+func (s Secret[T]) IsSet() bool { return s.set }
+
+// String returns "[redacted]", or "" when no value was bound.
+func (s Secret[T]) String() string {
+	if !s.set {
+		return ""
+	}
+	return redacted
+}
+
+// GoString keeps %#v from printing the value.
+func (s Secret[T]) GoString() string {
+	return fmt.Sprintf("clibind.Secret[%s]{%s}", reflect.TypeFor[T](), s.String())
+}
+
+func (s Secret[T]) reveal() string { return string(s.value) }
+
+func (s *Secret[T]) setSecret(v string) {
+	s.value = T(v)
+	s.set = true
+}
+
+// secretSetter is implemented by *Secret[T] for every T, letting the binder treat
+// secrets as string flags instead of nested structs.
+type secretSetter interface {
+	setSecret(string)
+}
+
+// secretValue is implemented by Secret[T] for every T.
+type secretValue interface {
+	fmt.Stringer
+	IsSet() bool
+	reveal() string
+}
+
+var secretSetterType = reflect.TypeFor[secretSetter]()
+
+// isSecret reports whether t is a Secret[T].
+func isSecret(t reflect.Type) bool {
+	return t.Kind() == reflect.Struct && reflect.PointerTo(t).Implements(secretSetterType)
+}
+
+// isSecretTagged reports whether the field sf holds a secret: a Secret[T], a slice
+// or pointer of them, or any field tagged cliSecret:"true".
+func isSecretTagged(sf reflect.StructField) bool {
+	if secret, _ := strconv.ParseBool(sf.Tag.Get(tagCLISecret)); secret {
+		return true
+	}
+	t := unreferenceType(sf.Type)
+	if t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return isSecret(t)
+}
@@ -0,0 +1,99 @@
+package clibind_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type secretConfig struct {
+	User    string                   `cli:"user" cliDefault:"admin"`
+	Token   clibind.Secret[string]   `cli:"token"`
+	Key     clibind.Secret[[]byte]   `cli:"key,omitempty"`
+	Backups []clibind.Secret[string] `cli:"backup,omitempty"`
+	Old     *clibind.Secret[string]  `cli:"old,omitempty"`
+	Pin     string                   `cli:"pin" cliSecret:"true" cliDefault:"0000"`
+}
+
+func TestSecret(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, secretConfig) error { return nil })
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--token", "", "--key", "k3y", "--backup", "b1,b2"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	got := clibindtest.Bound[secretConfig](t, res, "app")
+	if !got.Token.IsSet() || got.Token.Value() != "" || !bytes.Equal(got.Key.Value(), []byte("k3y")) || got.Old != nil {
+		t.Errorf("bound token %q (set %t), key %q, old %v; want an empty set token and k3y", got.Token.Value(), got.Token.IsSet(), got.Key.Value(), got.Old)
+	}
+	if len(got.Backups) != 2 || got.Backups[1].Value() != "b2" {
+		t.Errorf("bound backups %v, want 2", got.Backups)
+	}
+
+	for _, verb := range []string{"%v", "%+v", "%#v", "%s"} {
+		if s := fmt.Sprintf(verb, got); strings.Contains(s, "k3y") || strings.Contains(s, "b1") || !strings.Contains(s, "[redacted]") {
+			t.Errorf("%s prints a secret: %s", verb, s)
+		}
+	}
+	if s := fmt.Sprintf("%#v", got.Key); s != "clibind.Secret[[]uint8]{[redacted]}" {
+		t.Errorf("%%#v = %s, want clibind.Secret[[]uint8]{[redacted]}", s)
+	}
+	if s := (clibind.Secret[string]{}).String(); s != "" {
+		t.Errorf("unset String() = %q, want empty", s)
+	}
+	if s := clibind.NewSecret("x"); !s.IsSet() || s.Value() != "x" {
+		t.Errorf("NewSecret = %q (set %t), want x", s.Value(), s.IsSet())
+	}
+
+	args, err := clibind.Unbind(secretConfig{User: "u", Token: clibind.NewSecret("t0k"), Key: clibind.NewSecret([]byte("k3y")), Pin: "1234"})
+	if err != nil || !slices.Equal(args, []string{"--user", "u", "--token", "t0k", "--key", "k3y", "--pin", "1234"}) {
+		t.Errorf("Unbind = %q, %v; want the secrets in clear", args, err)
+	}
+}
+
+func TestSecretRedaction(t *testing.T) {
+	newRoot := func() *cli.Command {
+		return clibind.CommandWithBinding(nil, "app", func(context.Context, secretConfig) error { return nil },
+			clibind.WithPrintConfig())
+	}
+	args := []string{"--print-config", "--token", "t0k", "--key", "k3y", "--backup", "b1", "--old", "o1d", "--pin", "1234"}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: args})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	const masked = "user=admin\ntoken=[redacted]\nkey=[redacted]\nbackup=[redacted]\nold=[redacted]\npin=[redacted]\n"
+	if res.Stdout != masked {
+		t.Errorf("--print-config:\n%s\nwant:\n%s", res.Stdout, masked)
+	}
+	if len(res.Calls) != 0 {
+		t.Error("the handler ran along with --print-config")
+	}
+
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: append(args, "--show-secrets")})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	const shown = "user=admin\ntoken=t0k\nkey=k3y\nbackup=b1\nold=o1d\npin=1234\n"
+	if res.Stdout != shown {
+		t.Errorf("--print-config --show-secrets:\n%s\nwant:\n%s", res.Stdout, shown)
+	}
+
+	var b strings.Builder
+	if err := clibind.WriteConfig(&b, secretConfig{User: "u"}, false); err != nil {
+		t.Fatal(err)
+	}
+	if want := "user=u\ntoken=\nkey=\nbackup=\nold=\npin=\n"; b.String() != want {
+		t.Errorf("WriteConfig of unset secrets:\n%s\nwant:\n%s", b.String(), want)
+	}
+
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"--help"}})
+	if strings.Contains(res.Stdout, "0000") || !strings.Contains(res.Stdout, "[redacted]") {
+		t.Errorf("help shows the default of --pin:\n%s", res.Stdout)
+	}
+}
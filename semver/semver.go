@@ -0,0 +1,102 @@
+// Package semver teaches clibind the version and constraint types of
+// github.com/Masterminds/semver/v3, so release tooling validates versions when
+// flags are parsed rather than when they are used:
+//
+//	type Config struct {
+//	    MinVersion *semver.Version     `cli:"min-version" cliDefault:"1.4.0"`
+//	    Supported  *semver.Constraints `cli:"supported" cliDefault:">= 1.2, < 3"`
+//	    Skip       []semver.Version    `cli:"skip,omitempty"`
+//	}
+//
+//	import clibindsemver "github.com/eosproject/urfave-cli-bind/semver"
+//
+//	func main() {
+//	    if err := clibindsemver.Register(); err != nil {
+//	        log.Fatal(err)
+//	    }
+//	    clibind.Freeze()
+//	    ...
+//	}
+//
+// Versions are parsed with semver.NewVersion, which accepts a leading v and
+// missing minor or patch numbers (v1.2 is 1.2.0), and print as they were given.
+// Constraints are parsed with semver.NewConstraint: comma-separated ranges are
+// and-ed and || separated ones or-ed, so a []*semver.Constraints field, whose
+// values are split on commas, is rarely what is meant.
+package semver
+
+import (
+	"fmt"
+
+	"github.com/Masterminds/semver/v3"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+)
+
+// Register registers semver.Version and semver.Constraints, and pointers to
+// them, with clibind.RegisterType. It fails after clibind.Freeze.
+func Register() error {
+	for _, err := range []error{
+		clibind.RegisterType(parseVersion, formatVersion),
+		clibind.RegisterType(func(s string) (semver.Version, error) {
+			v, err := parseVersion(s)
+			if err != nil {
+				return semver.Version{}, err
+			}
+			return *v, nil
+		}, func(v semver.Version) string {
+			if v == (semver.Version{}) {
+				return ""
+			}
+			return formatVersion(&v)
+		}),
+		clibind.RegisterType(parseConstraints, formatConstraints),
+		clibind.RegisterType(func(s string) (semver.Constraints, error) {
+			c, err := parseConstraints(s)
+			if err != nil {
+				return semver.Constraints{}, err
+			}
+			return *c, nil
+		}, func(c semver.Constraints) string { return formatConstraints(&c) }),
+	} {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func parseVersion(s string) (*semver.Version, error) {
+	v, err := semver.NewVersion(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse version %q: %w", s, err)
+	}
+	return v, nil
+}
+
+// formatVersion prints v as it was given, 1.2.0 for a version made by the
+// semver functions.
+func formatVersion(v *semver.Version) string {
+	switch {
+	case v == nil:
+		return ""
+	case v.Original() != "":
+		return v.Original()
+	}
+	return v.String()
+}
+
+func parseConstraints(s string) (*semver.Constraints, error) {
+	c, err := semver.NewConstraint(s)
+	if err != nil {
+		return nil, fmt.Errorf("parse version constraint %q: %w", s, err)
+	}
+	return c, nil
+}
+
+func formatConstraints(c *semver.Constraints) string {
+	if c == nil {
+		return ""
+	}
+	return c.String()
+}
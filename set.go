@@ -0,0 +1,118 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+const flagSet = "set"
+
+// WithSetFlag makes CommandWithBinding add a repeatable --set PATH=VALUE flag
+// overriding any field of the struct by its dotted path, as Helm does for
+// values files, where a flag per leaf of a deeply nested configuration would be
+// unwieldy:
+//
+//	app serve --set server.timeout=5s --set server.tls.ciphers=a,b
+//
+// Each path segment is the cli name or the Go name of a field, regardless of
+// case; structs flattened into their parent (no cli name) are looked through.
+// Slice values are comma-separated, and map values key=value entries. WithBinding applies the overrides in order
+// once the flags are bound and their cliSources and value sources resolved, so
+// they win over every other source, and also reach fields without a flag
+// (cliSkipFlag, cli:"-"). Nil struct pointers on the way are allocated.
+func WithSetFlag() Option {
+	return func(o *options) {
+		o.setFlag = true
+	}
+}
+
+func setFlag() cli.Flag {
+	return &kvFlag{
+		Name:  flagSet,
+		Usage: "override the field at a dotted `PATH=VALUE`, e.g. server.timeout=5s (repeatable)",
+	}
+}
+
+// applySets applies the --set overrides of c to cfg, a pointer to a struct,
+// recording them in prov.
+func applySets(c *cli.Command, cfg any, prov *Provenance) error {
+	if !c.IsSet(flagSet) {
+		return nil
+	}
+	root := reflect.ValueOf(cfg).Elem()
+	for _, set := range c.Value(flagSet).([]string) {
+		path, value, ok := strings.Cut(set, "=")
+		if !ok {
+			return fmt.Errorf("--%s %s: want PATH=VALUE", flagSet, set)
+		}
+		path = strings.TrimSpace(path)
+		index, name, sf, ok := fieldIndexAt(root.Type(), "", path)
+		if !ok {
+			return fmt.Errorf("--%s %s: no field at %s", flagSet, set, path)
+		}
+		v := root
+		for _, i := range index[:len(index)-1] {
+			v = allocReferenced(v.Field(i))
+		}
+		field := allocReferenced(v.Field(index[len(index)-1]))
+		raw := any(value)
+		if field.Kind() == reflect.Slice && !isCustomType(field.Type()) {
+			raw = splitCSV(value)
+		}
+		if err := checkChoices(raw, splitCSV(sf.Tag.Get(tagCLIChoices))); err != nil {
+			return fmt.Errorf("--%s %s: %w", flagSet, path, err)
+		}
+		if err := setFieldFromString(value, sf, field); err != nil {
+			return fmt.Errorf("--%s %s: %w", flagSet, path, err)
+		}
+		if prov.Resolved == nil {
+			prov.Resolved = map[string]string{}
+		}
+		prov.Resolved[name] = "--" + flagSet + " " + path
+	}
+	return nil
+}
+
+// fieldIndexAt returns the index sequence of the leaf field of the struct type
+// t at the dotted path, with its full flag name given the inherited prefix.
+func fieldIndexAt(t reflect.Type, prefix, path string) ([]int, string, reflect.StructField, bool) {
+	t = unreferenceType(t)
+	seg, rest, nested := strings.Cut(path, ".")
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if isStructLike(sf.Type) {
+			sub := path
+			switch {
+			case nested && matchesSegment(sf, name, seg):
+				sub = rest
+			case name != "":
+				continue
+			}
+			if index, flag, leaf, ok := fieldIndexAt(sf.Type, nestedPrefix(sf, prefix), sub); ok {
+				return append([]int{i}, index...), flag, leaf, true
+			}
+			continue
+		}
+		if nested || !matchesSegment(sf, name, seg) {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		return []int{i}, prefix + name, sf, true
+	}
+	return nil, "", reflect.StructField{}, false
+}
+
+// matchesSegment reports whether the path segment seg names the field sf, whose
+// cli name is name.
+func matchesSegment(sf reflect.StructField, name, seg string) bool {
+	return strings.EqualFold(seg, sf.Name) || name != "" && strings.EqualFold(seg, name)
+}
@@ -0,0 +1,68 @@
+package clibind_test
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type setServer struct {
+	Timeout string `cli:"timeout" cliDefault:"1s"`
+}
+
+type setConfig struct {
+	Server  setServer         `cli:"server"`
+	Limits  map[string]int    `cli:"limit,omitempty"`
+	Tags    []string          `cli:"tag,omitempty"`
+	Secret  string            `cli:"-" cliEnv:"SET_SECRET" cliDefault:"none"`
+	Headers map[string]string `cli:"header,omitempty"`
+}
+
+func newSetRoot() *cli.Command {
+	return &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			clibind.CommandWithBinding(nil, "serve", func(context.Context, setConfig) error { return nil }, clibind.WithSetFlag()),
+		},
+	}
+}
+
+func TestSetFlag(t *testing.T) {
+	res := clibindtest.Run(t, newSetRoot(), clibindtest.Input{Args: []string{
+		"serve", "--server-timeout", "2s", "--set", "server.timeout=5s", "--set", "limit=a=1",
+		"--set", "Tags=x;y", "--set", "secret=s3cr3t", "--limit", "b=2",
+	}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	want := setConfig{
+		Server: setServer{Timeout: "5s"},
+		Limits: map[string]int{"a": 1},
+		Tags:   []string{"x;y"},
+		Secret: "s3cr3t",
+	}
+	if got := clibindtest.Bound[setConfig](t, res, "app serve"); !reflect.DeepEqual(got, want) {
+		t.Errorf("bound %+v, want %+v", got, want)
+	}
+}
+
+func TestSetFlagErrors(t *testing.T) {
+	for _, c := range []struct {
+		set, err string
+	}{
+		{"limit=a=x", `map value "x"`},
+		{"limit=a", "is not key=value"},
+		{"nope=1", "no field at nope"},
+		{"server.timeout", "want PATH=VALUE"},
+	} {
+		res := clibindtest.Run(t, newSetRoot(), clibindtest.Input{Args: []string{"serve", "--set", c.set}})
+		if res.Err == nil || !strings.Contains(res.Err.Error(), c.err) {
+			t.Errorf("--set %s: err = %v, want %q", c.set, res.Err, c.err)
+		}
+	}
+}
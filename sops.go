@@ -0,0 +1,56 @@
+package clibind
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// sopsCommand is the sops binary used to decrypt configuration files.
+var sopsCommand = "sops"
+
+// isSOPS reports whether the decoded configuration file m was encrypted with
+// SOPS, which stores its metadata under a top-level "sops" key.
+func isSOPS(m map[string]any) bool {
+	meta, ok := m["sops"].(map[string]any)
+	if !ok {
+		return false
+	}
+	_, ok = meta["mac"]
+	return ok
+}
+
+// sopsDecrypt decrypts a SOPS-encrypted configuration file of the given format
+// to JSON with the sops CLI, so every key service sops supports (age, PGP, AWS
+// and GCP KMS, Azure Key Vault, Vault transit) works with its usual setup. The
+// CLI must be on the PATH: linking the sops library would pull the SDKs of all
+// those services into every program using clibind. The data goes through a
+// temporary file, readable by the user only, as not every platform has a
+// /dev/stdin to point sops to.
+func sopsDecrypt(format string, data []byte) ([]byte, error) {
+	f, err := os.CreateTemp("", "clibind-*."+format)
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(data)
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+
+	var stderr strings.Builder
+	cmd := exec.Command(sopsCommand, "--decrypt", "--input-type", format, "--output-type", "json", f.Name())
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("sops: %s", msg)
+		}
+		return nil, fmt.Errorf("sops: %w", err)
+	}
+	return out, nil
+}
@@ -0,0 +1,47 @@
+package clibind_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+// fakeSOPS is a sops stand-in that checks it is given the encrypted file to read
+// and prints the decrypted configuration.
+const fakeSOPS = `#!/bin/sh
+for last; do :; done
+[ -f "$last" ] && grep -q '"mac"' "$last" || { echo "no encrypted file at $last" >&2; exit 1; }
+echo '{"host": "decrypted.internal"}'
+`
+
+type sopsConfig struct {
+	Host string `cli:"host"`
+}
+
+func TestConfigFileSOPS(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the fake sops is a shell script")
+	}
+	bin := t.TempDir()
+	if err := os.WriteFile(filepath.Join(bin, "sops"), []byte(fakeSOPS), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, sopsConfig) error { return nil },
+		clibind.WithConfigFile("config"))
+	res := clibindtest.Run(t, root, clibindtest.Input{
+		Args:  []string{"--config", "config.json"},
+		Env:   map[string]string{"PATH": bin + string(os.PathListSeparator) + os.Getenv("PATH")},
+		Files: map[string]string{"config.json": `{"host": "ENC[AES256_GCM,data:...]", "sops": {"mac": "ENC[...]"}}`},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[sopsConfig](t, res, "app").Host; got != "decrypted.internal" {
+		t.Errorf("Host = %q, want decrypted.internal", got)
+	}
+}
@@ -0,0 +1,170 @@
+package clibind
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// StrictMode selects what BindStrict and WithStrictBind check.
+type StrictMode int
+
+const (
+	// StrictFields requires a flag on the command, or one of its ancestors, for
+	// every field.
+	StrictFields StrictMode = 1 << iota
+	// StrictFlags requires a field for every flag of the command, except help
+	// and version.
+	StrictFlags
+)
+
+// BindStrict is Bind, but fails when fields and the command's flags have drifted
+// apart, as hand-assembled flag lists tend to: with StrictFields when a field has
+// no flag, with StrictFlags when a flag has no field, with StrictFields|StrictFlags
+// in both cases. Nothing is bound then.
+func BindStrict(cmd *cli.Command, dest any, mode StrictMode) error {
+	rv := reflect.ValueOf(dest)
+	if rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return errors.New("BindStrict: dest must be a non-nil pointer to a struct")
+	}
+	if err := checkStrict(cmd, unreferenceType(rv.Type()), mode, nil); err != nil {
+		return err
+	}
+	return Bind(cmd, dest)
+}
+
+// WithStrictBind makes WithBinding bind like BindStrict. The flags the options
+// themselves add (--config, --print-config and the like) need no field.
+func WithStrictBind(mode StrictMode) Option {
+	return func(o *options) {
+		o.strict = mode
+	}
+}
+
+// ownFlags returns the names of the flags the options add to a command.
+func (o *options) ownFlags() []string {
+	var names []string
+	if o.printConfig {
+		names = append(names, flagPrintConfig, flagShowSecrets)
+	}
+	if o.explainConfig {
+		names = append(names, flagExplainConfig)
+	}
+	if o.flagsJSON != nil {
+		names = append(names, flagFlagsJSON)
+	}
+	if o.configJSON != nil {
+		names = append(names, flagConfigJSON)
+	}
+	if o.setFlag {
+		names = append(names, flagSet)
+	}
+	if o.config != nil {
+		names = append(names, o.config.flag)
+		if o.config.checksumFlag != "" {
+			names = append(names, o.config.checksumFlag)
+		}
+	}
+	return names
+}
+
+// checkStrict compares the fields of the struct type t with the flags of cmd,
+// ignoring the flags named in ignore.
+func checkStrict(cmd *cli.Command, t reflect.Type, mode StrictMode, ignore []string) error {
+	var errs []error
+	fields := map[string]bool{}
+	walkLeafFields(t, "", func(name string, sf reflect.StructField) {
+		fields[name] = true
+		if mode&StrictFields != 0 && !isEnvOnly(sf) && !hasFlag(cmd, name) {
+			errs = append(errs, fmt.Errorf("field %s: no flag --%s", sf.Name, name))
+		}
+	})
+	walkGroupFlags(t, "", func(name string) {
+		fields[name] = true
+	})
+	if mode&StrictFlags != 0 {
+		for _, fl := range cmd.Flags {
+			names := fl.Names()
+			if fields[names[0]] || slices.Contains(ignore, names[0]) || isBuiltinFlag(fl) {
+				continue
+			}
+			errs = append(errs, fmt.Errorf("flag --%s: no field", names[0]))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// walkLeafFields is walkLeaves for types: it also visits the fields of nested
+// structs behind nil pointers.
+func walkLeafFields(t reflect.Type, prefix string, fn func(name string, sf reflect.StructField)) {
+	t = unreferenceType(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if isStructLike(sf.Type) {
+			walkLeafFields(sf.Type, nestedPrefix(sf, prefix), fn)
+			continue
+		}
+		name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		fn(prefix+name, sf)
+	}
+}
+
+// walkGroupFlags calls fn with the names of the flags generated for the struct
+// type t that are not bound to one of its leaf fields: the compound flags of
+// cliKV structs and the flags of the implementations of factory fields.
+func walkGroupFlags(t reflect.Type, prefix string, fn func(name string)) {
+	t = unreferenceType(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		name = prefix + name
+		if isStructLike(sf.Type) {
+			if isKV(sf) {
+				fn(name)
+			}
+			walkGroupFlags(sf.Type, nestedPrefix(sf, prefix), fn)
+			continue
+		}
+		for _, f := range factoriesOf(sf.Type) {
+			walkLeafFields(f.config, factoryPrefix(name, f), func(n string, _ reflect.StructField) { fn(n) })
+			walkGroupFlags(f.config, factoryPrefix(name, f), fn)
+		}
+	}
+}
+
+// hasFlag reports whether cmd or one of its ancestors has a flag named name.
+func hasFlag(cmd *cli.Command, name string) bool {
+	for _, c := range cmd.Lineage() {
+		for _, fl := range c.Flags {
+			if slices.Contains(fl.Names(), name) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func isBuiltinFlag(fl cli.Flag) bool {
+	for _, b := range []cli.Flag{cli.HelpFlag, cli.VersionFlag} {
+		if b != nil && slices.Equal(fl.Names(), b.Names()) {
+			return true
+		}
+	}
+	return false
+}
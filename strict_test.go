@@ -0,0 +1,94 @@
+package clibind_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type strictConfig struct {
+	Host  string `cli:"host" cliDefault:"localhost"`
+	Port  int    `cli:"port" cliDefault:"8080"`
+	Token string `cli:"-" cliEnv:"TOKEN" cliDefault:"none"`
+}
+
+func TestBindStrict(t *testing.T) {
+	for _, c := range []struct {
+		name  string
+		flags []cli.Flag
+		mode  clibind.StrictMode
+		err   []string // nil if binding succeeds
+	}{
+		{"in sync", []cli.Flag{&cli.StringFlag{Name: "host", Value: "db"}, &cli.IntFlag{Name: "port", Value: 5432}}, clibind.StrictFields | clibind.StrictFlags, nil},
+		{"missing flag", []cli.Flag{&cli.StringFlag{Name: "host"}}, clibind.StrictFields, []string{"field Port: no flag --port"}},
+		{"missing flag not checked", []cli.Flag{&cli.StringFlag{Name: "host", Value: "db"}}, clibind.StrictFlags, nil},
+		{"extra flag", []cli.Flag{&cli.StringFlag{Name: "host"}, &cli.IntFlag{Name: "port"}, &cli.BoolFlag{Name: "debug"}}, clibind.StrictFlags, []string{"flag --debug: no field"}},
+		{"both", []cli.Flag{&cli.BoolFlag{Name: "debug"}}, clibind.StrictFields | clibind.StrictFlags, []string{
+			"field Host: no flag --host", "field Port: no flag --port", "flag --debug: no field",
+		}},
+	} {
+		var cfg strictConfig
+		var bindErr error
+		root := &cli.Command{
+			Name:  "app",
+			Flags: c.flags,
+			Action: func(_ context.Context, cmd *cli.Command) error {
+				bindErr = clibind.BindStrict(cmd, &cfg, c.mode)
+				return nil
+			},
+		}
+		if res := clibindtest.Run(t, root, clibindtest.Input{}); res.Err != nil {
+			t.Fatalf("%s: %v", c.name, res.Err)
+		}
+		if c.err == nil {
+			if bindErr != nil {
+				t.Errorf("%s: %v", c.name, bindErr)
+			} else if cfg.Host != "db" || cfg.Token != "none" {
+				t.Errorf("%s: bound %+v, want host db and token none", c.name, cfg)
+			}
+			continue
+		}
+		for _, want := range c.err {
+			if bindErr == nil || !strings.Contains(bindErr.Error(), want) {
+				t.Errorf("%s: err = %v, want %q", c.name, bindErr, want)
+			}
+		}
+		if cfg != (strictConfig{}) {
+			t.Errorf("%s: bound %+v despite the error", c.name, cfg)
+		}
+	}
+
+	root := &cli.Command{Name: "app", Action: func(_ context.Context, cmd *cli.Command) error {
+		return clibind.BindStrict(cmd, strictConfig{}, clibind.StrictFields)
+	}}
+	if res := clibindtest.Run(t, root, clibindtest.Input{}); res.Err == nil || !strings.Contains(res.Err.Error(), "non-nil pointer") {
+		t.Errorf("err = %v, want a non-pointer dest rejected", res.Err)
+	}
+}
+
+func TestWithStrictBind(t *testing.T) {
+	root := &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{clibind.CommandWithBinding(nil, "serve", func(context.Context, strictConfig) error { return nil },
+			clibind.WithStrictBind(clibind.StrictFields|clibind.StrictFlags), clibind.WithPrintConfig(), clibind.WithSetFlag())},
+	}
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"serve", "--set", "port=9090"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[strictConfig](t, res, "app serve"); got.Host != "localhost" || got.Port != 9090 {
+		t.Errorf("bound %+v, want localhost:9090", got)
+	}
+
+	serve := clibind.CommandWithBinding(nil, "serve", func(context.Context, strictConfig) error { return nil },
+		clibind.WithStrictBind(clibind.StrictFlags))
+	serve.Flags = append(serve.Flags, &cli.BoolFlag{Name: "debug"})
+	res = clibindtest.Run(t, &cli.Command{Name: "app", Commands: []*cli.Command{serve}}, clibindtest.Input{Args: []string{"serve"}})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "flag --debug: no field") {
+		t.Errorf("err = %v, want the hand-added flag reported", res.Err)
+	}
+}
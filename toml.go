@@ -0,0 +1,67 @@
+package clibind
+
+import (
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// decodeTOML decodes a TOML configuration file into the shape decodeConfig
+// returns for JSON: tables become maps, arrays []any, and numbers json.Number.
+// Dates and times are kept as strings, in the syntax the flags parse.
+func decodeTOML(data []byte) (map[string]any, error) {
+	var m map[string]any
+	if err := toml.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return tomlValue(m).(map[string]any), nil
+}
+
+// tomlValue converts the value v decoded by the toml package, see decodeTOML.
+func tomlValue(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		for k, e := range v {
+			v[k] = tomlValue(e)
+		}
+		return v
+	case []map[string]any: // array of tables
+		s := make([]any, len(v))
+		for i, e := range v {
+			s[i] = tomlValue(e)
+		}
+		return s
+	case []any:
+		for i, e := range v {
+			v[i] = tomlValue(e)
+		}
+		return v
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case float64:
+		switch {
+		case math.IsInf(v, 1):
+			return json.Number("inf")
+		case math.IsInf(v, -1):
+			return json.Number("-inf")
+		case math.IsNaN(v):
+			return json.Number("nan")
+		}
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case time.Time:
+		// the toml package marks local dates and times by these zones
+		switch v.Location().String() {
+		case "date-local":
+			return v.Format(time.DateOnly)
+		case "time-local":
+			return v.Format("15:04:05.999999999")
+		case "datetime-local":
+			return v.Format("2006-01-02T15:04:05.999999999")
+		}
+		return v.Format(time.RFC3339Nano)
+	}
+	return v
+}
@@ -0,0 +1,91 @@
+package clibind_test
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type tomlConfig struct {
+	Name    string            `cli:"name"`
+	Port    int               `cli:"port"`
+	Ratio   float64           `cli:"ratio"`
+	Debug   bool              `cli:"debug"`
+	Tags    []string          `cli:"tag"`
+	Labels  map[string]string `cli:"label"`
+	Started time.Time         `cli:"started"`
+	Day     time.Time         `cli:"day" cliTimeLayout:"2006-01-02"`
+	DB      struct {
+		Host string `cli:"host"`
+		User string `cli:"user" cliFile:"database.credentials.user"`
+	} `cli:"db"`
+}
+
+const tomlFile = `
+# a comment
+name = "app"
+port = 8_080
+ratio = 0.25
+debug = true
+tag = ["a", "b"]
+label = { team = "infra", tier = "prod" }
+started = 2024-05-01T10:30:00Z
+day = 2024-05-01
+
+[db]
+host = "db.internal"
+
+[database.credentials]
+user = 'admin'
+`
+
+func TestConfigFileTOML(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, tomlConfig) error { return nil },
+		clibind.WithConfigFile("config"))
+	res := clibindtest.Run(t, root, clibindtest.Input{
+		Args:  []string{"--config", "config.toml"},
+		Files: map[string]string{"config.toml": tomlFile},
+	})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	cfg := clibindtest.Bound[tomlConfig](t, res, "app")
+	for _, c := range []struct {
+		name      string
+		got, want any
+	}{
+		{"Name", cfg.Name, "app"},
+		{"Port", cfg.Port, 8080},
+		{"Ratio", cfg.Ratio, 0.25},
+		{"Debug", cfg.Debug, true},
+		{"Labels", cfg.Labels["team"] + "," + cfg.Labels["tier"], "infra,prod"},
+		{"Started", cfg.Started.UTC(), time.Date(2024, 5, 1, 10, 30, 0, 0, time.UTC)},
+		{"Day", cfg.Day.Format(time.DateOnly), "2024-05-01"},
+		{"DB.Host", cfg.DB.Host, "db.internal"},
+		{"DB.User", cfg.DB.User, "admin"},
+	} {
+		if c.got != c.want {
+			t.Errorf("%s = %v, want %v", c.name, c.got, c.want)
+		}
+	}
+	if !slices.Equal(cfg.Tags, []string{"a", "b"}) {
+		t.Errorf("Tags = %v, want [a b]", cfg.Tags)
+	}
+}
+
+func TestConfigFileTOMLMalformed(t *testing.T) {
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, tomlConfig) error { return nil },
+		clibind.WithConfigFile("config"))
+	res := clibindtest.Run(t, root, clibindtest.Input{
+		Args:  []string{"--config", "config.toml"},
+		Files: map[string]string{"config.toml": "[db]\nhost = \"a\"\n[db]\nhost = \"b\"\n"},
+	})
+	if res.Err == nil || !strings.Contains(res.Err.Error(), "config.toml") {
+		t.Errorf("err = %v, want an error naming config.toml", res.Err)
+	}
+}
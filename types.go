@@ -0,0 +1,82 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// typeCodec parses and formats the values of a type given to RegisterType.
+type typeCodec struct {
+	parse  func(s string, sf reflect.StructField) (reflect.Value, error)
+	format func(v reflect.Value) string
+}
+
+var (
+	typesMu sync.RWMutex
+	types   = map[reflect.Type]typeCodec{}
+)
+
+// RegisterType teaches the binder the type T, which then binds like a string
+// flag parsed by parse, in fields, slices (one element per value) and map
+// values alike:
+//
+//	clibind.RegisterType(ulid.Parse, ulid.ULID.String)
+//
+//	type Config struct {
+//	    Since ulid.ULID   `cli:"since"`
+//	    Skip  []ulid.ULID `cli:"skip,omitempty"`
+//	}
+//
+// format writes values back for help defaults, dumps and Unbind; nil means
+// fmt.Sprint. Defaults are parsed when flags are generated, an empty string
+// leaves the field zero. A registered type takes precedence over the built-in
+// handling of T, including flag.Value. Registering T again replaces its
+// functions; it fails after Freeze. It panics if parse is nil.
+func RegisterType[T any](parse func(string) (T, error), format func(T) string) error {
+	t := reflect.TypeFor[T]()
+	if parse == nil {
+		panic(fmt.Sprintf("clibind: RegisterType[%s]: nil parse func", t))
+	}
+	c := typeCodec{
+		parse: func(s string, _ reflect.StructField) (reflect.Value, error) {
+			v, err := parse(s)
+			return reflect.ValueOf(&v).Elem(), err
+		},
+		format: func(v reflect.Value) string {
+			if format == nil {
+				return fmt.Sprint(v.Interface())
+			}
+			return format(v.Interface().(T))
+		},
+	}
+	typesMu.Lock()
+	defer typesMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterType[%s]", t)); err != nil {
+		return err
+	}
+	types[t] = c
+	return nil
+}
+
+// registeredType returns the codec RegisterType registered for t, else the
+// built-in one, if any.
+func registeredType(t reflect.Type) (typeCodec, bool) {
+	typesMu.RLock()
+	c, ok := types[t]
+	typesMu.RUnlock()
+	if !ok {
+		c, ok = builtinTypes[t]
+	}
+	if !ok && isNullable(t) {
+		c, ok = nullableCodec(t), true
+	}
+	return c, ok
+}
+
+// isCustomType reports whether the values of t parse and format themselves,
+// through RegisterType, a built-in codec or flag.Value, whatever the kind of t.
+func isCustomType(t reflect.Type) bool {
+	_, ok := registeredType(t)
+	return ok || isFlagValue(t)
+}
@@ -0,0 +1,295 @@
+package clibind
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"math/big"
+	"math/rand/v2"
+	"net"
+	"net/mail"
+	"net/netip"
+	"net/url"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofrs/uuid"
+)
+
+// Unbind is the inverse of Bind: it returns the command-line arguments that bind
+// cfg, a struct or a pointer to one, e.g. to run the command again in a child
+// process. Secrets are written in clear. Nil pointers, unset secrets, empty
+// slices and empty maps are left out, so they bind their default; slice
+// elements holding commas do not survive, as slice flags split on them. It
+// fails on non-nil interface fields (see RegisterFactory), which cannot be
+// turned back into flags.
+func Unbind(cfg any) ([]string, error) {
+	v := reflect.Indirect(reflect.ValueOf(cfg))
+	if v.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("Unbind: %T is not a struct", cfg)
+	}
+	var args []string
+	var err error
+	walkLeaves(v, "", func(name string, sf reflect.StructField, fv reflect.Value) bool {
+		if skip, _ := strconv.ParseBool(sf.Tag.Get(tagCLISkipFlag)); skip || isEnvOnly(sf) {
+			return true
+		}
+		if fv.Kind() == reflect.Interface {
+			if !fv.IsNil() {
+				err = fmt.Errorf("Unbind: flag %s: %s values cannot be unbound", name, fv.Type())
+				return false
+			}
+			return true
+		}
+		isPointer := fv.Kind() == reflect.Pointer
+		if isNullable(fv.Type()) { // written as the pointer it binds like, left out when unset
+			if !fv.Field(1).Bool() {
+				return true
+			}
+			fv, isPointer = fv.Field(0), true
+		}
+		for fv.Kind() == reflect.Pointer {
+			if fv.IsNil() {
+				return true
+			}
+			fv = fv.Elem()
+		}
+		if isSecret(fv.Type()) && !fv.Interface().(secretValue).IsSet() {
+			return true // left out, as "--name ''" binds a set secret
+		}
+		switch {
+		case isCustomType(fv.Type()):
+			if s := formatScalar(fv, sf, true); s != "" { // as empty binds the zero value
+				args = append(args, "--"+name, s)
+			}
+		case fv.Kind() == reflect.Bool && isPointer:
+			if fv.Bool() {
+				args = append(args, "--"+name)
+			} else {
+				args = append(args, "--no-"+name)
+			}
+		case fv.Kind() == reflect.Slice:
+			for i := range fv.Len() {
+				args = append(args, "--"+name, unbindScalar(fv.Index(i), sf))
+			}
+		case fv.Kind() == reflect.Map:
+			var entries []string
+			for _, k := range fv.MapKeys() {
+				vals := fv.MapIndex(k)
+				if vals.Kind() != reflect.Slice || isCustomType(vals.Type()) {
+					entries = append(entries, unbindScalar(k, sf)+"="+unbindScalar(vals, sf))
+					continue
+				}
+				parts := make([]string, vals.Len())
+				for i := range parts {
+					parts[i] = unbindScalar(vals.Index(i), sf)
+				}
+				entries = append(entries, unbindScalar(k, sf)+"="+strings.Join(parts, ";"))
+			}
+			slices.Sort(entries)
+			for _, e := range entries {
+				args = append(args, "--"+name, e)
+			}
+		case fv.Kind() == reflect.Bool:
+			args = append(args, "--"+name+"="+strconv.FormatBool(fv.Bool()))
+		default:
+			// a separate argument, as urfave takes "--name=" to leave the value to the next one
+			args = append(args, "--"+name, unbindScalar(fv, sf))
+		}
+		return true
+	})
+	return args, err
+}
+
+// unbindScalar formats v like formatScalar, but in the cliBase of integer fields.
+func unbindScalar(v reflect.Value, sf reflect.StructField) string {
+	base, _ := intBase(sf, 0)
+	switch {
+	case isCustomType(v.Type()):
+	case v.Type() == reflect.TypeOf(time.Second): // an int64, but written as a duration
+	case isAnyInt(v.Kind()):
+		return strconv.FormatInt(v.Int(), displayBase(base))
+	case isAnyUint(v.Kind()):
+		return strconv.FormatUint(v.Uint(), displayBase(base))
+	}
+	return formatScalar(v, sf, true)
+}
+
+// RandomArgs returns random command-line arguments valid for the flags of T, for
+// property tests such as clibindtest.CheckRoundTrip. Values stay within the range
+// of their field types and the cliChoices of their fields; required flags are
+// always given and the others most of the time. Interface fields (see
+// RegisterFactory), fields of RegisterType and flag.Value types and fields
+// tagged cliSkipFlag are left out.
+func RandomArgs[T any](r *rand.Rand) []string {
+	var args []string
+	walkLeafFields(reflect.TypeFor[T](), "", func(name string, sf reflect.StructField) {
+		sf, _ = nullableField(sf)
+		if skip, _ := strconv.ParseBool(sf.Tag.Get(tagCLISkipFlag)); skip || isEnvOnly(sf) || sf.Type.Kind() == reflect.Interface || isCustomType(unreferenceType(sf.Type)) && !isBuiltinType(unreferenceType(sf.Type)) {
+			return
+		}
+		_, _, omitEmpty := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		required := !omitEmpty && fieldDefault(sf) == "" && sf.Type.Kind() != reflect.Pointer && sf.Tag.Get(tagCLISources) == ""
+		if !required && r.IntN(4) == 0 {
+			return
+		}
+		t := unreferenceType(sf.Type)
+		switch {
+		case t.Kind() == reflect.Bool && sf.Type.Kind() == reflect.Pointer:
+			if r.IntN(2) == 0 {
+				args = append(args, "--"+name)
+			} else {
+				args = append(args, "--no-"+name)
+			}
+		case t.Kind() == reflect.Slice && !isCustomType(t):
+			for range 1 + r.IntN(3) {
+				args = append(args, "--"+name, randomScalar(r, t.Elem(), sf, true))
+			}
+		case t.Kind() == reflect.Map:
+			for range 1 + r.IntN(2) {
+				if t.Elem().Kind() != reflect.Slice || isCustomType(t.Elem()) {
+					args = append(args, "--"+name, randomScalar(r, t.Key(), sf, true)+"="+randomScalar(r, t.Elem(), sf, true))
+					continue
+				}
+				vals := make([]string, 1+r.IntN(2))
+				for i := range vals {
+					vals[i] = randomScalar(r, t.Elem().Elem(), sf, true)
+				}
+				args = append(args, "--"+name, randomScalar(r, t.Key(), sf, true)+"="+strings.Join(vals, ";"))
+			}
+		case t.Kind() == reflect.Bool:
+			args = append(args, "--"+name+"="+randomScalar(r, t, sf, false))
+		default:
+			args = append(args, "--"+name, randomScalar(r, t, sf, false))
+		}
+	})
+	return args
+}
+
+// randomScalar returns a random value of type t in flag syntax. Values going into
+// slices and maps (inList) avoid the characters separating their elements.
+func randomScalar(r *rand.Rand, t reflect.Type, sf reflect.StructField, inList bool) string {
+	if choices := splitCSV(sf.Tag.Get(tagCLIChoices)); len(choices) > 0 {
+		return choices[r.IntN(len(choices))]
+	}
+	switch {
+	case t == reflect.TypeOf(net.IP{}):
+		return randomIP(r).String()
+	case t == reflect.TypeOf(net.IPNet{}):
+		ip := randomIP(r)
+		mask := net.CIDRMask(r.IntN(8*len(ip)+1), 8*len(ip))
+		return (&net.IPNet{IP: ip.Mask(mask), Mask: mask}).String()
+	case t == reflect.TypeOf(netip.Addr{}):
+		a, _ := netip.AddrFromSlice(randomIP(r))
+		return a.String()
+	case t == reflect.TypeOf(netip.AddrPort{}):
+		a, _ := netip.AddrFromSlice(randomIP(r))
+		return netip.AddrPortFrom(a, uint16(r.UintN(65536))).String()
+	case t == reflect.TypeOf(netip.Prefix{}):
+		a, _ := netip.AddrFromSlice(randomIP(r))
+		return netip.PrefixFrom(a, r.IntN(a.BitLen()+1)).String()
+	case t == reflect.TypeOf(url.URL{}):
+		scheme := "https"
+		if schemes := splitCSV(sf.Tag.Get(tagCLIScheme)); len(schemes) > 0 {
+			scheme = schemes[r.IntN(len(schemes))]
+		}
+		u := url.URL{Scheme: scheme, Host: fmt.Sprintf("host%d.example:%d", r.IntN(100), 1+r.IntN(65535)), Path: fmt.Sprintf("/p%d", r.IntN(1000))}
+		return u.String()
+	case t == reflect.TypeOf(slog.LevelInfo):
+		return strings.ToLower(slog.Level(r.IntN(17) - 8).String())
+	case t == reflect.TypeOf(os.FileMode(0)):
+		m, _ := parseFileMode(strconv.FormatUint(r.Uint64N(0o10000), 8))
+		return formatFileMode(m)
+	case unreferenceType(t) == reflect.TypeOf(big.Int{}):
+		i := new(big.Int).Lsh(new(big.Int).SetUint64(r.Uint64()), uint(r.IntN(64))) // past the range of int64
+		if r.IntN(2) == 0 {
+			i.Neg(i)
+		}
+		return i.String()
+	case unreferenceType(t) == reflect.TypeOf(big.Rat{}):
+		return big.NewRat(r.Int64N(2001)-1000, 1+r.Int64N(1000)).RatString()
+	case unreferenceType(t) == reflect.TypeOf(big.Float{}):
+		return big.NewFloat(r.NormFloat64()*math.Pow(10, float64(r.IntN(31)-15))).Text('g', -1)
+	case unreferenceType(t) == reflect.TypeOf(time.Location{}):
+		return []string{"UTC", "Local", "Europe/Berlin", "America/New_York", "Asia/Kolkata", "Australia/Lord_Howe"}[r.IntN(6)]
+	case unreferenceType(t) == reflect.TypeOf(mail.Address{}):
+		a := mail.Address{Address: fmt.Sprintf("user%d@host%d.example", r.IntN(1000), r.IntN(100))}
+		if r.IntN(2) == 0 {
+			a.Name = fmt.Sprintf("User %d", r.IntN(1000))
+		}
+		return a.String()
+	case unreferenceType(t) == reflect.TypeOf(regexp.Regexp{}):
+		return fmt.Sprintf("^%s[0-9]*$", regexp.QuoteMeta(randomString(r, true)))
+	case t == reflect.TypeOf(time.Second):
+		return time.Duration(r.Int64N(2e15) - 1e15).String()
+	case t == reflect.TypeOf(time.Time{}):
+		layout, _, _ := strings.Cut(sf.Tag.Get(tagCLITimeFmt), "|")
+		if layout == "" {
+			layout = defaultTimeFmt
+		}
+		return time.Unix(r.Int64N(4102444800), 0).UTC().Format(layout) // until 2100
+	case t == reflect.TypeOf(uuid.UUID{}):
+		var id uuid.UUID
+		for i := range id {
+			id[i] = byte(r.UintN(256))
+		}
+		id.SetVersion(uuid.V4)
+		id.SetVariant(uuid.VariantRFC4122)
+		return id.String()
+	case isSecret(t) || t.Kind() == reflect.String:
+		return randomString(r, inList)
+	case t.Kind() == reflect.Bool:
+		return strconv.FormatBool(r.IntN(2) == 0)
+	case isAnyInt(t.Kind()):
+		v := r.Int64N(2001) - 1000
+		if r.IntN(2) == 0 || t.Bits() == 8 {
+			v = int64(r.Uint64()) >> (64 - t.Bits()) // anywhere in the range of t, which ±1000 overflows for 8 bits
+		}
+		base, _ := intBase(sf, 0)
+		return strconv.FormatInt(v, displayBase(base))
+	case isAnyUint(t.Kind()):
+		v := r.Uint64N(1001)
+		if r.IntN(2) == 0 || t.Bits() == 8 {
+			v = r.Uint64() >> (64 - t.Bits())
+		}
+		base, _ := intBase(sf, 0)
+		return strconv.FormatUint(v, displayBase(base))
+	case t.Kind() == reflect.Float32 || t.Kind() == reflect.Float64:
+		if special, _ := strconv.ParseBool(sf.Tag.Get(tagCLIFloats)); special && r.IntN(10) == 0 {
+			return []string{"+Inf", "-Inf"}[r.IntN(2)] // not NaN, which equals nothing
+		}
+		f := r.NormFloat64() * math.Pow(10, float64(r.IntN(13)-6))
+		return strconv.FormatFloat(f, 'g', -1, t.Bits())
+	}
+	return ""
+}
+
+// randomIP returns a random IPv4 or IPv6 address, in its shortest form.
+func randomIP(r *rand.Rand) net.IP {
+	ip := make(net.IP, []int{net.IPv4len, net.IPv6len}[r.IntN(2)])
+	for i := range ip {
+		ip[i] = byte(r.UintN(256))
+	}
+	return ip
+}
+
+func randomString(r *rand.Rand, inList bool) string {
+	chars := []rune("abcxyzABCXYZ0189-_./ é")
+	if !inList {
+		chars = append(chars, ',', ';', '=', ':')
+	}
+	n := r.IntN(10)
+	if inList {
+		n++ // an empty element would vanish
+	}
+	s := make([]rune, n)
+	for i := range s {
+		s[i] = chars[r.IntN(len(chars))]
+	}
+	return string(s)
+}
@@ -0,0 +1,83 @@
+package clibind_test
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+)
+
+type roundTripDB struct {
+	Host string `cli:"host"`
+	Port uint16 `cli:"port" cliDefault:"5432"`
+}
+
+type roundTripConfig struct {
+	DB       roundTripDB              `cliPrefix:"db-"`
+	Mode     string                   `cli:"mode" cliChoices:"fast,safe"`
+	Workers  int8                     `cli:"workers" cliDefault:"1"`
+	Ratio    float64                  `cli:"ratio,omitempty"`
+	Timeout  time.Duration            `cli:"timeout" cliDefault:"30s"`
+	Verbose  *bool                    `cli:"verbose"`
+	Tags     []string                 `cli:"tag,omitempty"`
+	Limits   map[string]int           `cli:"limit,omitempty"`
+	Route    netip.Prefix             `cli:"route,omitempty"`
+	Level    slog.Level               `cli:"log-level" cliDefault:"info"`
+	Mask     os.FileMode              `cli:"mask" cliDefault:"0022"`
+	Retries  clibind.Optional[int]    `cli:"retries"`
+	Password clibind.Secret[string]   `cli:"password,omitempty"`
+	Token    string                   `cli:"-" cliEnv:"TOKEN" cliDefault:"none"`
+	Labels   map[string][]string      `cli:"label,omitempty"`
+	Backups  []clibind.Secret[string] `cli:"backup,omitempty"`
+}
+
+func TestRandomArgsRoundTrip(t *testing.T) {
+	for seed := range uint64(20) {
+		clibindtest.CheckRoundTripSeed[roundTripConfig](t, seed, 10)
+	}
+
+	r := rand.New(rand.NewPCG(1, 1))
+	for range 100 {
+		args := clibind.RandomArgs[roundTripConfig](r)
+		if !slices.Contains(args, "--db-host") || !slices.Contains(args, "--mode") {
+			t.Fatalf("%q misses the required --db-host or --mode", args)
+		}
+		if i := slices.Index(args, "--mode"); args[i+1] != "fast" && args[i+1] != "safe" {
+			t.Fatalf("%q gives --mode a value outside its choices", args)
+		}
+		if slices.ContainsFunc(args, func(a string) bool { return strings.Contains(a, "token") }) {
+			t.Fatalf("%q gives the env-only token", args)
+		}
+	}
+}
+
+func TestUnbind(t *testing.T) {
+	args, err := clibind.Unbind(&roundTripConfig{
+		DB:      roundTripDB{Host: "h", Port: 5432},
+		Mode:    "safe",
+		Timeout: time.Minute,
+		Tags:    []string{},
+		Level:   slog.LevelWarn,
+		Mask:    0o27,
+		Token:   "t0k",
+	})
+	want := []string{"--db-host", "h", "--db-port", "5432", "--mode", "safe", "--workers", "0", "--ratio", "0",
+		"--timeout", "1m0s", "--log-level", "warn", "--mask", "0027"}
+	if err != nil || !slices.Equal(args, want) {
+		t.Errorf("Unbind = %q, %v; want %q", args, err, want)
+	}
+
+	if _, err := clibind.Unbind("x"); err == nil || err.Error() != "Unbind: string is not a struct" {
+		t.Errorf("err = %v, want string is not a struct", err)
+	}
+	if _, err := clibind.Unbind(storageConfig{Storage: diskStorage{}}); err == nil || !strings.Contains(err.Error(), "values cannot be unbound") {
+		t.Errorf("err = %v, want the interface field refused", err)
+	}
+}
@@ -1,9 +1,18 @@
 package clibind
 
 import (
+	"fmt"
+	"math"
+	"math/big"
 	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 	"time"
+	"unicode"
+
+	"github.com/urfave/cli/v3"
 )
 
 // Takes T from *T if *T value passed
@@ -17,6 +26,33 @@ func unreferenceValue(t reflect.Value) reflect.Value {
 	return t
 }
 
+// Sets v into field, allocating nil pointers on the way if field is *T
+func setReferenced(field reflect.Value, v reflect.Value) {
+	for field.Kind() == reflect.Pointer {
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	field.Set(v)
+}
+
+// allocReferenced returns the settable value behind field, allocating nil
+// pointers on the way if field is *T. It stops at the pointers of pointerTypes,
+// set to the pointer their codec returns rather than to a copy of its pointee.
+func allocReferenced(field reflect.Value) reflect.Value {
+	for field.Kind() == reflect.Pointer {
+		if _, ok := pointerTypes[field.Type()]; ok {
+			break
+		}
+		if field.IsNil() {
+			field.Set(reflect.New(field.Type().Elem()))
+		}
+		field = field.Elem()
+	}
+	return field
+}
+
 // Takes T from *T if *T type passed
 func unreferenceType(t reflect.Type) reflect.Type {
 	for t.Kind() == reflect.Pointer {
@@ -25,10 +61,264 @@ func unreferenceType(t reflect.Type) reflect.Type {
 	return t
 }
 
-// Returns true whenever passed value is a struct and not supported type (like time.Time{})
+// Returns true whenever passed value is a struct and not supported type (like time.Time{},
+// Secret[T] or a custom type)
 func isStructLike(t reflect.Type) bool {
 	t = unreferenceType(t)
-	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{})
+	return t.Kind() == reflect.Struct && t != reflect.TypeOf(time.Time{}) && !isSecret(t) && !isCustomType(t)
+}
+
+// nestedPrefix returns the prefix applied to the fields of the struct-like field sf.
+// FlagsFromStruct and Bind share it, so both passes resolve the same names:
+//
+//	                 | no tag                    | cli:"db"          | cliPrefix:"p-"
+//	anonymous embed  | flattened, inherited only | error             | inherited + "p-"
+//	named field      | flattened, inherited only | inherited + "db-" | inherited + "p-"
+//
+// Using the cli name as prefix lets a generic field (Filter T `cli:"filter"`) be a
+// single flag for scalar type arguments and a group of flags for struct ones. An
+// embed has no name of its own, see checkNested.
+func nestedPrefix(sf reflect.StructField, inherited string) string {
+	if p, ok := sf.Tag.Lookup(tagCLIPrefix); ok {
+		return inherited + p
+	}
+	if name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI)); name != "" {
+		return inherited + name + "-"
+	}
+	return inherited
+}
+
+// checkNested rejects a cli tag on the anonymous struct-like field sf: embeds
+// are flattened into their parent, cliPrefix namespaces them.
+func checkNested(sf reflect.StructField) error {
+	if name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI)); sf.Anonymous && name != "" {
+		return fmt.Errorf("embedded struct %s has cli tag, but unsupported (use cliPrefix)", sf.Name)
+	}
+	return nil
+}
+
+// humanizeFieldName splits a Go field name into lower-case words, keeping the first
+// word and acronyms as they are ("DBPool" -> "DB pool", "ReadReplica" -> "Read replica").
+func humanizeFieldName(name string) string {
+	rs := []rune(name)
+	var words []string
+	start := 0
+	for i := 1; i <= len(rs); i++ {
+		atBoundary := i == len(rs) ||
+			unicode.IsUpper(rs[i]) && (unicode.IsLower(rs[i-1]) || i+1 < len(rs) && unicode.IsLower(rs[i+1]))
+		if !atBoundary {
+			continue
+		}
+		w := string(rs[start:i])
+		if len(words) > 0 && !isAcronym(w) {
+			w = strings.ToLower(w)
+		}
+		words = append(words, w)
+		start = i
+	}
+	return strings.Join(words, " ")
+}
+
+func isAcronym(w string) bool {
+	return len(w) > 1 && strings.ToUpper(w) == w
+}
+
+// walkLeaves calls fn for every bindable (non-struct) field of the struct value v together
+// with its full flag name, following the naming rules of FlagsFromStruct and Bind.
+// Nil nested struct pointers are not descended into. Walking stops once fn returns false.
+func walkLeaves(v reflect.Value, prefix string, fn func(name string, sf reflect.StructField, fv reflect.Value) bool) bool {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		fv := v.Field(i)
+		if isStructLike(sf.Type) {
+			pfx := nestedPrefix(sf, prefix)
+			for fv.Kind() == reflect.Pointer {
+				if fv.IsNil() {
+					break
+				}
+				fv = fv.Elem()
+			}
+			if fv.Kind() == reflect.Struct && !walkLeaves(fv, pfx, fn) {
+				return false
+			}
+			continue
+		}
+		name, _, _ := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		if !fn(prefix+name, sf, fv) {
+			return false
+		}
+	}
+	return true
+}
+
+// findField returns the field of struct value v bound to the flag named name.
+func findField(v reflect.Value, name string) (reflect.Value, bool) {
+	var found reflect.Value
+	walkLeaves(v, "", func(n string, _ reflect.StructField, fv reflect.Value) bool {
+		if n == name {
+			found = fv
+			return false
+		}
+		return true
+	})
+	return found, found.IsValid()
+}
+
+// checkChoices reports an error if the flag value v (or any element of a slice value)
+// is not one of choices. Empty choices accept anything.
+func checkChoices(v any, choices []string) error {
+	if len(choices) == 0 {
+		return nil
+	}
+	vals, ok := v.([]string)
+	if !ok {
+		vals = []string{fmt.Sprint(v)}
+	}
+	for _, val := range vals {
+		if !slices.Contains(choices, val) {
+			return fmt.Errorf("value %q is not one of %s", val, strings.Join(choices, ", "))
+		}
+	}
+	return nil
+}
+
+// expandUsage substitutes the {default}, {env} and {choices} placeholders of a cliUsage tag.
+func expandUsage(usage, def string, env, choices []string) string {
+	if !strings.Contains(usage, "{") {
+		return usage
+	}
+	return strings.NewReplacer(
+		"{default}", def,
+		"{env}", strings.Join(env, ", "),
+		"{choices}", strings.Join(choices, ", "),
+	).Replace(usage)
+}
+
+// flagRefPattern matches {flag:name} references in cliDefault tags.
+var flagRefPattern = regexp.MustCompile(`\{flag:([^}]+)\}`)
+
+func hasFlagRefs(def string) bool {
+	return strings.Contains(def, "{flag:")
+}
+
+// resolveFlagRefs substitutes every {flag:name} reference in def with the parsed value of that flag.
+func resolveFlagRefs(ctx *cli.Command, def string) string {
+	return flagRefPattern.ReplaceAllStringFunc(def, func(ref string) string {
+		name := strings.TrimSpace(flagRefPattern.FindStringSubmatch(ref)[1])
+		v := ctx.Value(name)
+		if vs, ok := v.([]string); ok {
+			return strings.Join(vs, ",")
+		}
+		if v == nil {
+			return ""
+		}
+		return fmt.Sprint(v)
+	})
+}
+
+// splitMapEntries splits map flag values into key/value pairs at the first '=' or ':'.
+// Slice flags split their values on commas, so an element without separator continues
+// the previous entry's value ("Accept: a,b" arrives as "Accept: a", "b").
+func splitMapEntries(raw []string) ([][2]string, error) {
+	var entries [][2]string
+	for _, s := range raw {
+		i := strings.IndexAny(s, "=:")
+		if i < 0 {
+			if len(entries) == 0 {
+				return nil, fmt.Errorf("map entry %q is not key=value", s)
+			}
+			entries[len(entries)-1][1] += "," + s
+			continue
+		}
+		entries = append(entries, [2]string{strings.TrimSpace(s[:i]), strings.TrimSpace(s[i+1:])})
+	}
+	return entries, nil
+}
+
+// parseBools parses every element of a bool slice flag, reporting the offending index.
+func parseBools(vs []string) ([]bool, error) {
+	out := make([]bool, len(vs))
+	for i, v := range vs {
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return nil, fmt.Errorf("element %d: %q is not a bool", i, v)
+		}
+		out[i] = b
+	}
+	return out, nil
+}
+
+// durationsText re-renders comma-separated durations in canonical form ("90s" -> "1m30s"),
+// so help shows defaults the way bound values print.
+func durationsText(s string, sf reflect.StructField) (string, error) {
+	if _, err := durationUnit(sf); err != nil {
+		return "", err
+	}
+	if s == "" {
+		return "", nil
+	}
+	parts := splitCSV(s)
+	for i, p := range parts {
+		d, err := parseDuration(p, sf)
+		if err != nil {
+			return "", err
+		}
+		parts[i] = d.String()
+	}
+	return strings.Join(parts, ","), nil
+}
+
+var durationUnits = map[string]time.Duration{
+	"ns": time.Nanosecond,
+	"us": time.Microsecond,
+	"µs": time.Microsecond,
+	"ms": time.Millisecond,
+	"s":  time.Second,
+	"m":  time.Minute,
+	"h":  time.Hour,
+}
+
+// durationUnit returns the unit of bare integers given to the duration field
+// sf, 0 if it has no cliUnit tag.
+func durationUnit(sf reflect.StructField) (time.Duration, error) {
+	unit := sf.Tag.Get(tagCLIUnit)
+	if unit == "" {
+		return 0, nil
+	}
+	d, ok := durationUnits[unit]
+	if !ok {
+		return 0, fmt.Errorf("invalid %s %q, want ns, us, ms, s, m or h", tagCLIUnit, unit)
+	}
+	return d, nil
+}
+
+// parseDuration parses s with time.ParseDuration, or as a bare integer count of
+// the cliUnit of sf ("30" is 30s with cliUnit:"s").
+func parseDuration(s string, sf reflect.StructField) (time.Duration, error) {
+	unit, err := durationUnit(sf)
+	if err != nil {
+		return 0, err
+	}
+	if unit != 0 {
+		if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+			if n > math.MaxInt64/int64(unit) || n < math.MinInt64/int64(unit) {
+				return 0, fmt.Errorf("parse duration: %s%s overflows", s, sf.Tag.Get(tagCLIUnit))
+			}
+			return time.Duration(n) * unit, nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0, fmt.Errorf("parse duration: %w", err)
+	}
+	return d, nil
 }
 
 func splitCSV(s string) []string {
@@ -42,6 +332,52 @@ func splitCSV(s string) []string {
 	return parts
 }
 
+// intBase returns the integer base configured with cliBase, or fallback when the
+// tag is absent. As with strconv.ParseInt, 0 detects the base from the prefix.
+func intBase(sf reflect.StructField, fallback int) (int, error) {
+	tag, ok := sf.Tag.Lookup(tagCLIBase)
+	if !ok {
+		return fallback, nil
+	}
+	base, err := strconv.Atoi(tag)
+	if err != nil || base == 1 || base < 0 || base > 36 {
+		return 0, fmt.Errorf("invalid %s %q: want 0 or 2..36", tagCLIBase, tag)
+	}
+	return base, nil
+}
+
+// checkFinite rejects NaN and infinities unless the field opts in with
+// cliAllowSpecialFloats; strconv.ParseFloat accepts them silently.
+func checkFinite(f float64, sf reflect.StructField) error {
+	if allow, _ := strconv.ParseBool(sf.Tag.Get(tagCLIFloats)); allow {
+		return nil
+	}
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%v is not a finite number (set %s to allow it)", f, tagCLIFloats)
+	}
+	return nil
+}
+
+// roundFloat rounds f to the cliPrecision decimal places of sf, if any. Rounding
+// goes through the shortest decimal text, so 2.675 rounds to 2.68 as written
+// rather than to 2.67 as stored.
+func roundFloat(f float64, sf reflect.StructField) (float64, error) {
+	p := sf.Tag.Get(tagCLIPrecision)
+	if p == "" || math.IsNaN(f) || math.IsInf(f, 0) {
+		return f, nil
+	}
+	places, err := strconv.Atoi(p)
+	if err != nil || places < 0 {
+		return 0, fmt.Errorf("invalid %s %q, want a number of decimal places", tagCLIPrecision, p)
+	}
+	d, ok := new(big.Rat).SetString(strconv.FormatFloat(f, 'g', -1, 64))
+	if !ok {
+		return f, nil
+	}
+	rounded, _ := strconv.ParseFloat(d.FloatString(places), 64)
+	return rounded, nil
+}
+
 func isAnyInt(k reflect.Kind) bool {
 	switch k {
 	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
@@ -87,6 +423,12 @@ func castAndSetUint(field reflect.Value, v uint64) {
 	}
 }
 
+// isEnvOnly reports whether the leaf field sf is tagged cli:"-": it gets no
+// flag, and Bind fills it from its cliEnv variables or its cliDefault.
+func isEnvOnly(sf reflect.StructField) bool {
+	return strings.TrimSpace(sf.Tag.Get(tagCLI)) == "-" && !isStructLike(sf.Type)
+}
+
 // parseNamesWithOptions supports ",omitempty" as an extra token.
 func parseNamesWithOptions(tag string) (name string, aliases []string, omitEmpty bool) {
 	tag = strings.TrimSpace(tag)
@@ -94,7 +436,7 @@ func parseNamesWithOptions(tag string) (name string, aliases []string, omitEmpty
 		return "", nil, false
 	}
 	parts := splitCSV(tag)
-	if len(parts) == 0 || parts[0] == "" {
+	if len(parts) == 0 || parts[0] == "" || parts[0] == "-" { // cli:"-" fields keep the name of the field, see isEnvOnly
 		return "", nil, false
 	}
 	for _, p := range parts[1:] {
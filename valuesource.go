@@ -0,0 +1,130 @@
+package clibind
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// A ValueSource looks flag values up by flag name in a store such as Consul KV,
+// etcd or AWS SSM Parameter Store, so a service can pull its configuration from
+// there while keeping the same tagged struct. See the consul package.
+type ValueSource interface {
+	// Lookup returns the value of the flag named flag (the full, prefixed
+	// name), and false if the store has none. Lookup is called once per unset
+	// flag, so stores that answer better in bulk should fetch once and cache.
+	Lookup(ctx context.Context, flag string) (value string, ok bool, err error)
+	// String describes the source in explanations and errors, e.g.
+	// "consul kv myapp/".
+	String() string
+}
+
+var (
+	valueSourcesMu sync.RWMutex
+	valueSources   []ValueSource
+)
+
+// RegisterValueSource makes every command binding a struct consult src for the
+// flags neither the command line, the environment nor a config file set.
+// Sources are consulted in the order they are registered, after the ones given
+// to the command with WithValueSource. It fails after Freeze.
+func RegisterValueSource(src ValueSource) error {
+	valueSourcesMu.Lock()
+	defer valueSourcesMu.Unlock()
+	if err := checkFrozen(fmt.Sprintf("RegisterValueSource(%s)", src)); err != nil {
+		return err
+	}
+	valueSources = append(valueSources, src)
+	return nil
+}
+
+// WithValueSource makes the command consult src for flags left unset, like
+// RegisterValueSource does for every command. WithBinding looks the flags up
+// after binding, in the order the options are given: the first source with a
+// value wins, after the cliSources of the field and before cliDefault. A source
+// failing is only an error if no later one has a value; DoctorCommand reports
+// it as a warning then. WithSourceTimeout bounds each lookup.
+func WithValueSource(src ValueSource) Option {
+	return func(o *options) {
+		o.valueSources = append(o.valueSources, src)
+	}
+}
+
+// sourcedRequired returns the names of the flags of the struct type t that
+// would be required, but that the value sources of o may fill: flag generation
+// leaves them optional, and resolveSources checks them as urfave cannot.
+func (o *options) sourcedRequired(t reflect.Type) map[string]bool {
+	if len(o.allValueSources()) == 0 {
+		return nil
+	}
+	required := map[string]bool{}
+	o.collectSourcedRequired(t, "", required)
+	return required
+}
+
+// collectSourcedRequired adds the required flags of the struct type t to
+// required, following genFlagsForStruct.
+func (o *options) collectSourcedRequired(t reflect.Type, prefix string, required map[string]bool) {
+	t = unreferenceType(t)
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if sf.PkgPath != "" {
+			continue
+		}
+		if skip, _ := strconv.ParseBool(sf.Tag.Get(tagCLISkipFlag)); skip || isEnvOnly(sf) || factoriesOf(sf.Type) != nil {
+			continue
+		}
+		if isStructLike(sf.Type) {
+			if !isKV(sf) { // the flags of cliKV structs can be given through their compound flag
+				o.collectSourcedRequired(sf.Type, nestedPrefix(sf, prefix), required)
+			}
+			continue
+		}
+		sf, _ = nullableField(sf)
+		name, _, omitEmpty := parseNamesWithOptions(sf.Tag.Get(tagCLI))
+		if name == "" {
+			name = strings.ToLower(sf.Name)
+		}
+		name = prefix + name
+		gate, _ := versionGate(name, sf, o.version)
+		if o.flagRequired(sf, omitEmpty, gate) {
+			required[name] = true
+		}
+	}
+}
+
+// allValueSources returns the value sources of the command followed by the
+// registered ones.
+func (o *options) allValueSources() []ValueSource {
+	valueSourcesMu.RLock()
+	defer valueSourcesMu.RUnlock()
+	if len(valueSources) == 0 {
+		return o.valueSources
+	}
+	return append(o.valueSources[:len(o.valueSources):len(o.valueSources)], valueSources...)
+}
+
+// errNoValue stands for a source without a value for the flag, through callResolver.
+var errNoValue = errors.New("no value")
+
+// lookupValue looks the flag name up in src, within the timeout of its scheme.
+func (o *options) lookupValue(ctx context.Context, src ValueSource, name string) (string, bool, error) {
+	s, err := o.callResolver(ctx, sourceScheme(src), name, func(ctx context.Context, name string) (string, error) {
+		s, ok, err := src.Lookup(ctx, name)
+		if err == nil && !ok {
+			err = errNoValue
+		}
+		return s, err
+	})
+	switch {
+	case errors.Is(err, errNoValue):
+		return "", false, nil
+	case err != nil:
+		return "", false, err
+	}
+	return s, true, nil
+}
@@ -0,0 +1,137 @@
+package clibind_test
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+// mapSource is a ValueSource answering from a map.
+type mapSource map[string]string
+
+func (m mapSource) Lookup(_ context.Context, flag string) (string, bool, error) {
+	v, ok := m[flag]
+	return v, ok, nil
+}
+
+func (m mapSource) String() string { return "map" }
+
+type sourcedRequiredConfig struct {
+	Host string `cli:"host"`
+}
+
+type sourcedDefaultConfig struct {
+	Host string `cli:"host" cliDefault:"localhost"`
+}
+
+func TestSourcedRequiredIsPerCommand(t *testing.T) {
+	noop := func(context.Context, sourcedRequiredConfig) error { return nil }
+	newRoot := func() *cli.Command {
+		return &cli.Command{
+			Name: "app",
+			Commands: []*cli.Command{
+				clibind.CommandWithBinding(nil, "required", noop, clibind.WithValueSource(mapSource{})),
+				clibind.CommandWithBinding(nil, "sourced", noop, clibind.WithValueSource(mapSource{"host": "db.internal"})),
+				clibind.CommandWithBinding(nil, "default", func(context.Context, sourcedDefaultConfig) error { return nil },
+					clibind.WithValueSource(mapSource{})),
+			},
+		}
+	}
+
+	if res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"required"}}); res.Err == nil {
+		t.Error("required: missing --host was accepted")
+	}
+	res := clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"sourced"}})
+	if res.Err != nil {
+		t.Fatalf("sourced: %v", res.Err)
+	}
+	if got := clibindtest.Bound[sourcedRequiredConfig](t, res, "app sourced").Host; got != "db.internal" {
+		t.Errorf("sourced: Host = %q, want db.internal", got)
+	}
+	res = clibindtest.Run(t, newRoot(), clibindtest.Input{Args: []string{"default"}})
+	if res.Err != nil {
+		t.Fatalf("default: %v", res.Err)
+	}
+	if got := clibindtest.Bound[sourcedDefaultConfig](t, res, "app default").Host; got != "localhost" {
+		t.Errorf("default: Host = %q, want localhost", got)
+	}
+}
+
+// slowSource is a WithSecretSource source that never answers in time, unless
+// it is fast.
+type slowSource struct {
+	lookups *atomic.Int32
+	fast    *atomic.Bool
+}
+
+func (s slowSource) Lookup() (string, bool) {
+	s.lookups.Add(1)
+	if !s.fast.Load() {
+		time.Sleep(time.Second)
+	}
+	return "late", true
+}
+
+func (slowSource) Scheme() string   { return "slow" }
+func (slowSource) String() string   { return "slow store" }
+func (slowSource) GoString() string { return "slowSource{}" }
+
+type secretSourceConfig struct {
+	Password clibind.Secret[string] `cli:"password" cliDefault:"fallback"`
+}
+
+func TestSecretSourceTimeout(t *testing.T) {
+	var (
+		lookups atomic.Int32
+		fast    atomic.Bool
+		prov    *clibind.Provenance
+	)
+	root := &cli.Command{
+		Name: "app",
+		Commands: []*cli.Command{
+			clibind.CommandWithBinding(nil, "login", func(ctx context.Context, _ secretSourceConfig) error {
+				prov, _ = clibind.ProvenanceFromContext(ctx)
+				return nil
+			},
+				clibind.WithSecretSource(func(string) cli.ValueSource { return slowSource{&lookups, &fast} }),
+				clibind.WithSourceTimeout(time.Hour),
+				clibind.WithSourceTimeout(10*time.Millisecond, "slow"),
+			),
+		},
+	}
+	start := time.Now()
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"login"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if d := time.Since(start); d > 500*time.Millisecond {
+		t.Errorf("took %s, want the 10ms timeout of the slow scheme", d)
+	}
+	if got := clibindtest.Bound[secretSourceConfig](t, res, "app login").Password.Value(); got != "fallback" {
+		t.Errorf("Password = %q, want the default", got)
+	}
+	if n := lookups.Load(); n != 1 {
+		t.Errorf("looked the slow source up %d times, want 1", n)
+	}
+	if prov == nil || len(prov.Failures) != 1 || prov.Failures[0].Flag != "password" || prov.Failures[0].Source != "slow store" {
+		t.Errorf("provenance failures = %+v, want the slow store timing out for --password", prov)
+	}
+
+	// a later run looks the source up again
+	fast.Store(true)
+	res = clibindtest.Run(t, root, clibindtest.Input{Args: []string{"login"}})
+	if res.Err != nil {
+		t.Fatal(res.Err)
+	}
+	if got := clibindtest.Bound[secretSourceConfig](t, res, "app login").Password.Value(); got != "late" || lookups.Load() != 2 {
+		t.Errorf("rerun: Password = %q after %d lookups, want the source's value", got, lookups.Load())
+	}
+	if len(prov.Failures) != 0 {
+		t.Errorf("rerun: provenance failures = %+v, want none", prov.Failures)
+	}
+}
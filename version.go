@@ -0,0 +1,105 @@
+package clibind
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/urfave/cli/v3"
+)
+
+// WithVersion sets the version of the application, against which the cliSince
+// and cliUntil tags of fields are checked: a flag is active from its cliSince
+// version and up to, but excluding, its cliUntil version. Other flags are hidden
+// from help and fail the command when given, which lets options roll out gradually
+// and retire cleanly. CommandWithBinding defaults it to the Version of its base
+// command; without a version the tags are ignored.
+func WithVersion(version string) Option {
+	return func(o *options) {
+		o.version = version
+	}
+}
+
+// versionGate returns why the flag name of sf is inactive in version, or "".
+func versionGate(name string, sf reflect.StructField, version string) (string, error) {
+	since, until := sf.Tag.Get(tagCLISince), sf.Tag.Get(tagCLIUntil)
+	if version == "" || since == "" && until == "" {
+		return "", nil
+	}
+	for _, v := range []string{since, until, version} {
+		if _, err := parseVersion(v); v != "" && err != nil {
+			return "", err
+		}
+	}
+	switch {
+	case since != "" && compareVersions(version, since) < 0:
+		return fmt.Sprintf("flag --%s is not available before version %s (this is %s)", name, since, version), nil
+	case until != "" && compareVersions(version, until) >= 0:
+		return fmt.Sprintf("flag --%s was removed in version %s", name, until), nil
+	}
+	return "", nil
+}
+
+// gateFlag hides fl and makes setting it fail with msg.
+func gateFlag(fl cli.Flag, msg string) {
+	v := flagStruct(fl)
+	if f := v.FieldByName("Hidden"); f.IsValid() && f.Kind() == reflect.Bool {
+		f.SetBool(true)
+	}
+	if f := v.FieldByName("Action"); f.IsValid() && f.Kind() == reflect.Func {
+		err := reflect.ValueOf(fmt.Errorf("%s", msg))
+		f.Set(reflect.MakeFunc(f.Type(), func([]reflect.Value) []reflect.Value {
+			return []reflect.Value{err}
+		}))
+	}
+}
+
+// parseVersion splits a version like "v1.2.3-rc.1" into its numeric release
+// parts and pre-release suffix.
+func parseVersion(v string) ([]int, error) {
+	s, _, _ := strings.Cut(strings.TrimPrefix(v, "v"), "-")
+	s, _, _ = strings.Cut(s, "+") // build metadata
+	var parts []int
+	for _, p := range strings.Split(s, ".") {
+		n, err := strconv.Atoi(p)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid version %q", v)
+		}
+		parts = append(parts, n)
+	}
+	return parts, nil
+}
+
+// compareVersions orders two valid versions; missing parts count as zero
+// (v2 = v2.0.0) and a pre-release comes before its release.
+func compareVersions(a, b string) int {
+	pa, _ := parseVersion(a)
+	pb, _ := parseVersion(b)
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	_, preA, _ := strings.Cut(a, "-")
+	_, preB, _ := strings.Cut(b, "-")
+	switch {
+	case preA == preB:
+		return 0
+	case preA == "":
+		return 1
+	case preB == "":
+		return -1
+	}
+	return strings.Compare(preA, preB)
+}
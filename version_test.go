@@ -0,0 +1,50 @@
+package clibind_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	clibind "github.com/eosproject/urfave-cli-bind"
+	"github.com/eosproject/urfave-cli-bind/clibindtest"
+	"github.com/urfave/cli/v3"
+)
+
+type gatedConfig struct {
+	Workers int    `cli:"workers" cliDefault:"1"`
+	Legacy  string `cli:"legacy,omitempty" cliUntil:"2.0"`
+	Pool    string `cli:"pool,omitempty" cliSince:"v2.0.0"`
+}
+
+func TestVersionGates(t *testing.T) {
+	for _, c := range []struct {
+		version string
+		args    []string
+		err     string
+	}{
+		{"1.5", []string{"--legacy", "x"}, ""},
+		{"1.5", []string{"--pool", "x"}, "flag --pool is not available before version v2.0.0 (this is 1.5)"},
+		{"2.0.0-rc.1", []string{"--pool", "x"}, "flag --pool is not available before version v2.0.0 (this is 2.0.0-rc.1)"},
+		{"2.0.0-rc.1", []string{"--legacy", "x"}, ""},
+		{"v2", []string{"--pool", "x"}, ""},
+		{"2.1", []string{"--legacy", "x"}, "flag --legacy was removed in version 2.0"},
+		{"", []string{"--legacy", "x", "--pool", "y"}, ""},
+	} {
+		root := clibind.CommandWithBinding(&cli.Command{Version: c.version}, "app",
+			func(context.Context, gatedConfig) error { return nil })
+		res := clibindtest.Run(t, root, clibindtest.Input{Args: c.args})
+		switch {
+		case c.err == "" && res.Err != nil:
+			t.Errorf("%s %q: %v", c.version, c.args, res.Err)
+		case c.err != "" && (res.Err == nil || !strings.Contains(res.Err.Error(), c.err)):
+			t.Errorf("%s %q: err = %v, want %q", c.version, c.args, res.Err, c.err)
+		}
+	}
+
+	root := clibind.CommandWithBinding(nil, "app", func(context.Context, gatedConfig) error { return nil },
+		clibind.WithVersion("2.1"))
+	res := clibindtest.Run(t, root, clibindtest.Input{Args: []string{"--help"}})
+	if !strings.Contains(res.Stdout, "--pool") || strings.Contains(res.Stdout, "--legacy") {
+		t.Errorf("help shows --legacy or not --pool:\n%s", res.Stdout)
+	}
+}